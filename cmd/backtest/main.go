@@ -0,0 +1,117 @@
+// cmd/backtest — offline replay of recorded UP/DOWN prices through the FSM.
+//
+// Usage:
+//
+//	./backtest --data ./history
+//	./backtest --data ./history --arb-threshold 0.96,0.97,0.98 --momentum-trigger 0.85,0.88
+//
+// Comma-separated values run every combination and print one line per run.
+// Order sizing (ARB_ORDER_USDC, MOMENTUM_MAIN_USDC, ...) comes from .env.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gipsh/polymarket-bot-go/internal/backtest"
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+)
+
+func main() {
+	config.Load()
+
+	dataDir := flag.String("data", "", "Directory of per-market CSV price series (required)")
+	arbFlag := flag.String("arb-threshold", ftoa(config.ARBThreshold), "ARB_THRESHOLD value(s), comma-separated")
+	trigFlag := flag.String("momentum-trigger", ftoa(config.MomentumTrigger), "MOMENTUM_TRIGGER value(s), comma-separated")
+	entryFlag := flag.String("momentum-max-entry", ftoa(config.MomentumMaxEntry), "MOMENTUM_MAX_ENTRY value(s), comma-separated")
+	slippage := flag.Float64("slippage", 0, "Absolute price slippage added to every simulated buy")
+	verbose := flag.Bool("v", false, "Show FSM / inventory logs while replaying")
+	flag.Parse()
+
+	if *dataDir == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	arbs, err := parseList(*arbFlag)
+	if err != nil {
+		log.Fatalf("--arb-threshold: %v", err)
+	}
+	trigs, err := parseList(*trigFlag)
+	if err != nil {
+		log.Fatalf("--momentum-trigger: %v", err)
+	}
+	entries, err := parseList(*entryFlag)
+	if err != nil {
+		log.Fatalf("--momentum-max-entry: %v", err)
+	}
+
+	series, err := backtest.LoadDir(*dataDir)
+	if err != nil {
+		log.Fatalf("load: %v", err)
+	}
+	fmt.Printf("Loaded %d markets from %s\n\n", len(series), *dataDir)
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	type run struct {
+		arb, trig, entry float64
+		rep              *backtest.Report
+	}
+	var runs []run
+	for _, a := range arbs {
+		for _, t := range trigs {
+			for _, e := range entries {
+				config.ARBThreshold = a
+				config.MomentumTrigger = t
+				config.MomentumMaxEntry = e
+				eng := backtest.NewEngine(backtest.Options{Slippage: *slippage})
+				runs = append(runs, run{a, t, e, eng.Run(series)})
+			}
+		}
+	}
+
+	if len(runs) == 1 {
+		runs[0].rep.Print(os.Stdout)
+		return
+	}
+
+	sort.Slice(runs, func(i, j int) bool { return runs[i].rep.PnL > runs[j].rep.PnL })
+	fmt.Printf("%-8s %-8s %-8s %9s %9s %8s %6s %5s\n",
+		"ARB", "MOM_TRG", "MOM_MAX", "INVESTED", "PNL", "MAX_DD", "ROI%", "MERGE")
+	for _, r := range runs {
+		fmt.Printf("%-8.3f %-8.3f %-8.3f %9.2f %+9.2f %8.2f %+6.2f %5d\n",
+			r.arb, r.trig, r.entry, r.rep.Invested, r.rep.PnL, r.rep.MaxDrawdown,
+			r.rep.ROI()*100, r.rep.Merges)
+	}
+}
+
+func parseList(s string) ([]float64, error) {
+	var out []float64
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no values")
+	}
+	return out, nil
+}
+
+func ftoa(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -2,43 +2,94 @@
 //
 // Usage:
 //
-//	./bot              # live trading
-//	./bot --dry-run    # simulate, no real orders
+//	./bot                        # live trading
+//	./bot --dry-run              # simulate, no real orders
+//	./bot --replay ./recordings  # replay recorded feeds (implies --dry-run)
+//	./bot --replay ./recordings --replay-speed 0   # as fast as possible
 //
 // Environment: configure via .env file (same as Python version).
+// SIGTERM/SIGINT shut down gracefully (see SHUTDOWN_TIMEOUT_SEC).
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/gipsh/polymarket-bot-go/internal/api"
 	"github.com/gipsh/polymarket-bot-go/internal/clob"
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
 	"github.com/gipsh/polymarket-bot-go/internal/config"
 	"github.com/gipsh/polymarket-bot-go/internal/executor"
 	"github.com/gipsh/polymarket-bot-go/internal/fsm"
 	"github.com/gipsh/polymarket-bot-go/internal/inventory"
 	"github.com/gipsh/polymarket-bot-go/internal/market"
+	"github.com/gipsh/polymarket-bot-go/internal/metrics"
+	"github.com/gipsh/polymarket-bot-go/internal/orders"
+	"github.com/gipsh/polymarket-bot-go/internal/pnl"
 	"github.com/gipsh/polymarket-bot-go/internal/pricer"
+	"github.com/gipsh/polymarket-bot-go/internal/recorder"
+	"github.com/gipsh/polymarket-bot-go/internal/replay"
+	"github.com/gipsh/polymarket-bot-go/internal/risk"
+	"github.com/gipsh/polymarket-bot-go/internal/settle"
+	"github.com/gipsh/polymarket-bot-go/internal/store"
 	"github.com/gipsh/polymarket-bot-go/internal/types"
 	"github.com/gipsh/polymarket-bot-go/internal/ws"
 )
 
 func main() {
 	dryRun := flag.Bool("dry-run", false, "Simulate without placing real orders")
+	replayDir := flag.String("replay", "", "Replay recorded feed files from this directory (implies --dry-run)")
+	replaySpeed := flag.Float64("replay-speed", 1, "Replay speed multiplier (1 = real time, 0 = as fast as possible)")
 	flag.Parse()
 
 	// ── Load config ────────────────────────────────────────────────────
 	config.Load()
-	if *dryRun {
+	if *dryRun || *replayDir != "" {
 		config.DryRun = true
 	}
 
 	setupLogging()
 
+	// ── Shutdown contexts ──────────────────────────────────────────────
+	// ctx ends on SIGTERM/SIGINT: the loop stops taking new actions and
+	// feeds disconnect. In-flight orders and merges run on workCtx, which
+	// outlives the signal by up to SHUTDOWN_TIMEOUT_SEC so they can finish.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	shutdownTimeout := time.Duration(config.ShutdownTimeoutSec) * time.Second
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	defer cancelWork()
+	context.AfterFunc(ctx, func() {
+		log.Printf("[main] shutdown requested — finishing in-flight work (up to %s)", shutdownTimeout)
+		time.AfterFunc(shutdownTimeout, cancelWork)
+	})
+
+	// ── Replay mode: recorded feeds + simulated clock ──────────────────
+	wsPricer := ws.NewWSPricer()
+	var (
+		player *replay.Player
+		simClk *clock.Sim
+	)
+	if *replayDir != "" {
+		p, err := replay.Load(*replayDir, wsPricer)
+		if err != nil {
+			log.Fatalf("replay: %v", err)
+		}
+		player = p
+		simClk = clock.NewSim(p.Start())
+		clock.Set(simClk)
+		log.SetFlags(0)
+		log.SetOutput(&replayLogWriter{clk: simClk})
+		log.Printf("[replay] replaying %s at %gx", *replayDir, *replaySpeed)
+	}
+
 	if config.DryRun {
 		log.Println("============================================================")
 		log.Println("  DRY RUN MODE — No real orders will be placed")
@@ -51,53 +102,162 @@ func main() {
 		log.Fatalf("CLOB client init: %v", err)
 	}
 
-	inv := inventory.New()
+	// SQLite history and inventory (STORAGE_BACKEND=sqlite); nil otherwise
+	var db *store.DB
+	switch config.StorageBackend {
+	case "json": // inventory in INVENTORY_FILE, no history
+	case "sqlite":
+		if player == nil {
+			db, err = store.Open(config.SQLitePath)
+			if err != nil {
+				log.Fatalf("store: %v", err)
+			}
+		}
+	default:
+		log.Fatalf("STORAGE_BACKEND=%q: want json or sqlite", config.StorageBackend)
+	}
+
+	var inv inventory.Inventory
+	switch {
+	case player != nil:
+		inv = inventory.NewMemory() // never touch live inventory state
+	case db != nil:
+		inv = inventory.NewSQLite(db)
+	default:
+		inv, err = inventory.New()
+		if err != nil {
+			log.Fatalf("inventory: %v", err)
+		}
+	}
 
 	exec := executor.New(inv, clobClient, config.DryRun)
-	fsmEngine := fsm.New()
-	marketFinder := market.NewFinder()
-	restPricer := pricer.NewPricer()
-	wsPricer := ws.NewWSPricer()
+	exec.SetStore(db)
+	exec.SetBooks(wsPricer)
+	var fsmEngine *fsm.FSM
+	if player != nil {
+		fsmEngine = fsm.NewWithClock(simClk) // never touch live caps/cooldowns
+	} else {
+		fsmEngine = fsm.New()
+	}
+
+	// Bot-wide limits between FSM actions and the executor
+	var riskMgr *risk.Manager
+	if player != nil {
+		riskMgr = risk.NewMemory(inv, risk.LimitsFromConfig())
+	} else {
+		riskMgr = risk.New(inv, risk.LimitsFromConfig())
+	}
+	if reason := fsmEngine.Unreadable(); reason != "" {
+		riskMgr.Kill(reason) // caps spent before the restart are unknown
+	}
+
+	// Per-market and daily P&L, settled as markets close
+	var ledger *pnl.Ledger
+	if player != nil {
+		ledger = pnl.NewMemoryLedger(inv)
+	} else {
+		ledger = pnl.NewLedger(inv)
+	}
+
+	// Resting maker quotes: fills count against the FSM's ARB cap
+	orderMgr := orders.New(inv, clobClient, config.DryRun)
+	orderMgr.SetStore(db)
+	orderMgr.SetOnFill(func(o *orders.Order, tokens, usdc float64) {
+		if o.OrderSide == types.SideBuy {
+			fsmEngine.RecordArbSpend(o.ConditionID, usdc)
+			riskMgr.RecordSpend(usdc)
+		}
+	})
+
+	var (
+		marketFinder marketSource = market.NewFinder()
+		restPricer   priceSource  = pricer.NewPricer()
+	)
+	if player != nil {
+		marketFinder = player
+		restPricer = player
+	}
+	exec.SetPrices(restPricer) // dry-run redeems without a merger
+
+	// Closed markets are settled once their winner is known, even after
+	// they have left the active list
+	settler := settle.New(inv, restPricer, func(m *types.Market, winner string) {
+		riskMgr.Settle(m.ConditionID, winner)
+		ledger.Close(m, winner)
+	})
+
+	// ── Market-data recorder (optional) ────────────────────────────────
+	var rec *recorder.Recorder
+	if config.RecordDir != "" && player == nil {
+		rec, err = recorder.New(config.RecordDir, config.RecordMaxMB)
+		if err != nil {
+			log.Fatalf("recorder init: %v", err)
+		}
+		wsPricer.SetRecorder(rec)
+		restPricer.(*pricer.Pricer).SetRecorder(rec)
+	}
+
+	// ── Metrics endpoint (optional) ────────────────────────────────────
+	var metricsSrv *http.Server
+	if config.MetricsAddr != "" {
+		metrics.FeedAge(metrics.FeedMarket, wsPricer.LastMessageAge)
+		metricsSrv = metrics.Serve(config.MetricsAddr)
+	}
+
+	// ── Control / status API (optional) ────────────────────────────────
+	control := api.New(fsmEngine, inv, exec, riskMgr, ledger)
+	control.SetToken(config.APIToken)
+	if config.APIAddr != "" {
+		if err := control.Start(workCtx, config.APIAddr); err != nil {
+			log.Fatalf("api: %v", err)
+		}
+	}
 
 	// ── Authenticate ───────────────────────────────────────────────────
-	var wsUser *ws.UserClient
-	if !config.DryRun && config.PrivateKey != "" {
-		creds, err := clobClient.CreateOrDeriveAPICreds()
+	var (
+		wsUser *ws.UserClient
+		authed bool // L2 creds set: live orders can be placed and cancelled
+	)
+	if !config.DryRun && config.PrivateKey != "" && player == nil {
+		creds, err := clobClient.CreateOrDeriveAPICreds(ctx)
 		if err != nil {
 			log.Printf("[main] WARNING: failed to derive API creds: %v", err)
 		} else {
 			log.Printf("[main] API credentials derived ✓")
 			clobClient.SetAPICreds(creds)
+			control.SetCLOB(clobClient)
+			authed = true
+
+			// Nothing the bot posted before a crash or deploy should keep resting
+			cancelAllOrders(ctx, clobClient, "startup")
 
 			// Reconcile inventory from trade history
-			if n, err := inv.ReconcileFromAPI(clobClient, true); err != nil {
+			if n, err := inv.ReconcileFromAPI(ctx, clobClient, true); err != nil {
 				log.Printf("[main] startup reconcile failed: %v", err)
 			} else if n > 0 {
 				log.Printf("[main] startup reconcile: %d markets", n)
 			}
+			if _, err := exec.ReconcileOnChain(ctx); err != nil {
+				log.Printf("[main] startup on-chain reconcile failed: %v", err)
+			}
 
 			// User WebSocket (fill feed)
-			wsUser = ws.NewUserClient(creds, exec.HandleFill)
-			wsUser.Start()
+			wsUser = ws.NewUserClient(creds, func(f types.FillEvent) {
+				db.RecordFill(f)
+				if !orderMgr.HandleFill(f) {
+					exec.HandleFill(f)
+				}
+			})
+			wsUser.SetOnOrder(exec.HandleOrder)
+			wsUser.Start(ctx)
 		}
 	}
 
-	// ── Start WS price feed ────────────────────────────────────────────
-	wsPricer.Start()
-	defer wsPricer.Stop()
-	if wsUser != nil {
-		defer wsUser.Stop()
+	// ── Start WS price feed (replay feeds it from the recording) ───────
+	if player == nil {
+		wsPricer.Start(ctx)
 	}
 
-	// ── Graceful shutdown ──────────────────────────────────────────────
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
-	go func() {
-		s := <-sigCh
-		log.Printf("[main] received signal %s — shutting down", s)
-		os.Exit(0)
-	}()
-
 	// ── Main loop ──────────────────────────────────────────────────────
 	log.Println("🐾 Polymarket Bot (Go) starting up...")
 	log.Printf("[main] Assets: %v | Interval: %.1fs", config.Assets, config.PollIntervalSec)
@@ -105,24 +265,47 @@ func main() {
 	var (
 		markets          []*types.Market
 		lastMarketRefresh time.Time
+		lastRedeemSweep  time.Time
 		lastLogState     string
 		lastLogTS        time.Time
 	)
 
 	pollInterval := time.Duration(config.PollIntervalSec * float64(time.Second))
 
-	for {
+loop:
+	for ctx.Err() == nil {
+		if player != nil {
+			// Checked before advancing: the tick that plays the last records
+			// still runs on them, the next one ends the replay
+			if player.Done() {
+				log.Println("[replay] end of recording")
+				for _, m := range markets {
+					log.Printf("[inventory] %s", inv.Summary(m.ConditionID))
+				}
+				break loop
+			}
+			player.AdvanceTo(clock.Now())
+		}
+
 		// Refresh market list every MarketRefreshMin minutes
-		if time.Since(lastMarketRefresh) >= time.Duration(config.MarketRefreshMin)*time.Minute || markets == nil {
+		if clock.Since(lastMarketRefresh) >= time.Duration(config.MarketRefreshMin)*time.Minute || markets == nil {
 			log.Println("[main] refreshing market list...")
 			newMarkets, err := marketFinder.GetActiveMarkets()
 			if err != nil {
 				log.Printf("[main] market refresh error: %v", err)
 			} else {
+				settler.Refresh(markets, newMarkets)
 				markets = newMarkets
-				lastMarketRefresh = time.Now()
+				lastMarketRefresh = clock.Now()
+				metrics.ResetInventory()
+				control.SetMarkets(markets)
+				riskMgr.Track(markets)
 				for _, m := range markets {
 					log.Printf("[main]  → %s", m)
+					db.RecordMarket(m)
+					if rec != nil {
+						rec.Register(m)
+					}
 					wsPricer.Subscribe([]string{m.UpTokenID, m.DownTokenID})
 					if wsUser != nil {
 						wsUser.Subscribe(m.ConditionID)
@@ -133,6 +316,10 @@ func main() {
 
 		// Process each market
 		for _, m := range markets {
+			if ctx.Err() != nil {
+				break loop
+			}
+
 			// Get prices: prefer fresh WS data, fall back to REST
 			var prices *types.Prices
 			wsFresh := wsPricer.IsFresh(m.UpTokenID, 4*time.Second) &&
@@ -140,28 +327,44 @@ func main() {
 
 			if wsFresh {
 				prices = wsPricer.GetPrices(m.UpTokenID, m.DownTokenID)
+				metrics.PriceFetch(metrics.SourceWS)
 			} else {
-				p, err := restPricer.GetPrices(m.UpTokenID, m.DownTokenID)
+				p, err := restPricer.GetPrices(ctx, m.UpTokenID, m.DownTokenID)
 				if err != nil {
 					log.Printf("[main] REST price error for %s: %v", m.Asset, err)
+					metrics.PriceFetch(metrics.SourceRESTError)
 					continue
 				}
+				metrics.PriceFetch(metrics.SourceREST)
 				prices = p
 				// Seed WS cache with REST data
 				wsPricer.UpdateCache(m.UpTokenID, prices.Up)
 				wsPricer.UpdateCache(m.DownTokenID, prices.Down)
 			}
 
-			// Run FSM
-			state, action := fsmEngine.Step(m.ConditionID, prices, inv, m.MinutesToClose())
+			db.RecordPrices(m.ConditionID, prices)
+
+			// Settle closed markets: risk manager's daily loss, P&L ledger
+			settler.Observe(m, prices)
+
+			// Run FSM (unless paused through the API), then apply risk limits
+			state, action := types.BotIdle, types.WaitAction("paused")
+			if !control.Paused(m.Asset) {
+				state, action = fsmEngine.Step(m.ConditionID, prices, inv, m.MinutesToClose())
+				action = riskMgr.Check(m.ConditionID, action)
+			}
+			metrics.Step(state.String(), string(action.Kind))
+			control.Observe(m.ConditionID, prices, state, action)
+			db.RecordAction(m.ConditionID, state, prices, action)
 
 			// Adaptive poll interval
 			pollInterval = adaptInterval(prices)
 
 			// Logging: state change, trade action, or 30s heartbeat
-			now := time.Now()
+			now := clock.Now()
 			stateKey := state.String()
-			shouldLog := action.Kind != types.ActionWait && action.Kind != types.ActionSkip ||
+			shouldLog := action.Kind != types.ActionWait && action.Kind != types.ActionSkip &&
+				action.Kind != types.ActionQuoteArb ||
 				stateKey != lastLogState ||
 				now.Sub(lastLogTS) >= 30*time.Second
 
@@ -172,39 +375,132 @@ func main() {
 					prices.Up, prices.Down, prices.Spread, m.MinutesToClose(),
 					action.Kind, action.Reason,
 				)
+				if _, ok := inv.Get(m.ConditionID); ok {
+					log.Printf("[pnl] %s %s", m.Asset, ledger.Mark(m.ConditionID, prices))
+				}
 				lastLogState = stateKey
 				lastLogTS = now
 			}
 
 			// Execute action
-			executeAction(m, action, prices, exec)
+			executeAction(workCtx, m, action, prices, exec, orderMgr)
 
 			log.Printf("[inventory] %s", inv.Summary(m.ConditionID))
+			metrics.Inventory(m.Slug,
+				inv.GetBalance(m.ConditionID, "UP"),
+				inv.GetBalance(m.ConditionID, "DOWN"),
+				inv.GetMergeablePairs(m.ConditionID))
 		}
 
 		if len(markets) == 0 {
 			log.Println("[main] no active markets — waiting...")
 		}
 
-		time.Sleep(pollInterval)
+		// Flag market orders that are not confirming on-chain
+		exec.SweepOrders()
+
+		// Settle closed markets that have resolved since
+		settler.Poll(ctx)
+
+		// Redeem resolved conditions left in inventory (in the background:
+		// each redeem waits for its receipt)
+		if player == nil && config.RedeemIntervalMin > 0 &&
+			clock.Since(lastRedeemSweep) >= time.Duration(config.RedeemIntervalMin)*time.Minute {
+			lastRedeemSweep = clock.Now()
+			go exec.RedeemResolved(workCtx)
+		}
+
+		if simClk != nil {
+			simClk.Advance(pollInterval)
+			if *replaySpeed > 0 {
+				time.Sleep(time.Duration(float64(pollInterval) / *replaySpeed))
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(pollInterval):
+		}
+	}
+
+	// ── Graceful shutdown ──────────────────────────────────────────────
+	log.Println("[main] shutting down — no new actions")
+	if err := exec.Shutdown(workCtx); err != nil {
+		log.Printf("[main] %v", err)
+	}
+
+	// Cancels must go out even if the drain deadline has passed
+	cancelCtx, cancelCancels := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelCancels()
+	orderMgr.CancelAll(cancelCtx)
+	if authed {
+		cancelAllOrders(cancelCtx, clobClient, "shutdown")
+	}
+
+	// Stop the feeds before the final flush so no fill lands after it
+	if wsUser != nil {
+		wsUser.Stop()
 	}
+	wsPricer.Stop()
+	if err := inv.Flush(); err != nil {
+		log.Printf("[main] inventory flush failed: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		log.Printf("[main] store close failed: %v", err)
+	}
+	if rec != nil {
+		rec.Close()
+	}
+	if metricsSrv != nil {
+		_ = metricsSrv.Shutdown(cancelCtx)
+	}
+	_ = control.Shutdown(cancelCtx)
+	log.Println("[main] shutdown complete")
+}
+
+// ── Price / market sources (live or replay) ───────────────────────────────
+
+type marketSource interface {
+	GetActiveMarkets() ([]*types.Market, error)
+}
+
+type priceSource interface {
+	GetPrices(ctx context.Context, upTokenID, downTokenID string) (*types.Prices, error)
+}
+
+// replayLogWriter prefixes log lines with the simulated time, so replay logs
+// read like the original bot.log.
+type replayLogWriter struct {
+	clk *clock.Sim
+}
+
+func (w *replayLogWriter) Write(b []byte) (int, error) {
+	fmt.Fprintf(os.Stderr, "%s %s", w.clk.Now().Local().Format("2006/01/02 15:04:05"), b)
+	return len(b), nil
 }
 
 // ── Action execution ──────────────────────────────────────────────────────
 
-func executeAction(m *types.Market, action types.Action, prices *types.Prices, exec *executor.Executor) {
+func executeAction(
+	ctx context.Context,
+	m *types.Market, action types.Action, prices *types.Prices,
+	exec *executor.Executor, orderMgr *orders.Manager,
+) {
+	// Maker quotes only rest while the FSM keeps asking for them
+	orderMgr.SimulateFills(m.ConditionID, prices)
+	if action.Kind != types.ActionQuoteArb {
+		orderMgr.CancelMarket(ctx, m.ConditionID)
+	}
+
 	switch action.Kind {
 	case types.ActionWait, types.ActionSkip:
 		return
 
 	case types.ActionBuyArb:
-		priceHint := prices.Up
-		if action.Side == "DOWN" {
-			priceHint = prices.Down
-		}
+		// Limit at the worst ask the FSM sized the buy against
 		result := exec.BuyMarket(
-			m.ConditionID, m.UpTokenID, m.DownTokenID,
-			action.Side, action.ArbUSDC, priceHint,
+			ctx, m.ConditionID, m.UpTokenID, m.DownTokenID,
+			action.Side, action.ArbUSDC, action.ArbPrice, action.Pairs,
 		)
 		if result.Success {
 			log.Printf("  ✓ ARB BUY %s | $%.2f → %.3f tokens", action.Side, result.USDCSpent, result.TokensReceived)
@@ -212,6 +508,22 @@ func executeAction(m *types.Market, action types.Action, prices *types.Prices, e
 			log.Printf("  ✗ ARB BUY failed: %s", result.Error)
 		}
 
+	case types.ActionBuyArbPair:
+		result := exec.BuyArbPair(
+			ctx, m.ConditionID, m.UpTokenID, m.DownTokenID,
+			action.Pairs, action.UpUSDC, action.DownUSDC, action.UpPrice, action.DownPrice,
+		)
+		if result.Up.Success && result.Down.Success {
+			log.Printf("  ✓ ARB PAIR | UP $%.2f → %.3f + DOWN $%.2f → %.3f | %.3f pairs",
+				result.Up.USDCSpent, result.Up.TokensReceived,
+				result.Down.USDCSpent, result.Down.TokensReceived, result.Pairs)
+		} else {
+			log.Printf("  ✗ ARB PAIR incomplete: UP=%q DOWN=%q", result.Up.Error, result.Down.Error)
+		}
+
+	case types.ActionQuoteArb:
+		orderMgr.QuoteArb(ctx, m, action)
+
 	case types.ActionBuyMomentum:
 		mainPrice := prices.Up
 		if action.MainSide == "DOWN" {
@@ -223,8 +535,8 @@ func executeAction(m *types.Market, action types.Action, prices *types.Prices, e
 		}
 
 		mainResult := exec.BuyMarket(
-			m.ConditionID, m.UpTokenID, m.DownTokenID,
-			action.MainSide, action.MainUSDC, mainPrice,
+			ctx, m.ConditionID, m.UpTokenID, m.DownTokenID,
+			action.MainSide, action.MainUSDC, mainPrice, 0,
 		)
 		if mainResult.Success {
 			log.Printf("  ✓ MOMENTUM %s (main) | $%.2f → %.3f tokens",
@@ -232,16 +544,30 @@ func executeAction(m *types.Market, action types.Action, prices *types.Prices, e
 		}
 
 		hedgeResult := exec.BuyMarket(
-			m.ConditionID, m.UpTokenID, m.DownTokenID,
-			action.HedgeSide, action.HedgeUSDC, hedgePrice,
+			ctx, m.ConditionID, m.UpTokenID, m.DownTokenID,
+			action.HedgeSide, action.HedgeUSDC, hedgePrice, 0,
 		)
 		if hedgeResult.Success {
 			log.Printf("  ✓ MOMENTUM %s (hedge) | $%.2f → %.3f tokens",
 				action.HedgeSide, hedgeResult.USDCSpent, hedgeResult.TokensReceived)
 		}
 
+	case types.ActionSplitSell:
+		result := exec.SplitAndSell(
+			ctx, m.ConditionID, m.UpTokenID, m.DownTokenID,
+			action.Pairs, action.UpPrice, action.DownPrice,
+		)
+		if result.Up.Success && result.Down.Success {
+			log.Printf("  ✓ SPLIT %.3f pairs | UP → $%.2f + DOWN → $%.2f",
+				result.Pairs, result.Up.USDCReceived, result.Down.USDCReceived)
+		} else if result.Pairs > 0 {
+			log.Printf("  ✗ SPLIT sells incomplete: UP=%q DOWN=%q", result.Up.Error, result.Down.Error)
+		} else {
+			log.Printf("  ✗ SPLIT failed")
+		}
+
 	case types.ActionMerge:
-		pairs := exec.MergePairs(m.ConditionID)
+		pairs := exec.MergePairs(ctx, m.ConditionID)
 		if pairs > 0 {
 			log.Printf("  ✓ MERGE %.2f pairs → +$%.2f USDC", pairs, pairs)
 		}
@@ -250,11 +576,23 @@ func executeAction(m *types.Market, action types.Action, prices *types.Prices, e
 
 // ── Helpers ───────────────────────────────────────────────────────────────
 
+// cancelAllOrders cancels every resting order of the account.
+func cancelAllOrders(ctx context.Context, client *clob.Client, when string) {
+	resp, err := client.CancelAll(ctx)
+	if err != nil {
+		log.Printf("[main] %s cancel-all failed: %v", when, err)
+		return
+	}
+	if len(resp.Canceled) > 0 {
+		log.Printf("[main] %s cancel-all: %d orders cancelled", when, len(resp.Canceled))
+	}
+}
+
 func adaptInterval(prices *types.Prices) time.Duration {
 	switch prices.State {
 	case types.StateMomentumUp, types.StateMomentumDown:
 		return 300 * time.Millisecond
-	case types.StateARB:
+	case types.StateARB, types.StateSplit:
 		return 500 * time.Millisecond
 	case types.StateResolved:
 		return 5 * time.Second
@@ -0,0 +1,68 @@
+// cmd/fakeclob — local fake Polymarket CLOB for paper trading.
+//
+// Usage:
+//
+//	./fakeclob --addr 127.0.0.1:8089 --books books.json
+//	CLOB_HOST=http://127.0.0.1:8089 ./polymarket-bot
+//
+// books.json is a list of POST /fake/book payloads:
+//
+//	[{"token_id":"123…","market":"0xabc…","outcome":"Up",
+//	  "bids":[{"price":"0.48","size":"200"}],"asks":[{"price":"0.50","size":"200"}]}]
+//
+// Books can also be reseeded at runtime with POST /fake/book.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gipsh/polymarket-bot-go/internal/fakeclob"
+)
+
+type bookSeed struct {
+	TokenID string           `json:"token_id"`
+	Market  string           `json:"market"`
+	Outcome string           `json:"outcome"`
+	Bids    []fakeclob.Level `json:"bids"`
+	Asks    []fakeclob.Level `json:"asks"`
+}
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8089", "Listen address")
+	booksFile := flag.String("books", "", "Optional JSON file of initial books")
+	flag.Parse()
+
+	srv := fakeclob.New()
+
+	if *booksFile != "" {
+		data, err := os.ReadFile(*booksFile)
+		if err != nil {
+			log.Fatalf("read %s: %v", *booksFile, err)
+		}
+		var seeds []bookSeed
+		if err := json.Unmarshal(data, &seeds); err != nil {
+			log.Fatalf("parse %s: %v", *booksFile, err)
+		}
+		for _, b := range seeds {
+			srv.SetBook(b.TokenID, b.Bids, b.Asks)
+			if b.Market != "" {
+				srv.SetTokenInfo(b.TokenID, b.Market, b.Outcome)
+			}
+		}
+		log.Printf("[fakeclob] seeded %d books from %s", len(seeds), *booksFile)
+	}
+
+	if _, err := srv.Start(*addr); err != nil {
+		log.Fatalf("listen: %v", err)
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+	<-sigCh
+	_ = srv.Close()
+}
@@ -0,0 +1,57 @@
+// cmd/setup-allowances — one-time approvals for a new Gnosis Safe wallet.
+//
+// Usage:
+//
+//	./setup-allowances --check   # print USDC allowances and CTF approvals
+//	./setup-allowances           # approve whatever is missing via the Safe
+//
+// Sets USDC.approve(MAX) and ConditionalTokens.setApprovalForAll(true) for
+// the CTF Exchange, Neg Risk CTF Exchange and Neg Risk Adapter. Reads
+// POLYGON_RPC, FUNDER_ADDRESS and MERGE_PRIVATE_KEY (or PRIVATE_KEY) from
+// the environment / .env. Python reference: setup_safe_allowances.py
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/onchain"
+)
+
+func main() {
+	check := flag.Bool("check", false, "Only print the current approvals, send nothing")
+	timeout := flag.Duration("timeout", 5*time.Minute, "Overall timeout")
+	flag.Parse()
+
+	config.Load()
+	if config.FunderAddress == "" || config.MergePrivateKey == "" {
+		log.Fatal("FUNDER_ADDRESS and MERGE_PRIVATE_KEY (or PRIVATE_KEY) must be set")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	safe, err := onchain.NewSafe(config.PolygonRPC, config.FunderAddress, config.MergePrivateKey)
+	if err != nil {
+		log.Fatalf("safe: %v", err)
+	}
+	missing, err := onchain.SetupAllowances(ctx, safe, *check)
+	if err != nil {
+		log.Fatalf("setup allowances: %v", err)
+	}
+	switch {
+	case missing == 0:
+		log.Println("[setup] all approvals in place")
+	case *check:
+		log.Printf("[setup] %d approvals missing — run without --check to set them", missing)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,414 @@
+// Package api serves a small HTTP control and status API for the running
+// bot. It is disabled unless API_ADDR is set, and a bare port binds to
+// localhost only.
+//
+// Endpoints:
+//
+//	GET  /status                 tracked markets, prices, FSM state, caps, cooldowns
+//	GET  /inventory              every inventory entry
+//	POST /pause[?asset=bitcoin]  stop taking new actions (all markets or one asset)
+//	POST /resume[?asset=bitcoin] undo /pause (no asset = resume everything)
+//	POST /merge/{conditionID}    merge that market's pairs now
+//	POST /reconcile              force an inventory reconcile from trade history
+//	GET  /risk                   risk limits, usage and kill switch
+//	POST /risk/kill[?reason=…]   latch the kill switch by hand
+//	POST /risk/reset             clear the kill switch
+//	GET  /pnl                    marked P&L of tracked markets, today and past days
+//
+// The POST routes change what the bot does, so they need an
+// "Authorization: Bearer <API_TOKEN>" header and are refused when no token
+// is set. The header also keeps a browser page from driving them: it cannot
+// be sent cross-origin without a CORS preflight, which the API never grants.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clob"
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
+	"github.com/gipsh/polymarket-bot-go/internal/executor"
+	"github.com/gipsh/polymarket-bot-go/internal/fsm"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/pnl"
+	"github.com/gipsh/polymarket-bot-go/internal/risk"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+// Server holds the bot components the API reads and drives, plus the
+// pause switches the main loop consults every tick.
+type Server struct {
+	fsm  *fsm.FSM
+	inv  inventory.Inventory
+	exec *executor.Executor
+	risk *risk.Manager
+	pnl  *pnl.Ledger
+
+	mu        sync.Mutex
+	token     string       // bearer token the POST routes require (empty = refused)
+	client    *clob.Client // set once L2 creds exist (needed by /reconcile)
+	markets   []*types.Market
+	ticks     map[string]tick // conditionID → last loop tick
+	pausedAll bool
+	paused    map[string]bool // asset → paused
+	ctx       context.Context // parent of merges and reconciles
+	httpSrv   *http.Server
+}
+
+// tick is what the main loop last saw and decided for a market.
+type tick struct {
+	at     time.Time
+	prices *types.Prices
+	state  types.BotState
+	action types.Action
+}
+
+// New creates an API server. Call Start to serve it.
+func New(f *fsm.FSM, inv inventory.Inventory, exec *executor.Executor, rm *risk.Manager, ledger *pnl.Ledger) *Server {
+	return &Server{
+		fsm:    f,
+		inv:    inv,
+		exec:   exec,
+		risk:   rm,
+		pnl:    ledger,
+		ticks:  make(map[string]tick),
+		paused: make(map[string]bool),
+		ctx:    context.Background(),
+	}
+}
+
+// SetCLOB enables /reconcile with an authenticated client.
+func (s *Server) SetCLOB(client *clob.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = client
+}
+
+// SetToken sets the bearer token the POST routes require. Without one they
+// are refused.
+func (s *Server) SetToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// ── Main loop hooks ───────────────────────────────────────────────────────
+
+// SetMarkets replaces the tracked market list.
+func (s *Server) SetMarkets(markets []*types.Market) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.markets = markets
+	live := make(map[string]bool, len(markets))
+	for _, m := range markets {
+		live[m.ConditionID] = true
+	}
+	for id := range s.ticks {
+		if !live[id] {
+			delete(s.ticks, id)
+		}
+	}
+}
+
+// Observe records the prices, state and action of one loop tick.
+func (s *Server) Observe(conditionID string, prices *types.Prices, state types.BotState, action types.Action) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ticks[conditionID] = tick{at: clock.Now(), prices: prices, state: state, action: action}
+}
+
+// Paused reports whether new actions are paused for asset.
+func (s *Server) Paused(asset string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pausedAll || s.paused[strings.ToLower(asset)]
+}
+
+// ── Server lifecycle ──────────────────────────────────────────────────────
+
+// Start serves the API on addr in the background. A bare ":port" binds to
+// 127.0.0.1. Merges and reconciles started through the API run on ctx, so
+// they are not cut off when the HTTP client goes away.
+func (s *Server) Start(ctx context.Context, addr string) error {
+	if strings.HasPrefix(addr, ":") {
+		addr = "127.0.0.1" + addr
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.ctx = ctx
+	s.httpSrv = &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := s.httpSrv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[api] serve: %v", err)
+		}
+	}()
+	log.Printf("[api] listening on http://%s", ln.Addr())
+	s.mu.Lock()
+	noToken := s.token == ""
+	s.mu.Unlock()
+	if noToken {
+		log.Printf("[api] ⚠ API_TOKEN not set — POST routes (pause, merge, reconcile, risk…) are refused")
+	}
+	return nil
+}
+
+// Shutdown stops a server started with Start.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.httpSrv == nil {
+		return nil
+	}
+	return s.httpSrv.Shutdown(ctx)
+}
+
+// Handler returns the API routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("GET /inventory", s.handleInventory)
+	mux.HandleFunc("POST /pause", s.authorized(s.handlePause))
+	mux.HandleFunc("POST /resume", s.authorized(s.handleResume))
+	mux.HandleFunc("POST /merge/{conditionID}", s.authorized(s.handleMerge))
+	mux.HandleFunc("POST /reconcile", s.authorized(s.handleReconcile))
+	mux.HandleFunc("GET /risk", s.handleRisk)
+	mux.HandleFunc("POST /risk/kill", s.authorized(s.handleRiskKill))
+	mux.HandleFunc("POST /risk/reset", s.authorized(s.handleRiskReset))
+	mux.HandleFunc("GET /pnl", s.handlePnL)
+	return mux
+}
+
+// authorized wraps a state-changing handler: the request must carry the
+// configured bearer token.
+func (s *Server) authorized(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		token := s.token
+		s.mu.Unlock()
+		if token == "" {
+			writeErr(w, http.StatusForbidden, "API_TOKEN not set — control routes disabled")
+			return
+		}
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			log.Printf("[api] ⚠ %s %s from %s refused: bad or missing token", r.Method, r.URL.Path, r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeErr(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// ── Handlers ──────────────────────────────────────────────────────────────
+
+type pricesJSON struct {
+	Up     float64           `json:"up"`
+	Down   float64           `json:"down"`
+	Spread float64           `json:"spread"`
+	State  types.MarketState `json:"state"`
+}
+
+type marketJSON struct {
+	Asset          string      `json:"asset"`
+	Slug           string      `json:"slug"`
+	ConditionID    string      `json:"condition_id"`
+	EndDate        time.Time   `json:"end_date"`
+	MinutesToClose float64     `json:"minutes_to_close"`
+	Paused         bool        `json:"paused"`
+	Prices         *pricesJSON `json:"prices,omitempty"`
+	BotState       string      `json:"bot_state,omitempty"`
+	LastAction     string      `json:"last_action,omitempty"`
+	LastReason     string      `json:"last_reason,omitempty"`
+	UpdatedAt      *time.Time  `json:"updated_at,omitempty"`
+	fsm.Status
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	out := struct {
+		Time         time.Time    `json:"time"`
+		PausedAll    bool         `json:"paused_all"`
+		PausedAssets []string     `json:"paused_assets"`
+		Risk         risk.Status  `json:"risk"`
+		Markets      []marketJSON `json:"markets"`
+	}{
+		Time:         clock.Now(),
+		PausedAll:    s.pausedAll,
+		PausedAssets: []string{},
+		Markets:      []marketJSON{},
+	}
+	for a := range s.paused {
+		out.PausedAssets = append(out.PausedAssets, a)
+	}
+	sort.Strings(out.PausedAssets)
+	for _, m := range s.markets {
+		mj := marketJSON{
+			Asset:          m.Asset,
+			Slug:           m.Slug,
+			ConditionID:    m.ConditionID,
+			EndDate:        m.EndDate,
+			MinutesToClose: m.MinutesToClose(),
+			Paused:         s.pausedAll || s.paused[strings.ToLower(m.Asset)],
+		}
+		if t, ok := s.ticks[m.ConditionID]; ok {
+			mj.Prices = &pricesJSON{Up: t.prices.Up, Down: t.prices.Down, Spread: t.prices.Spread, State: t.prices.State}
+			mj.BotState = t.state.String()
+			mj.LastAction = string(t.action.Kind)
+			mj.LastReason = t.action.Reason
+			at := t.at
+			mj.UpdatedAt = &at
+		}
+		out.Markets = append(out.Markets, mj)
+	}
+	s.mu.Unlock()
+
+	// FSM and risk have their own locks; read them outside ours
+	out.Risk = s.risk.Status()
+	for i := range out.Markets {
+		out.Markets[i].Status = s.fsm.Status(out.Markets[i].ConditionID)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleInventory(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.inv.Snapshot())
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	asset := strings.ToLower(r.URL.Query().Get("asset"))
+	s.mu.Lock()
+	if asset == "" {
+		s.pausedAll = true
+	} else {
+		s.paused[asset] = true
+	}
+	s.mu.Unlock()
+	log.Printf("[api] paused %s", scope(asset))
+	writeJSON(w, http.StatusOK, map[string]string{"paused": scope(asset)})
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	asset := strings.ToLower(r.URL.Query().Get("asset"))
+	s.mu.Lock()
+	if asset == "" {
+		s.pausedAll = false
+		s.paused = make(map[string]bool)
+	} else {
+		delete(s.paused, asset)
+	}
+	s.mu.Unlock()
+	log.Printf("[api] resumed %s", scope(asset))
+	writeJSON(w, http.StatusOK, map[string]string{"resumed": scope(asset)})
+}
+
+func (s *Server) handleMerge(w http.ResponseWriter, r *http.Request) {
+	conditionID := r.PathValue("conditionID")
+	if _, ok := s.inv.Get(conditionID); !ok {
+		writeErr(w, http.StatusNotFound, "no inventory for "+conditionID)
+		return
+	}
+	log.Printf("[api] merge requested for %s...", conditionID[:min(8, len(conditionID))])
+	merged := s.exec.MergePairs(s.ctx, conditionID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"condition_id": conditionID,
+		"merged_pairs": merged,
+	})
+}
+
+func (s *Server) handleReconcile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	client := s.client
+	s.mu.Unlock()
+	if client == nil {
+		writeErr(w, http.StatusServiceUnavailable, "no authenticated CLOB client (dry run or missing creds)")
+		return
+	}
+	n, err := s.inv.ReconcileFromAPI(s.ctx, client, true)
+	if err != nil {
+		writeErr(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	fixed, err := s.exec.ReconcileOnChain(s.ctx)
+	if err != nil {
+		writeErr(w, http.StatusBadGateway, "on-chain reconcile: "+err.Error())
+		return
+	}
+	log.Printf("[api] reconcile: %d markets, %d on-chain corrections", n, fixed)
+	writeJSON(w, http.StatusOK, map[string]int{"markets": n, "onchain_corrections": fixed})
+}
+
+func (s *Server) handleRisk(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.risk.Status())
+}
+
+func (s *Server) handleRiskKill(w http.ResponseWriter, r *http.Request) {
+	reason := r.URL.Query().Get("reason")
+	if reason == "" {
+		reason = "manual kill via API"
+	}
+	s.risk.Kill(reason)
+	writeJSON(w, http.StatusOK, s.risk.Status())
+}
+
+func (s *Server) handleRiskReset(w http.ResponseWriter, r *http.Request) {
+	s.risk.Reset()
+	writeJSON(w, http.StatusOK, s.risk.Status())
+}
+
+func (s *Server) handlePnL(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	markets := s.markets
+	prices := make(map[string]*types.Prices, len(s.ticks))
+	for cid, t := range s.ticks {
+		prices[cid] = t.prices
+	}
+	s.mu.Unlock()
+
+	out := struct {
+		Markets []pnl.Market `json:"markets"`
+		Today   pnl.Day      `json:"today"`
+		History []pnl.Day    `json:"history"`
+	}{
+		Markets: []pnl.Market{},
+		Today:   s.pnl.Today(),
+		History: s.pnl.History(),
+	}
+	for _, m := range markets {
+		if _, ok := s.inv.Get(m.ConditionID); !ok {
+			continue
+		}
+		mark := s.pnl.Mark(m.ConditionID, prices[m.ConditionID])
+		mark.Slug = m.Slug
+		out.Markets = append(out.Markets, mark)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// ── Helpers ───────────────────────────────────────────────────────────────
+
+func scope(asset string) string {
+	if asset == "" {
+		return "all"
+	}
+	return asset
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeErr(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+)
+
+func TestPostRoutesNeedToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string // configured API_TOKEN
+		header     string // Authorization header sent
+		wantStatus int
+		wantPaused bool
+	}{
+		{name: "no token configured", header: "Bearer secret", wantStatus: http.StatusForbidden},
+		{name: "missing header", token: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", header: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "not a bearer token", token: "secret", header: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "valid token", token: "secret", header: "Bearer secret", wantStatus: http.StatusOK, wantPaused: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(nil, nil, nil, nil, nil)
+			s.SetToken(tt.token)
+			req := httptest.NewRequest(http.MethodPost, "/pause", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := s.Paused("bitcoin"); got != tt.wantPaused {
+				t.Errorf("paused = %v, want %v", got, tt.wantPaused)
+			}
+		})
+	}
+}
+
+func TestGetRoutesStayOpen(t *testing.T) {
+	s := New(nil, inventory.NewMemory(), nil, nil, nil)
+	s.SetToken("secret")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/inventory", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /inventory status = %d, want %d without a token", rec.Code, http.StatusOK)
+	}
+}
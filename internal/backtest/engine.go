@@ -0,0 +1,165 @@
+package backtest
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/fsm"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+// Options tunes the fill simulation.
+type Options struct {
+	// Slippage is added to the quoted price of every simulated buy
+	// (absolute, e.g. 0.005 = half a cent per token).
+	Slippage float64
+}
+
+// Engine drives recorded prices through fsm.FSM.Step with a simulated clock
+// and a memory-only inventory. Thresholds and sizing are read from config,
+// exactly as in live trading.
+type Engine struct {
+	opts  Options
+	clock *clock.Sim
+	fsm   *fsm.FSM
+	inv   inventory.Inventory
+}
+
+// NewEngine creates a fresh engine (new FSM, empty inventory).
+func NewEngine(opts Options) *Engine {
+	clk := clock.NewSim(time.Time{})
+	return &Engine{
+		opts:  opts,
+		clock: clk,
+		fsm:   fsm.NewWithClock(clk),
+		inv:   inventory.NewMemory(),
+	}
+}
+
+// event is one tick of one market in the merged timeline.
+type event struct {
+	idx  int // index into series
+	tick Tick
+	last bool // final tick of this market → resolve afterwards
+}
+
+// Run replays all series in global timestamp order and returns the report.
+func (e *Engine) Run(series []*Series) *Report {
+	results := make([]*MarketResult, len(series))
+	var events []event
+	for i, s := range series {
+		results[i] = &MarketResult{Market: s.Market}
+		for j, t := range s.Ticks {
+			events = append(events, event{idx: i, tick: t, last: j == len(s.Ticks)-1})
+		}
+	}
+	sort.SliceStable(events, func(a, b int) bool { return events[a].tick.TS.Before(events[b].tick.TS) })
+
+	rep := &Report{Markets: results}
+	equity := make([]float64, len(series)) // latest mark-to-market equity per market
+	var peak float64
+
+	for _, ev := range events {
+		s := series[ev.idx]
+		r := results[ev.idx]
+		m := s.Market
+		e.clock.Set(ev.tick.TS)
+
+		prices := &types.Prices{
+			Up:     ev.tick.Up,
+			Down:   ev.tick.Down,
+			Spread: ev.tick.Up + ev.tick.Down,
+			State:  types.ClassifyPrices(ev.tick.Up, ev.tick.Down, 0, 0, config.ARBThreshold, config.SplitThreshold, config.MomentumTrigger),
+		}
+		minutesToClose := m.EndDate.Sub(e.clock.Now()).Minutes()
+
+		_, action := e.fsm.Step(m.ConditionID, prices, e.inv, minutesToClose)
+		r.Ticks++
+		e.execute(m, action, prices, r)
+
+		if ev.last {
+			e.resolve(m, prices, r)
+		}
+
+		equity[ev.idx] = r.mark(e.inv, prices)
+		r.trackDrawdown(equity[ev.idx])
+
+		total := 0.0
+		for _, v := range equity {
+			total += v
+		}
+		if total > peak {
+			peak = total
+		}
+		if dd := peak - total; dd > rep.MaxDrawdown {
+			rep.MaxDrawdown = dd
+		}
+	}
+
+	rep.aggregate()
+	return rep
+}
+
+// execute simulates an FSM action the same way executor.Executor does in
+// dry-run mode: fill at the quoted price (+slippage) and record in inventory.
+func (e *Engine) execute(m *types.Market, action types.Action, prices *types.Prices, r *MarketResult) {
+	switch action.Kind {
+	case types.ActionBuyArb:
+		e.buy(m, action.Side, action.ArbUSDC, prices, r)
+		r.ArbBuys++
+
+	case types.ActionBuyArbPair:
+		e.buy(m, "UP", action.UpUSDC, prices, r)
+		e.buy(m, "DOWN", action.DownUSDC, prices, r)
+		r.ArbBuys++
+
+	case types.ActionQuoteArb:
+		// resting maker quotes are not simulated
+
+	case types.ActionBuyMomentum:
+		e.buy(m, action.MainSide, action.MainUSDC, prices, r)
+		e.buy(m, action.HedgeSide, action.HedgeUSDC, prices, r)
+		r.MomentumBuys++
+
+	case types.ActionMerge:
+		pairs := e.inv.GetMergeablePairs(m.ConditionID)
+		if pairs < 0.01 {
+			return
+		}
+		e.inv.RecordMerge(m.ConditionID, pairs)
+		r.Merged += pairs
+		r.Merges++
+	}
+}
+
+func (e *Engine) buy(m *types.Market, side string, usdc float64, prices *types.Prices, r *MarketResult) {
+	if usdc <= 0 {
+		return
+	}
+	price := prices.Up
+	if side == "DOWN" {
+		price = prices.Down
+	}
+	price = math.Min(price+e.opts.Slippage, 0.999)
+	tokens := usdc / math.Max(price, 0.01)
+	e.inv.RecordBuy(m.ConditionID, m.UpTokenID, m.DownTokenID, side, tokens, usdc)
+	r.Invested += usdc
+}
+
+// resolve pays out the winning side at $1/token after the market's last tick.
+// The winner is the side priced higher on the final tick.
+func (e *Engine) resolve(m *types.Market, prices *types.Prices, r *MarketResult) {
+	r.Winner = prices.Winner()
+	r.UpLeft = e.inv.GetBalance(m.ConditionID, "UP")
+	r.DownLeft = e.inv.GetBalance(m.ConditionID, "DOWN")
+	if r.Winner == "UP" {
+		r.Payout = r.UpLeft
+	} else {
+		r.Payout = r.DownLeft
+	}
+	r.resolved = true
+}
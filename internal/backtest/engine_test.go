@@ -0,0 +1,80 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+)
+
+// arbConfig sets the thresholds and ARB sizing the fixture is written for.
+func arbConfig(t *testing.T) {
+	t.Helper()
+	threshold, split, trigger := config.ARBThreshold, config.SplitThreshold, config.MomentumTrigger
+	max, order, maker := config.ARBMaxUSDC, config.ARBOrderUSDC, config.ARBMakerEnabled
+	t.Cleanup(func() {
+		config.ARBThreshold, config.SplitThreshold, config.MomentumTrigger = threshold, split, trigger
+		config.ARBMaxUSDC, config.ARBOrderUSDC, config.ARBMakerEnabled = max, order, maker
+	})
+	config.ARBThreshold, config.SplitThreshold, config.MomentumTrigger = 0.98, 0, 0.85
+	config.ARBMaxUSDC, config.ARBOrderUSDC, config.ARBMakerEnabled = 18, 9, false
+}
+
+func TestLoadFile(t *testing.T) {
+	s, err := LoadFile("testdata/btc-updown-test.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := s.Market
+	if m.Slug != "btc-updown-test" || m.Asset != "BTC" || m.ConditionID[:12] != "0xbacktest00" {
+		t.Errorf("market = %s / %s / %s", m.Slug, m.Asset, m.ConditionID)
+	}
+	if want := time.Date(2026, 2, 22, 22, 0, 0, 0, time.UTC); !m.EndDate.Equal(want) {
+		t.Errorf("end = %s, want %s", m.EndDate, want)
+	}
+	if len(s.Ticks) != 5 {
+		t.Fatalf("%d ticks, want 5 (column header skipped)", len(s.Ticks))
+	}
+	// RFC3339, unix seconds and unix milliseconds
+	for i, want := range []string{"21:50:00", "21:59:30", "21:59:50"} {
+		if got := s.Ticks[[]int{0, 3, 4}[i]].TS.Format("15:04:05"); got != want {
+			t.Errorf("tick %d at %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	arbConfig(t)
+	tests := []struct {
+		name     string
+		slippage float64
+		wantPnL  float64
+	}{
+		// Two $9 pairs at 0.90 (the third hits ARB_MAX_USDC): 20 pairs merged for $20
+		{name: "quoted prices", wantPnL: 20 - 18},
+		// Filled at 0.50 a side: 18 pairs merged for $18
+		{name: "slippage eats the edge", slippage: 0.05, wantPnL: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := LoadDir("testdata")
+			if err != nil {
+				t.Fatal(err)
+			}
+			rep := NewEngine(Options{Slippage: tt.slippage}).Run(series)
+
+			r := rep.Markets[0]
+			if r.Ticks != 5 || r.ArbBuys != 2 || r.Merges != 1 || r.Invested != 18 {
+				t.Errorf("%d ticks, %d ARB buys, %d merges, $%.2f invested; want 5, 2, 1, $18",
+					r.Ticks, r.ArbBuys, r.Merges, r.Invested)
+			}
+			if r.Winner != "UP" || r.UpLeft > 1e-9 || r.DownLeft > 1e-9 {
+				t.Errorf("resolved %s with UP %.4f / DOWN %.4f left, want UP and nothing left", r.Winner, r.UpLeft, r.DownLeft)
+			}
+			if math.Abs(rep.PnL-tt.wantPnL) > 1e-9 || math.Abs(rep.ROI()-tt.wantPnL/18) > 1e-9 {
+				t.Errorf("P&L $%.4f (ROI %.4f), want $%.4f", rep.PnL, rep.ROI(), tt.wantPnL)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+// MarketResult holds the simulated outcome for one market.
+type MarketResult struct {
+	Market       *types.Market
+	Ticks        int
+	ArbBuys      int
+	MomentumBuys int
+	Merges       int
+	Invested     float64 // USDC spent on buys
+	Merged       float64 // USDC returned by merges
+	Payout       float64 // USDC paid to the winning side at resolution
+	Winner       string  // "UP" / "DOWN" (set at resolution)
+	UpLeft       float64 // unmerged UP tokens at resolution
+	DownLeft     float64 // unmerged DOWN tokens at resolution
+	PnL          float64
+	MaxDrawdown  float64
+
+	resolved bool
+	peak     float64
+}
+
+// mark returns the market's equity: cash flows plus open tokens marked to
+// the current prices (or the resolution payout once resolved).
+func (r *MarketResult) mark(inv inventory.Inventory, prices *types.Prices) float64 {
+	cash := r.Merged - r.Invested
+	if r.resolved {
+		r.PnL = cash + r.Payout
+		return r.PnL
+	}
+	cid := r.Market.ConditionID
+	r.PnL = cash + inv.GetBalance(cid, "UP")*prices.Up + inv.GetBalance(cid, "DOWN")*prices.Down
+	return r.PnL
+}
+
+func (r *MarketResult) trackDrawdown(equity float64) {
+	if equity > r.peak {
+		r.peak = equity
+	}
+	if dd := r.peak - equity; dd > r.MaxDrawdown {
+		r.MaxDrawdown = dd
+	}
+}
+
+// Report aggregates results across all markets.
+type Report struct {
+	Markets      []*MarketResult
+	Invested     float64
+	Merged       float64
+	Payout       float64
+	PnL          float64
+	Merges       int
+	ArbBuys      int
+	MomentumBuys int
+	MaxDrawdown  float64 // on the combined equity curve
+}
+
+func (rep *Report) aggregate() {
+	for _, r := range rep.Markets {
+		rep.Invested += r.Invested
+		rep.Merged += r.Merged
+		rep.Payout += r.Payout
+		rep.PnL += r.PnL
+		rep.Merges += r.Merges
+		rep.ArbBuys += r.ArbBuys
+		rep.MomentumBuys += r.MomentumBuys
+	}
+}
+
+// ROI returns PnL / Invested (0 if nothing was invested).
+func (rep *Report) ROI() float64 {
+	if rep.Invested == 0 {
+		return 0
+	}
+	return rep.PnL / rep.Invested
+}
+
+// Print writes a per-market table and the aggregate totals.
+func (rep *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "%-44s %6s %4s %4s %5s %9s %9s %9s %9s %8s %4s\n",
+		"MARKET", "TICKS", "ARB", "MOM", "MERGE", "INVESTED", "MERGED", "PAYOUT", "PNL", "MAX_DD", "WIN")
+	fmt.Fprintln(w, strings.Repeat("─", 120))
+	for _, r := range rep.Markets {
+		fmt.Fprintf(w, "%-44s %6d %4d %4d %5d %9.2f %9.2f %9.2f %+9.2f %8.2f %4s\n",
+			truncate(r.Market.Slug, 44), r.Ticks, r.ArbBuys, r.MomentumBuys, r.Merges,
+			r.Invested, r.Merged, r.Payout, r.PnL, r.MaxDrawdown, r.Winner)
+	}
+	fmt.Fprintln(w, strings.Repeat("─", 120))
+	fmt.Fprintf(w, "%-44s %6s %4d %4d %5d %9.2f %9.2f %9.2f %+9.2f %8.2f\n",
+		fmt.Sprintf("TOTAL (%d markets, ROI %+.2f%%)", len(rep.Markets), rep.ROI()*100), "",
+		rep.ArbBuys, rep.MomentumBuys, rep.Merges,
+		rep.Invested, rep.Merged, rep.Payout, rep.PnL, rep.MaxDrawdown)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-1] + "…"
+}
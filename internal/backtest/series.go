@@ -0,0 +1,161 @@
+// Package backtest replays historical UP/DOWN price series through the real
+// FSM and inventory code paths against a simulated clock, and reports P&L.
+package backtest
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+// Tick is a single UP/DOWN price observation.
+type Tick struct {
+	TS   time.Time
+	Up   float64
+	Down float64
+}
+
+// Series is the recorded price history of one market.
+type Series struct {
+	Market *types.Market
+	Ticks  []Tick // sorted by TS
+}
+
+// LoadDir loads every *.csv file in dir as one market series.
+//
+// File format (one file per market, file name = market slug):
+//
+//	# asset=BTC
+//	# condition_id=0xabc...
+//	# end=2026-02-22T22:00:00Z
+//	timestamp,up,down
+//	2026-02-22T21:00:00Z,0.51,0.50
+//
+// Header comments are optional: asset defaults to the slug prefix, the
+// condition ID is derived from the slug, and end defaults to the last tick.
+// Timestamps may be RFC3339 or unix seconds / milliseconds.
+func LoadDir(dir string) ([]*Series, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.csv"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no *.csv files in %s", dir)
+	}
+	sort.Strings(paths)
+
+	out := make([]*Series, 0, len(paths))
+	for _, p := range paths {
+		s, err := LoadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(p), err)
+		}
+		if len(s.Ticks) == 0 {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// LoadFile loads a single market series from a CSV file (see LoadDir).
+func LoadFile(path string) (*Series, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	slug := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	meta := map[string]string{}
+	var ticks []Tick
+
+	sc := bufio.NewScanner(f)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if k, v, ok := strings.Cut(strings.TrimSpace(line[1:]), "="); ok {
+				meta[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+			continue
+		}
+		cols := strings.Split(line, ",")
+		if len(cols) < 3 {
+			return nil, fmt.Errorf("line %d: expected timestamp,up,down", lineNo)
+		}
+		ts, err := parseTimestamp(strings.TrimSpace(cols[0]))
+		if err != nil {
+			if len(ticks) == 0 {
+				continue // column header
+			}
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		up, err1 := strconv.ParseFloat(strings.TrimSpace(cols[1]), 64)
+		down, err2 := strconv.ParseFloat(strings.TrimSpace(cols[2]), 64)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("line %d: bad price", lineNo)
+		}
+		ticks = append(ticks, Tick{TS: ts, Up: up, Down: down})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(ticks, func(i, j int) bool { return ticks[i].TS.Before(ticks[j].TS) })
+
+	m := &types.Market{
+		Asset:       meta["asset"],
+		Slug:        slug,
+		ConditionID: meta["condition_id"],
+		UpTokenID:   meta["up_token_id"],
+		DownTokenID: meta["down_token_id"],
+		Title:       meta["title"],
+	}
+	if m.Asset == "" {
+		m.Asset = strings.ToUpper(strings.SplitN(slug, "-", 2)[0])
+	}
+	if m.ConditionID == "" {
+		sum := sha256.Sum256([]byte(slug))
+		m.ConditionID = "0x" + hex.EncodeToString(sum[:])
+	}
+	if m.UpTokenID == "" {
+		m.UpTokenID = m.ConditionID + "-up"
+	}
+	if m.DownTokenID == "" {
+		m.DownTokenID = m.ConditionID + "-down"
+	}
+	if end, ok := meta["end"]; ok {
+		t, err := parseTimestamp(end)
+		if err != nil {
+			return nil, fmt.Errorf("end: %w", err)
+		}
+		m.EndDate = t
+	} else if len(ticks) > 0 {
+		m.EndDate = ticks[len(ticks)-1].TS
+	}
+
+	return &Series{Market: m, Ticks: ticks}, nil
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(n).UTC(), nil
+		}
+		return time.Unix(n, 0).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,265 @@
+// Package book models a per-token L2 order book (bid and ask levels with
+// sizes), built from full snapshots and incremental level updates.
+//
+// Prices are per-token in USDC (0..1); sizes are in tokens.
+package book
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Level is one price level.
+type Level struct {
+	Price float64
+	Size  float64
+}
+
+// Side of the book a level update applies to.
+type Side int
+
+const (
+	Bid Side = iota // resting buy orders
+	Ask             // resting sell orders
+)
+
+// Book is a concurrency-safe L2 order book for one token.
+type Book struct {
+	mu      sync.RWMutex
+	bids    map[int64]float64 // price (micro-units) → size
+	asks    map[int64]float64
+	updated time.Time
+}
+
+// New creates an empty book.
+func New() *Book {
+	return &Book{
+		bids: make(map[int64]float64),
+		asks: make(map[int64]float64),
+	}
+}
+
+// ── Writes ────────────────────────────────────────────────────────────────
+
+// Snapshot replaces the whole book.
+func (b *Book) Snapshot(bids, asks []Level, ts time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bids = make(map[int64]float64, len(bids))
+	b.asks = make(map[int64]float64, len(asks))
+	for _, l := range bids {
+		setLevel(b.bids, l.Price, l.Size)
+	}
+	for _, l := range asks {
+		setLevel(b.asks, l.Price, l.Size)
+	}
+	b.updated = ts
+}
+
+// Set sets the size resting at price on one side. Size 0 removes the level.
+func (b *Book) Set(side Side, price, size float64, ts time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if side == Bid {
+		setLevel(b.bids, price, size)
+	} else {
+		setLevel(b.asks, price, size)
+	}
+	b.updated = ts
+}
+
+func setLevel(m map[int64]float64, price, size float64) {
+	if price <= 0 {
+		return
+	}
+	k := priceKey(price)
+	if size <= 0 {
+		delete(m, k)
+		return
+	}
+	m[k] = size
+}
+
+// ── Reads ─────────────────────────────────────────────────────────────────
+
+// Updated returns when the book last changed.
+func (b *Book) Updated() time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.updated
+}
+
+// BestBid returns the highest bid.
+func (b *Book) BestBid() (float64, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	best, ok := int64(0), false
+	for k := range b.bids {
+		if !ok || k > best {
+			best, ok = k, true
+		}
+	}
+	return keyPrice(best), ok
+}
+
+// BestAsk returns the lowest ask.
+func (b *Book) BestAsk() (float64, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	best, ok := int64(0), false
+	for k := range b.asks {
+		if !ok || k < best {
+			best, ok = k, true
+		}
+	}
+	return keyPrice(best), ok
+}
+
+// Bids returns bid levels, best (highest) first.
+func (b *Book) Bids() []Level {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return sortedLevels(b.bids, true)
+}
+
+// Asks returns ask levels, best (lowest) first.
+func (b *Book) Asks() []Level {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return sortedLevels(b.asks, false)
+}
+
+// AskDepth returns the tokens offered at or below maxPrice and their cost.
+func (b *Book) AskDepth(maxPrice float64) (tokens, usdc float64) {
+	limit := priceKey(maxPrice)
+	for _, l := range b.Asks() {
+		if priceKey(l.Price) > limit {
+			break
+		}
+		tokens += l.Size
+		usdc += l.Size * l.Price
+	}
+	return tokens, usdc
+}
+
+// BidDepth returns the tokens bid at or above minPrice and their proceeds.
+func (b *Book) BidDepth(minPrice float64) (tokens, usdc float64) {
+	limit := priceKey(minPrice)
+	for _, l := range b.Bids() {
+		if priceKey(l.Price) < limit {
+			break
+		}
+		tokens += l.Size
+		usdc += l.Size * l.Price
+	}
+	return tokens, usdc
+}
+
+// BuyVWAP walks the asks to spend usdc and returns the average fill price
+// and tokens received. full=false if the book can't absorb the whole amount
+// (vwap/tokens then describe the partial fill).
+func (b *Book) BuyVWAP(usdc float64) (vwap, tokens float64, full bool) {
+	remaining := usdc
+	spent := 0.0
+	for _, l := range b.Asks() {
+		if remaining <= 0 {
+			break
+		}
+		take := l.Size
+		if cost := take * l.Price; cost > remaining {
+			take = remaining / l.Price
+		}
+		tokens += take
+		spent += take * l.Price
+		remaining -= take * l.Price
+	}
+	if tokens == 0 {
+		return 0, 0, false
+	}
+	return spent / tokens, tokens, remaining <= 1e-9
+}
+
+// CostToBuy walks the asks for exactly `tokens` and returns the USDC cost.
+// full=false if the book has fewer tokens on offer.
+func (b *Book) CostToBuy(tokens float64) (usdc float64, full bool) {
+	remaining := tokens
+	for _, l := range b.Asks() {
+		if remaining <= 0 {
+			break
+		}
+		take := math.Min(l.Size, remaining)
+		usdc += take * l.Price
+		remaining -= take
+	}
+	return usdc, remaining <= 1e-9
+}
+
+// SellVWAP walks the bids to sell `tokens` and returns the average price
+// and USDC received.
+func (b *Book) SellVWAP(tokens float64) (vwap, usdc float64, full bool) {
+	remaining := tokens
+	sold := 0.0
+	for _, l := range b.Bids() {
+		if remaining <= 0 {
+			break
+		}
+		take := math.Min(l.Size, remaining)
+		sold += take
+		usdc += take * l.Price
+		remaining -= take
+	}
+	if sold == 0 {
+		return 0, 0, false
+	}
+	return usdc / sold, usdc, remaining <= 1e-9
+}
+
+// Clone returns an independent copy of the book.
+func (b *Book) Clone() *Book {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	c := &Book{
+		bids:    make(map[int64]float64, len(b.bids)),
+		asks:    make(map[int64]float64, len(b.asks)),
+		updated: b.updated,
+	}
+	for k, v := range b.bids {
+		c.bids[k] = v
+	}
+	for k, v := range b.asks {
+		c.asks[k] = v
+	}
+	return c
+}
+
+// ── Helpers ───────────────────────────────────────────────────────────────
+
+// priceKey maps a price to integer micro-units so "0.52" parsed twice lands
+// on the same level.
+func priceKey(p float64) int64 {
+	return int64(math.Round(p * 1e6))
+}
+
+func keyPrice(k int64) float64 {
+	return float64(k) / 1e6
+}
+
+func sortedLevels(m map[int64]float64, desc bool) []Level {
+	keys := make([]int64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if desc {
+			return keys[i] > keys[j]
+		}
+		return keys[i] < keys[j]
+	})
+	out := make([]Level, len(keys))
+	for i, k := range keys {
+		out[i] = Level{Price: keyPrice(k), Size: m[k]}
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package book
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func testBook() *Book {
+	b := New()
+	b.Snapshot(
+		[]Level{{0.48, 100}, {0.47, 50}, {0.45, 200}},
+		[]Level{{0.50, 10}, {0.52, 100}, {0.55, 40}},
+		time.Unix(0, 0),
+	)
+	return b
+}
+
+func TestBuyVWAP(t *testing.T) {
+	tests := []struct {
+		name       string
+		usdc       float64
+		wantVWAP   float64
+		wantTokens float64
+		wantFull   bool
+	}{
+		{name: "inside the best level", usdc: 2.5, wantVWAP: 0.50, wantTokens: 5, wantFull: true},
+		{name: "exactly the best level", usdc: 5, wantVWAP: 0.50, wantTokens: 10, wantFull: true},
+		// 10 @ 0.50 + 10 @ 0.52 = 20 tokens for $10.20
+		{name: "across two levels", usdc: 10.2, wantVWAP: 0.51, wantTokens: 20, wantFull: true},
+		// 10 @ 0.50 + 100 @ 0.52 + 40 @ 0.55 = 150 tokens for $79
+		{name: "more than the book", usdc: 100, wantVWAP: 79.0 / 150, wantTokens: 150, wantFull: false},
+		{name: "nothing to spend", usdc: 0, wantFull: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vwap, tokens, full := testBook().BuyVWAP(tt.usdc)
+			if !near(vwap, tt.wantVWAP) || !near(tokens, tt.wantTokens) || full != tt.wantFull {
+				t.Errorf("BuyVWAP(%v) = %.6f, %.6f, %v; want %.6f, %.6f, %v",
+					tt.usdc, vwap, tokens, full, tt.wantVWAP, tt.wantTokens, tt.wantFull)
+			}
+		})
+	}
+}
+
+func TestSellVWAP(t *testing.T) {
+	tests := []struct {
+		name     string
+		tokens   float64
+		wantVWAP float64
+		wantUSDC float64
+		wantFull bool
+	}{
+		{name: "inside the best level", tokens: 40, wantVWAP: 0.48, wantUSDC: 19.2, wantFull: true},
+		// 100 @ 0.48 + 50 @ 0.47 = $71.50
+		{name: "across two levels", tokens: 150, wantVWAP: 71.5 / 150, wantUSDC: 71.5, wantFull: true},
+		// every bid: $71.50 + 200 @ 0.45 = $161.50 for 350 tokens
+		{name: "more than the book", tokens: 400, wantVWAP: 161.5 / 350, wantUSDC: 161.5, wantFull: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vwap, usdc, full := testBook().SellVWAP(tt.tokens)
+			if !near(vwap, tt.wantVWAP) || !near(usdc, tt.wantUSDC) || full != tt.wantFull {
+				t.Errorf("SellVWAP(%v) = %.6f, %.6f, %v; want %.6f, %.6f, %v",
+					tt.tokens, vwap, usdc, full, tt.wantVWAP, tt.wantUSDC, tt.wantFull)
+			}
+		})
+	}
+}
+
+func TestCostToBuy(t *testing.T) {
+	tests := []struct {
+		tokens   float64
+		wantUSDC float64
+		wantFull bool
+	}{
+		{tokens: 5, wantUSDC: 2.5, wantFull: true},
+		{tokens: 20, wantUSDC: 10.2, wantFull: true},
+		{tokens: 150, wantUSDC: 79, wantFull: true},
+		{tokens: 200, wantUSDC: 79, wantFull: false},
+	}
+	for _, tt := range tests {
+		usdc, full := testBook().CostToBuy(tt.tokens)
+		if !near(usdc, tt.wantUSDC) || full != tt.wantFull {
+			t.Errorf("CostToBuy(%v) = %.6f, %v; want %.6f, %v", tt.tokens, usdc, full, tt.wantUSDC, tt.wantFull)
+		}
+	}
+}
+
+func TestDepth(t *testing.T) {
+	b := testBook()
+	if tokens, usdc := b.AskDepth(0.52); !near(tokens, 110) || !near(usdc, 57) {
+		t.Errorf("AskDepth(0.52) = %v, %v; want 110, 57", tokens, usdc)
+	}
+	if tokens, usdc := b.BidDepth(0.47); !near(tokens, 150) || !near(usdc, 71.5) {
+		t.Errorf("BidDepth(0.47) = %v, %v; want 150, 71.5", tokens, usdc)
+	}
+
+	// A level update replaces the size; size 0 removes the level
+	b.Set(Ask, 0.50, 0, time.Unix(1, 0))
+	b.Set(Bid, 0.49, 5, time.Unix(1, 0))
+	if ask, _ := b.BestAsk(); ask != 0.52 {
+		t.Errorf("best ask after removing 0.50 = %v, want 0.52", ask)
+	}
+	if bid, _ := b.BestBid(); bid != 0.49 {
+		t.Errorf("best bid after adding 0.49 = %v, want 0.49", bid)
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,42 @@
+package book
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// wireLevel is a level as returned by the CLOB REST API (decimal strings).
+type wireLevel struct {
+	Price string `json:"price"`
+	Size  string `json:"size"`
+}
+
+// ParseJSON builds a book from a CLOB GET /book response:
+//
+//	{"asset_id":"…","bids":[{"price":"0.48","size":"100"}],"asks":[…]}
+func ParseJSON(data []byte, ts time.Time) (*Book, error) {
+	var resp struct {
+		Bids []wireLevel `json:"bids"`
+		Asks []wireLevel `json:"asks"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("parse book: %w", err)
+	}
+	b := New()
+	b.Snapshot(parseWire(resp.Bids), parseWire(resp.Asks), ts)
+	return b, nil
+}
+
+func parseWire(in []wireLevel) []Level {
+	out := make([]Level, 0, len(in))
+	for _, l := range in {
+		p, err1 := strconv.ParseFloat(l.Price, 64)
+		sz, err2 := strconv.ParseFloat(l.Size, 64)
+		if err1 == nil && err2 == nil {
+			out = append(out, Level{Price: p, Size: sz})
+		}
+	}
+	return out
+}
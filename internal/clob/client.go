@@ -9,6 +9,7 @@ package clob
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"crypto/hmac"
 	"crypto/sha256"
@@ -26,7 +27,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/gipsh/polymarket-bot-go/internal/book"
 	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/metrics"
 	"github.com/gipsh/polymarket-bot-go/internal/types"
 )
 
@@ -43,6 +46,12 @@ type Client struct {
 
 // NewClient creates a new CLOB client from the global config.
 func NewClient() (*Client, error) {
+	return NewClientWithHost(config.CLOBHost)
+}
+
+// NewClientWithHost creates a CLOB client targeting host (e.g. a local
+// fakeclob server) instead of config.CLOBHost.
+func NewClientWithHost(host string) (*Client, error) {
 	var key *ecdsa.PrivateKey
 	var addr common.Address
 
@@ -58,7 +67,7 @@ func NewClient() (*Client, error) {
 	funder := common.HexToAddress(config.FunderAddress)
 
 	return &Client{
-		host:    config.CLOBHost,
+		host:    strings.TrimRight(host, "/"),
 		key:     key,
 		address: addr,
 		funder:  funder,
@@ -71,7 +80,7 @@ func NewClient() (*Client, error) {
 
 // CreateOrDeriveAPICreds derives L2 API credentials by signing with the private key.
 // This calls POST /auth/api-key with L1 auth headers.
-func (c *Client) CreateOrDeriveAPICreds() (*types.APICreds, error) {
+func (c *Client) CreateOrDeriveAPICreds(ctx context.Context) (*types.APICreds, error) {
 	if c.key == nil {
 		return nil, fmt.Errorf("no private key configured")
 	}
@@ -82,7 +91,7 @@ func (c *Client) CreateOrDeriveAPICreds() (*types.APICreds, error) {
 		return nil, fmt.Errorf("L1 sign: %w", err)
 	}
 
-	req, err := http.NewRequest("GET", c.host+"/auth/api-key", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.host+"/auth/api-key", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -122,6 +131,23 @@ func (c *Client) SetAPICreds(creds *types.APICreds) {
 	c.creds = creds
 }
 
+// Identity returns the API key and the maker address our orders carry:
+// what tells our legs of a trade apart (Trade.Legs).
+func (c *Client) Identity() (apiKey, maker string) {
+	if c.creds != nil {
+		apiKey = c.creds.APIKey
+	}
+	return apiKey, c.makerAddress().Hex()
+}
+
+// makerAddress is the address orders are placed for.
+func (c *Client) makerAddress() common.Address {
+	if c.sigType == types.SigGnosisSafe {
+		return c.funder // Safe is the maker; EOA is the signer
+	}
+	return c.address
+}
+
 // ── Price fetching ────────────────────────────────────────────────────────
 
 // PriceResponse from GET /price.
@@ -130,12 +156,12 @@ type PriceResponse struct {
 }
 
 // GetPrice fetches the best ask price for a single token (BUY side).
-func (c *Client) GetPrice(tokenID string) (float64, error) {
+func (c *Client) GetPrice(ctx context.Context, tokenID string) (float64, error) {
 	params := url.Values{}
 	params.Set("token_id", tokenID)
 	params.Set("side", "BUY")
 
-	resp, err := c.httpCli.Get(c.host + "/price?" + params.Encode())
+	resp, err := c.get(ctx, c.host+"/price?"+params.Encode())
 	if err != nil {
 		return 0, err
 	}
@@ -154,8 +180,8 @@ func (c *Client) GetPrice(tokenID string) (float64, error) {
 }
 
 // GetMidpoint fetches the midpoint price for a single token.
-func (c *Client) GetMidpoint(tokenID string) (float64, error) {
-	resp, err := c.httpCli.Get(c.host + "/midpoint?token_id=" + tokenID)
+func (c *Client) GetMidpoint(ctx context.Context, tokenID string) (float64, error) {
+	resp, err := c.get(ctx, c.host+"/midpoint?token_id="+tokenID)
 	if err != nil {
 		return 0, err
 	}
@@ -171,6 +197,21 @@ func (c *Client) GetMidpoint(tokenID string) (float64, error) {
 	return strconv.ParseFloat(result.Mid, 64)
 }
 
+// GetBook fetches the full order book for a single token.
+func (c *Client) GetBook(ctx context.Context, tokenID string) (*book.Book, error) {
+	resp, err := c.get(ctx, c.host+"/book?token_id="+tokenID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GET /book: HTTP %d: %s", resp.StatusCode, body)
+	}
+	return book.ParseJSON(body, time.Now())
+}
+
 // ── Order placement ───────────────────────────────────────────────────────
 
 // MarketOrderRequest defines the parameters for a market (FOK) order.
@@ -178,38 +219,105 @@ type MarketOrderRequest struct {
 	ConditionID string
 	UpTokenID   string
 	DownTokenID string
-	Side        string  // "UP" or "DOWN"
-	USDCAmount  float64
-	PriceHint   float64 // best known price for token estimation
+	Side        string          // "UP" or "DOWN"
+	OrderSide   types.OrderSide // SideBuy (default) or SideSell
+	USDCAmount  float64         // BUY: USDC to spend
+	Tokens      float64         // SELL: tokens to sell
+	PriceHint   float64         // limit: BUY the highest ask to reach, SELL the lowest bid
 }
 
-// PlaceMarketOrder builds, signs, and submits a market (FOK) BUY order.
-// Returns the full response from the CLOB or an error.
-func (c *Client) PlaceMarketOrder(req MarketOrderRequest) (map[string]interface{}, error) {
-	if c.key == nil {
-		return nil, fmt.Errorf("no private key — cannot place orders")
-	}
-	if c.creds == nil {
-		return nil, fmt.Errorf("API creds not set — call CreateOrDeriveAPICreds first")
-	}
-
+// PlaceMarketOrder builds, signs, and submits a market (FOK) order.
+// BUY spends USDCAmount on asks up to PriceHint; SELL sells Tokens for at
+// least Tokens*PriceHint USDC. Returns the full response from the CLOB or
+// an error.
+func (c *Client) PlaceMarketOrder(ctx context.Context, req MarketOrderRequest) (map[string]interface{}, error) {
 	tokenID := req.UpTokenID
 	if req.Side == "DOWN" {
 		tokenID = req.DownTokenID
 	}
 
-	// Build order params
-	salt := big.NewInt(rand.Int63())
-	makerAmt := USDCToUnits(req.USDCAmount)
-
-	// Estimate taker amount from price hint (tokens = USDC / price)
-	var takerAmt *big.Int
-	if req.PriceHint > 0 {
-		estimated := req.USDCAmount / req.PriceHint
-		takerAmt = USDCToUnits(estimated)
+	// BUY: maker gives USDC, takes tokens; SELL: maker gives tokens, takes USDC.
+	var makerAmt, takerAmt *big.Int
+	if req.OrderSide == types.SideSell {
+		if req.Tokens <= 0 {
+			return nil, fmt.Errorf("SELL needs a positive token amount")
+		}
+		makerAmt = USDCToUnits(req.Tokens)
+		takerAmt = USDCToUnits(req.Tokens * req.PriceHint) // 0 = any price
 	} else {
-		// 0.5 default
-		takerAmt = USDCToUnits(req.USDCAmount * 2)
+		makerAmt = USDCToUnits(req.USDCAmount)
+		// Taker amount from the limit (tokens = USDC / price): a buy walking
+		// several levels must be priced at the worst one it reaches
+		if req.PriceHint > 0 {
+			takerAmt = USDCToUnits(req.USDCAmount / req.PriceHint)
+		} else {
+			// 0.5 default
+			takerAmt = USDCToUnits(req.USDCAmount * 2)
+		}
+	}
+
+	order, err := c.signOrder(tokenID, req.OrderSide, makerAmt, takerAmt, 0)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := c.postL2(ctx, "/order", map[string]interface{}{
+		"order":     order,
+		"orderType": "FOK",
+	})
+	metrics.Order(req.OrderSide.String(), time.Since(start), err)
+	return resp, err
+}
+
+// LimitOrderRequest defines a limit order at a fixed price.
+type LimitOrderRequest struct {
+	TokenID    string
+	OrderSide  types.OrderSide
+	Price      float64 // USDC per token (0..1)
+	Size       float64 // tokens
+	OrderType  string  // "GTC" (default), "GTD" or "FOK"
+	Expiration int64   // unix seconds, GTD only (0 = none)
+}
+
+// PlaceLimitOrder builds, signs, and submits a limit order for Size tokens
+// at Price. Returns the full response from the CLOB or an error.
+func (c *Client) PlaceLimitOrder(ctx context.Context, req LimitOrderRequest) (map[string]interface{}, error) {
+	if req.Price <= 0 || req.Price >= 1 || req.Size <= 0 {
+		return nil, fmt.Errorf("invalid limit order: %.4f @ %.4f", req.Size, req.Price)
+	}
+	orderType := req.OrderType
+	if orderType == "" {
+		orderType = "GTC"
+	}
+
+	tokens := USDCToUnits(req.Size)
+	usdc := USDCToUnits(req.Size * req.Price)
+	makerAmt, takerAmt := usdc, tokens
+	if req.OrderSide == types.SideSell {
+		makerAmt, takerAmt = tokens, usdc
+	}
+
+	order, err := c.signOrder(req.TokenID, req.OrderSide, makerAmt, takerAmt, req.Expiration)
+	if err != nil {
+		return nil, err
+	}
+	return c.postL2(ctx, "/order", map[string]interface{}{
+		"order":     order,
+		"orderType": orderType,
+	})
+}
+
+// signOrder builds and EIP-712 signs an order, returning it in the JSON
+// shape POST /order expects.
+func (c *Client) signOrder(
+	tokenID string, side types.OrderSide,
+	makerAmt, takerAmt *big.Int, expiration int64,
+) (map[string]interface{}, error) {
+	if c.key == nil {
+		return nil, fmt.Errorf("no private key — cannot place orders")
+	}
+	if c.creds == nil {
+		return nil, fmt.Errorf("API creds not set — call CreateOrDeriveAPICreds first")
 	}
 
 	tokenIDBig, err := TokenIDFromHex(tokenID)
@@ -217,11 +325,10 @@ func (c *Client) PlaceMarketOrder(req MarketOrderRequest) (map[string]interface{
 		return nil, fmt.Errorf("invalid tokenID: %w", err)
 	}
 
-	maker := c.address
-	if c.sigType == types.SigGnosisSafe {
-		maker = c.funder // Safe is the maker; EOA is the signer
-	}
+	maker := c.makerAddress()
 
+	salt := big.NewInt(rand.Int63())
+	exp := big.NewInt(expiration)
 	params := OrderParams{
 		Salt:          salt,
 		Maker:         maker,
@@ -230,10 +337,10 @@ func (c *Client) PlaceMarketOrder(req MarketOrderRequest) (map[string]interface{
 		TokenID:       tokenIDBig,
 		MakerAmount:   makerAmt,
 		TakerAmount:   takerAmt,
-		Expiration:    big.NewInt(0),
+		Expiration:    exp,
 		Nonce:         big.NewInt(0),
 		FeeRateBps:    big.NewInt(0),
-		Side:          0, // BUY
+		Side:          uint8(side),
 		SignatureType: uint8(c.sigType),
 	}
 
@@ -242,7 +349,7 @@ func (c *Client) PlaceMarketOrder(req MarketOrderRequest) (map[string]interface{
 		return nil, fmt.Errorf("sign order: %w", err)
 	}
 
-	order := map[string]interface{}{
+	return map[string]interface{}{
 		"salt":          salt.String(),
 		"maker":         strings.ToLower(maker.Hex()),
 		"signer":        strings.ToLower(c.address.Hex()),
@@ -250,64 +357,291 @@ func (c *Client) PlaceMarketOrder(req MarketOrderRequest) (map[string]interface{
 		"tokenId":       tokenIDBig.String(),
 		"makerAmount":   makerAmt.String(),
 		"takerAmount":   takerAmt.String(),
-		"expiration":    "0",
+		"expiration":    exp.String(),
 		"nonce":         "0",
 		"feeRateBps":    "0",
-		"side":          0,
+		"side":          int(side),
 		"signatureType": int(c.sigType),
 		"signature":     sig,
-	}
+	}, nil
+}
 
-	body := map[string]interface{}{
-		"order":     order,
-		"orderType": "FOK",
-	}
+// ── Cancellation ──────────────────────────────────────────────────────────
 
-	return c.postL2("/order", body)
+// CancelResponse is the CLOB reply to every cancel endpoint.
+type CancelResponse struct {
+	Canceled    []string          `json:"canceled"`
+	NotCanceled map[string]string `json:"not_canceled"` // orderID → reason
 }
 
-// ── Trade history ─────────────────────────────────────────────────────────
+// CancelOrder cancels one resting order by ID (DELETE /order).
+func (c *Client) CancelOrder(ctx context.Context, orderID string) (*CancelResponse, error) {
+	return c.cancel(ctx, "/order", map[string]string{"orderID": orderID})
+}
 
-// Trade represents a single trade entry from /data/trades.
-type Trade struct {
-	Market    string `json:"market"`
-	Side      string `json:"side"`
-	Outcome   string `json:"outcome"`
-	Size      string `json:"size"`
-	Price     string `json:"price"`
-	Status    string `json:"status"`
-	AssetID   string `json:"asset_id"`
-	Timestamp string `json:"timestamp"`
-}
-
-// GetTrades fetches recent trade history (L2 auth required).
-func (c *Client) GetTrades(nextCursor string) ([]Trade, error) {
+// CancelOrders cancels several resting orders by ID (DELETE /orders).
+func (c *Client) CancelOrders(ctx context.Context, orderIDs []string) (*CancelResponse, error) {
+	return c.cancel(ctx, "/orders", orderIDs)
+}
+
+// CancelAll cancels every resting order of the account (DELETE /cancel-all).
+func (c *Client) CancelAll(ctx context.Context) (*CancelResponse, error) {
+	return c.cancel(ctx, "/cancel-all", nil)
+}
+
+// CancelMarket cancels every resting order in one market, optionally
+// narrowed to one token (DELETE /cancel-market-orders). Either argument
+// may be empty.
+func (c *Client) CancelMarket(ctx context.Context, conditionID, tokenID string) (*CancelResponse, error) {
+	return c.cancel(ctx, "/cancel-market-orders", map[string]string{
+		"market":   conditionID,
+		"asset_id": tokenID,
+	})
+}
+
+func (c *Client) cancel(ctx context.Context, path string, payload interface{}) (*CancelResponse, error) {
+	if c.creds == nil {
+		return nil, fmt.Errorf("API creds not set")
+	}
+	body, err := c.deleteL2(ctx, path, payload)
+	if err != nil {
+		return nil, err
+	}
+	var cr CancelResponse
+	if err := json.Unmarshal(body, &cr); err != nil {
+		return nil, fmt.Errorf("parse DELETE %s: %w", path, err)
+	}
+	return &cr, nil
+}
+
+// ── Open orders ───────────────────────────────────────────────────────────
+
+// OpenOrder is a resting order as returned by /data/orders and /data/order.
+type OpenOrder struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	Market       string `json:"market"`
+	AssetID      string `json:"asset_id"`
+	Side         string `json:"side"`
+	OriginalSize string `json:"original_size"`
+	SizeMatched  string `json:"size_matched"`
+	Price        string `json:"price"`
+	Outcome      string `json:"outcome"`
+	OrderType    string `json:"order_type"`
+	Expiration   string `json:"expiration"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+// GetOpenOrders lists the account's resting orders, optionally filtered by
+// market (condition ID) and/or token. Follows next_cursor to the last page.
+func (c *Client) GetOpenOrders(ctx context.Context, conditionID, tokenID string) ([]OpenOrder, error) {
 	if c.creds == nil {
 		return nil, fmt.Errorf("API creds not set")
 	}
 
-	path := "/data/trades"
-	if nextCursor != "" && nextCursor != "MA==" {
-		path += "?next_cursor=" + nextCursor
+	var all []OpenOrder
+	cursor := ""
+	for {
+		params := url.Values{}
+		if conditionID != "" {
+			params.Set("market", conditionID)
+		}
+		if tokenID != "" {
+			params.Set("asset_id", tokenID)
+		}
+		if cursor != "" {
+			params.Set("next_cursor", cursor)
+		}
+		path := "/data/orders"
+		if len(params) > 0 {
+			path += "?" + params.Encode()
+		}
+
+		body, err := c.getL2(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Data       []OpenOrder `json:"data"`
+			NextCursor string      `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			// Some deployments return a bare array
+			var orders []OpenOrder
+			if err2 := json.Unmarshal(body, &orders); err2 != nil {
+				return nil, fmt.Errorf("parse /data/orders: %w", err)
+			}
+			return append(all, orders...), nil
+		}
+		all = append(all, page.Data...)
+		if page.NextCursor == "" || page.NextCursor == endCursor || page.NextCursor == cursor {
+			return all, nil
+		}
+		cursor = page.NextCursor
 	}
+}
 
-	body, err := c.getL2(path)
+// GetOrder fetches one order by ID (GET /data/order/{id}).
+func (c *Client) GetOrder(ctx context.Context, orderID string) (*OpenOrder, error) {
+	if c.creds == nil {
+		return nil, fmt.Errorf("API creds not set")
+	}
+	body, err := c.getL2(ctx, "/data/order/"+orderID)
 	if err != nil {
 		return nil, err
 	}
+	var o OpenOrder
+	if err := json.Unmarshal(body, &o); err != nil {
+		return nil, fmt.Errorf("parse /data/order: %w", err)
+	}
+	return &o, nil
+}
+
+// ── Trade history ─────────────────────────────────────────────────────────
+
+// endCursor is the next_cursor value the CLOB returns on the last page.
+const endCursor = "LTE="
+
+// Trade represents a single trade entry from /data/trades. The top-level
+// side, outcome, asset, size and price are the taker order's; when we were
+// a maker (TraderSide "MAKER") our part is in MakerOrders.
+type Trade struct {
+	ID          string       `json:"id"`
+	Market      string       `json:"market"`
+	Side        string       `json:"side"`
+	Outcome     string       `json:"outcome"`
+	Size        string       `json:"size"`
+	Price       string       `json:"price"`
+	Status      string       `json:"status"`
+	AssetID     string       `json:"asset_id"`
+	Timestamp   string       `json:"timestamp"`
+	FeeRateBps  string       `json:"fee_rate_bps"`
+	TraderSide  string       `json:"trader_side,omitempty"` // TAKER / MAKER
+	Owner       string       `json:"owner,omitempty"`
+	MakerOrders []MakerOrder `json:"maker_orders,omitempty"`
+}
 
-	// Response can be []Trade or {data: []Trade}
-	var trades []Trade
-	if err := json.Unmarshal(body, &trades); err == nil {
-		return trades, nil
+// MakerOrder is one resting order a trade matched against.
+type MakerOrder struct {
+	OrderID       string `json:"order_id"`
+	Owner         string `json:"owner"`
+	MakerAddress  string `json:"maker_address"`
+	MatchedAmount string `json:"matched_amount"`
+	Price         string `json:"price"`
+	FeeRateBps    string `json:"fee_rate_bps"`
+	AssetID       string `json:"asset_id"`
+	Outcome       string `json:"outcome"`
+	Side          string `json:"side"`
+}
+
+// TradeLeg is our side of a trade: what we bought or sold, and at what
+// price.
+type TradeLeg struct {
+	Side       string // BUY / SELL
+	Outcome    string
+	AssetID    string
+	Size       string
+	Price      string
+	FeeRateBps string
+}
+
+// Legs returns our legs of t. As the taker that is the trade itself; as a
+// maker it is each of our orders among t.MakerOrders, recognized by API key
+// or maker address. A maker order without a side is inferred from the
+// taker's: opposite on the same token, the same on the complementary one
+// (a mint or merge match).
+func (t Trade) Legs(apiKey, maker string) []TradeLeg {
+	if !strings.EqualFold(t.TraderSide, "MAKER") {
+		return []TradeLeg{{
+			Side:       t.Side,
+			Outcome:    t.Outcome,
+			AssetID:    t.AssetID,
+			Size:       t.Size,
+			Price:      t.Price,
+			FeeRateBps: t.FeeRateBps,
+		}}
+	}
+	var legs []TradeLeg
+	for _, mo := range t.MakerOrders {
+		ours := (apiKey != "" && mo.Owner == apiKey) ||
+			(maker != "" && strings.EqualFold(mo.MakerAddress, maker))
+		if !ours {
+			continue
+		}
+		side := mo.Side
+		if side == "" {
+			side = t.Side
+			if mo.AssetID == t.AssetID {
+				side = oppositeSide(t.Side)
+			}
+		}
+		legs = append(legs, TradeLeg{
+			Side:       side,
+			Outcome:    mo.Outcome,
+			AssetID:    mo.AssetID,
+			Size:       mo.MatchedAmount,
+			Price:      mo.Price,
+			FeeRateBps: mo.FeeRateBps,
+		})
+	}
+	return legs
+}
+
+func oppositeSide(side string) string {
+	switch side {
+	case "BUY":
+		return "SELL"
+	case "SELL":
+		return "BUY"
 	}
-	var wrapped struct {
-		Data []Trade `json:"data"`
+	return side
+}
+
+// GetTrades fetches the account's trade history (L2 auth required),
+// following next_cursor to the last page. after > 0 limits it to trades
+// after that unix time.
+func (c *Client) GetTrades(ctx context.Context, after int64) ([]Trade, error) {
+	if c.creds == nil {
+		return nil, fmt.Errorf("API creds not set")
 	}
-	if err := json.Unmarshal(body, &wrapped); err != nil {
-		return nil, fmt.Errorf("parse /data/trades: %w", err)
+
+	var all []Trade
+	cursor := ""
+	for {
+		params := url.Values{}
+		if after > 0 {
+			params.Set("after", strconv.FormatInt(after, 10))
+		}
+		if cursor != "" {
+			params.Set("next_cursor", cursor)
+		}
+		path := "/data/trades"
+		if len(params) > 0 {
+			path += "?" + params.Encode()
+		}
+
+		body, err := c.getL2(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Data       []Trade `json:"data"`
+			NextCursor string  `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			// Some deployments return a bare array
+			var trades []Trade
+			if err2 := json.Unmarshal(body, &trades); err2 != nil {
+				return nil, fmt.Errorf("parse /data/trades: %w", err)
+			}
+			return append(all, trades...), nil
+		}
+		all = append(all, page.Data...)
+		if page.NextCursor == "" || page.NextCursor == endCursor || page.NextCursor == cursor {
+			return all, nil
+		}
+		cursor = page.NextCursor
 	}
-	return wrapped.Data, nil
 }
 
 // ── L1 / L2 helpers ──────────────────────────────────────────────────────
@@ -326,8 +660,14 @@ func (c *Client) addL1Headers(req *http.Request, sig, ts, nonce string) {
 // hmacL2Sign computes the HMAC-SHA256 L2 signature.
 // message = timestamp + method + path + body
 func (c *Client) hmacL2Sign(ts, method, path, body string) string {
-	secret, _ := base64.URLEncoding.DecodeString(c.creds.APISecret)
-	mac := hmac.New(sha256.New, secret)
+	return L2Signature(c.creds.APISecret, ts, method, path, body)
+}
+
+// L2Signature computes the base64url HMAC-SHA256 of ts+method+path+body
+// keyed with the base64url-encoded API secret.
+func L2Signature(secret, ts, method, path, body string) string {
+	key, _ := base64.URLEncoding.DecodeString(secret)
+	mac := hmac.New(sha256.New, key)
 	mac.Write([]byte(ts + method + path + body))
 	return base64.URLEncoding.EncodeToString(mac.Sum(nil))
 }
@@ -343,13 +683,22 @@ func (c *Client) addL2Headers(req *http.Request, method, path, body string) {
 	req.Header.Set("Content-Type", "application/json")
 }
 
-func (c *Client) postL2(path string, payload interface{}) (map[string]interface{}, error) {
+// get issues an unauthenticated GET bound to ctx.
+func (c *Client) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.httpCli.Do(req)
+}
+
+func (c *Client) postL2(ctx context.Context, path string, payload interface{}) (map[string]interface{}, error) {
 	bodyBytes, err := json.Marshal(payload)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", c.host+path, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.host+path, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return nil, err
 	}
@@ -373,8 +722,36 @@ func (c *Client) postL2(path string, payload interface{}) (map[string]interface{
 	return result, nil
 }
 
-func (c *Client) getL2(path string) ([]byte, error) {
-	req, err := http.NewRequest("GET", c.host+path, nil)
+func (c *Client) deleteL2(ctx context.Context, path string, payload interface{}) ([]byte, error) {
+	var bodyBytes []byte
+	if payload != nil {
+		var err error
+		if bodyBytes, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.host+path, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	c.addL2Headers(req, "DELETE", path, string(bodyBytes))
+
+	resp, err := c.httpCli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("DELETE %s: HTTP %d: %s", path, resp.StatusCode, respBody)
+	}
+	return respBody, nil
+}
+
+func (c *Client) getL2(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.host+path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -43,8 +43,8 @@ type OrderParams struct {
 	Signer        common.Address // actual signer (EOA)
 	Taker         common.Address // zero address = open order
 	TokenID       *big.Int       // token ID (uint256)
-	MakerAmount   *big.Int       // USDC in (6 decimals)
-	TakerAmount   *big.Int       // tokens out (6 decimals)
+	MakerAmount   *big.Int       // BUY: USDC in / SELL: tokens in (6 decimals)
+	TakerAmount   *big.Int       // BUY: tokens out / SELL: USDC out (6 decimals)
 	Expiration    *big.Int       // 0 = no expiry
 	Nonce         *big.Int       // 0 for market orders
 	FeeRateBps    *big.Int       // 0 for taker orders
@@ -58,16 +58,8 @@ type OrderParams struct {
 //
 // isNegRisk selects the NegRisk CTF Exchange domain.
 func BuildAndSignOrder(params OrderParams, key *ecdsa.PrivateKey, isNegRisk bool) (string, error) {
-	// 1. Build domain separator
-	domainSep := buildDomainSeparator(isNegRisk)
-
-	// 2. Build struct hash
-	structHash := buildOrderStructHash(params)
-
-	// 3. EIP-712 digest: 0x1901 + domainSep + structHash
-	digest := crypto.Keccak256(
-		append([]byte{0x19, 0x01}, append(domainSep, structHash...)...),
-	)
+	// 1-3. Domain separator + struct hash → EIP-712 digest
+	digest := OrderDigest(params, isNegRisk)
 
 	// 4. Sign
 	sig, err := crypto.Sign(digest, key)
@@ -87,6 +79,22 @@ func BuildAndSignOrder(params OrderParams, key *ecdsa.PrivateKey, isNegRisk bool
 	return "0x" + hex.EncodeToString(sig), nil
 }
 
+// OrderDigest returns the EIP-712 digest that BuildAndSignOrder signs:
+// keccak256(0x1901 + domainSeparator + structHash).
+func OrderDigest(params OrderParams, isNegRisk bool) []byte {
+	domainSep := buildDomainSeparator(isNegRisk)
+	structHash := buildOrderStructHash(params)
+	return crypto.Keccak256(
+		append([]byte{0x19, 0x01}, append(domainSep, structHash...)...),
+	)
+}
+
+// RecoverOrderSigner returns the address that produced sigHex over the
+// order's EIP-712 digest. Accepts the trailing GnosisSafe type byte.
+func RecoverOrderSigner(params OrderParams, sigHex string, isNegRisk bool) (common.Address, error) {
+	return recoverSigner(OrderDigest(params, isNegRisk), sigHex)
+}
+
 // ── Domain separator ──────────────────────────────────────────────────────
 
 func buildDomainSeparator(isNegRisk bool) []byte {
@@ -181,6 +189,34 @@ func PersonalSign(message string, key *ecdsa.PrivateKey) (string, error) {
 	return "0x" + hex.EncodeToString(sig), nil
 }
 
+// RecoverPersonalSigner returns the address that personal_signed message.
+func RecoverPersonalSigner(message, sigHex string) (common.Address, error) {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))
+	return recoverSigner(crypto.Keccak256([]byte(prefix+message)), sigHex)
+}
+
+// recoverSigner recovers the signer of a 65-byte [R|S|V] signature (V = 27/28),
+// ignoring any extra trailing bytes (e.g. the GnosisSafe \x02 marker).
+func recoverSigner(hash []byte, sigHex string) (common.Address, error) {
+	sig, err := hex.DecodeString(strings.TrimPrefix(sigHex, "0x"))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("decode signature: %w", err)
+	}
+	if len(sig) < 65 {
+		return common.Address{}, fmt.Errorf("signature too short: %d bytes", len(sig))
+	}
+	rsv := make([]byte, 65)
+	copy(rsv, sig[:65])
+	if rsv[64] >= 27 {
+		rsv[64] -= 27
+	}
+	pub, err := crypto.SigToPub(hash, rsv)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("recover: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
 // ── Key helpers ───────────────────────────────────────────────────────────
 
 // ParsePrivateKey parses a hex private key string (with or without 0x prefix).
@@ -13,13 +13,16 @@ import (
 
 // ── Polymarket API endpoints ─────────────────────────────────────────────
 const (
-	CLOBHost  = "https://clob.polymarket.com"
-	GammaHost = "https://gamma-api.polymarket.com"
-	ChainID   = 137 // Polygon mainnet
+	DefaultCLOBHost = "https://clob.polymarket.com"
+	GammaHost       = "https://gamma-api.polymarket.com"
+	ChainID         = 137 // Polygon mainnet
 )
 
 // ── Config fields (populated by Load) ───────────────────────────────────
 var (
+	// CLOB REST host (override with CLOB_HOST to target a fake/paper server)
+	CLOBHost = DefaultCLOBHost
+
 	// Credentials
 	PrivateKey      string
 	FunderAddress   string
@@ -45,13 +48,68 @@ var (
 	MomentumHedgeUSDC float64
 	MomentumMaxUSDC   float64
 
+	// Maker ARB: resting bids on both sides in the grey zone (off by default)
+	ARBMakerEnabled bool
+	ARBMakerPairs   float64 // tokens bid on each side
+	ARBMakerTTLSec  int     // GTD lifetime of each quote (0 = GTC)
+
+	// Reverse ARB: split USDC into pairs and sell both legs (0 threshold = disabled)
+	SplitThreshold float64 // UP bid + DOWN bid must exceed this
+	SplitOrderUSDC float64 // pairs minted per cycle without book depth
+	SplitMaxUSDC   float64 // per-market cap on USDC split
+
+	// Bot-wide risk limits (0 = disabled)
+	RiskMaxExposureUSDC   float64 // net USDC at risk across tracked markets
+	RiskMaxDailySpendUSDC float64 // USDC spent on buys per UTC day
+	RiskMaxDailyLossUSDC  float64 // realized loss per UTC day (latches kill switch)
+	RiskMaxOrdersPerMin   int     // market orders per minute (latches kill switch)
+	RiskStateFile         string
+
 	// Timing
-	PollIntervalSec  float64
-	MarketRefreshMin int
-	MaxMarketAgeH    int
+	PollIntervalSec    float64
+	MarketRefreshMin   int
+	MaxMarketAgeH      int
+	ShutdownTimeoutSec int // how long in-flight orders/merges may finish after SIGTERM
+	RedeemIntervalMin  int // how often resolved conditions are redeemed (0 = never)
+
+	// Market orders not confirmed on-chain within this long are flagged (0 = never)
+	OrderConfirmTimeoutSec int
+
+	// On-chain transactions (merge, split, redeem, approvals)
+	TxTimeoutSec   int     // how long a Safe tx may take to be mined
+	TxBumpAfterSec int     // re-broadcast with +20% fees after this long (0 = never)
+	TxMaxFeeGwei   float64 // ceiling for the EIP-1559 max fee
+	TxStateFile    string  // pending txs, kept across restarts
+
+	// Overwrite inventory balances with the Safe's on-chain balances
+	// (startup, before each merge, POST /reconcile)
+	ReconcileOnChain bool
 
 	// Inventory
-	InventoryFile string
+	InventoryFile        string // snapshot
+	InventoryJournalFile string // changes since the snapshot ("" = InventoryFile + ".journal")
+	FSMStateFile         string // per-market caps and cooldowns
+	PnLFile              string // settled markets and daily P&L
+	TradesFile           string // local CLOB trade history (append-only NDJSON)
+
+	// Storage backend: "json" (files above) or "sqlite" (inventory and
+	// history in one database)
+	StorageBackend         string
+	SQLitePath             string
+	SQLitePriceIntervalSec int // min seconds between price snapshots per market
+
+	// Market-data recording (empty RecordDir = disabled)
+	RecordDir   string
+	RecordMaxMB int
+
+	// Prometheus /metrics listen address (empty = disabled)
+	MetricsAddr string
+
+	// Control/status API listen address (empty = disabled, ":port" = localhost)
+	APIAddr string
+
+	// Bearer token the control API's POST routes require (empty = refused)
+	APIToken string
 )
 
 // Load reads .env (if present) then overrides from OS env vars.
@@ -60,6 +118,8 @@ func Load() {
 		log.Println("[config] No .env file found, using OS environment")
 	}
 
+	CLOBHost = strings.TrimRight(getEnv("CLOB_HOST", DefaultCLOBHost), "/")
+
 	// Credentials
 	PrivateKey      = getEnv("PRIVATE_KEY", "")
 	FunderAddress   = getEnv("FUNDER_ADDRESS", "")
@@ -91,13 +151,63 @@ func Load() {
 	MomentumHedgeUSDC = getEnvFloat("MOMENTUM_HEDGE_USDC", 1.0)
 	MomentumMaxUSDC   = getEnvFloat("MOMENTUM_MAX_USDC", 30.0)
 
+	// Maker ARB
+	ARBMakerEnabled = getEnvBool("ARB_MAKER", false)
+	ARBMakerPairs   = getEnvFloat("ARB_MAKER_PAIRS", 10.0)
+	ARBMakerTTLSec  = getEnvInt("ARB_MAKER_TTL_SEC", 300)
+
+	// Reverse ARB
+	SplitThreshold = getEnvFloat("SPLIT_THRESHOLD", 0)
+	SplitOrderUSDC = getEnvFloat("SPLIT_ORDER_USDC", 5.0)
+	SplitMaxUSDC   = getEnvFloat("SPLIT_MAX_USDC", 20.0)
+
+	// Risk limits
+	RiskMaxExposureUSDC   = getEnvFloat("RISK_MAX_EXPOSURE_USDC", 200.0)
+	RiskMaxDailySpendUSDC = getEnvFloat("RISK_MAX_DAILY_SPEND_USDC", 500.0)
+	RiskMaxDailyLossUSDC  = getEnvFloat("RISK_MAX_DAILY_LOSS_USDC", 50.0)
+	RiskMaxOrdersPerMin   = getEnvInt("RISK_MAX_ORDERS_PER_MIN", 30)
+	RiskStateFile         = getEnv("RISK_STATE_FILE", "risk_state.json")
+
 	// Timing
-	PollIntervalSec  = getEnvFloat("POLL_INTERVAL", 2.0)
-	MarketRefreshMin = getEnvInt("MARKET_REFRESH_MIN", 10)
-	MaxMarketAgeH    = getEnvInt("MAX_MARKET_AGE_H", 4)
+	PollIntervalSec    = getEnvFloat("POLL_INTERVAL", 2.0)
+	MarketRefreshMin   = getEnvInt("MARKET_REFRESH_MIN", 10)
+	MaxMarketAgeH      = getEnvInt("MAX_MARKET_AGE_H", 4)
+	ShutdownTimeoutSec = getEnvInt("SHUTDOWN_TIMEOUT_SEC", 30)
+	RedeemIntervalMin  = getEnvInt("REDEEM_INTERVAL_MIN", 10)
+
+	OrderConfirmTimeoutSec = getEnvInt("ORDER_CONFIRM_TIMEOUT_SEC", 300)
+
+	// On-chain transactions
+	TxTimeoutSec   = getEnvInt("TX_TIMEOUT_SEC", 180)
+	TxBumpAfterSec = getEnvInt("TX_BUMP_AFTER_SEC", 30)
+	TxMaxFeeGwei   = getEnvFloat("TX_MAX_FEE_GWEI", 1000)
+	TxStateFile    = getEnv("TX_STATE_FILE", "tx_state.json")
+
+	ReconcileOnChain = getEnvBool("RECONCILE_ONCHAIN", true)
 
 	// Inventory
 	InventoryFile = getEnv("INVENTORY_FILE", "inventory_state.json")
+	FSMStateFile  = getEnv("FSM_STATE_FILE", "fsm_state.json")
+	PnLFile       = getEnv("PNL_FILE", "pnl_ledger.json")
+	TradesFile    = getEnv("TRADES_FILE", "trades.ndjson")
+
+	InventoryJournalFile = getEnv("INVENTORY_JOURNAL_FILE", "")
+
+	// Storage
+	StorageBackend         = strings.ToLower(getEnv("STORAGE_BACKEND", "json"))
+	SQLitePath             = getEnv("SQLITE_PATH", "bot.db")
+	SQLitePriceIntervalSec = getEnvInt("SQLITE_PRICE_INTERVAL_SEC", 10)
+
+	// Recording
+	RecordDir   = getEnv("RECORD_DIR", "")
+	RecordMaxMB = getEnvInt("RECORD_MAX_MB", 64)
+
+	// Metrics
+	MetricsAddr = getEnv("METRICS_ADDR", "")
+
+	// Control API
+	APIAddr = getEnv("API_ADDR", "")
+	APIToken = getEnv("API_TOKEN", "")
 }
 
 // ── Helpers ──────────────────────────────────────────────────────────────
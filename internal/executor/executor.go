@@ -3,51 +3,142 @@
 package executor
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"math"
+	"sync"
+	"time"
 
+	"github.com/gipsh/polymarket-bot-go/internal/book"
 	"github.com/gipsh/polymarket-bot-go/internal/clob"
 	"github.com/gipsh/polymarket-bot-go/internal/config"
 	"github.com/gipsh/polymarket-bot-go/internal/inventory"
 	"github.com/gipsh/polymarket-bot-go/internal/merger"
+	"github.com/gipsh/polymarket-bot-go/internal/onchain"
+	"github.com/gipsh/polymarket-bot-go/internal/store"
 	"github.com/gipsh/polymarket-bot-go/internal/types"
 )
 
 // Executor places orders and executes MERGE via the CLOB client.
 type Executor struct {
-	inv    *inventory.Inventory
+	inv    inventory.Inventory
 	client *clob.Client
 	merger *merger.Merger
+	db     *store.DB   // order and on-chain history (nil = not recorded)
+	books  BookSource  // live order books for re-pricing hedges (nil = REST)
+	prices PriceSource // resolution for dry runs without a merger (nil = none)
+	orders *orderTracker
 	dryRun bool
+
+	mu        sync.Mutex
+	closing   bool           // Shutdown called: no new orders or merges
+	redeeming bool           // a RedeemResolved sweep is running
+	inflight sync.WaitGroup // orders and merges in progress
 }
 
 // New creates an Executor. If dryRun=true, no real orders are placed.
-func New(inv *inventory.Inventory, client *clob.Client, dryRun bool) *Executor {
+func New(inv inventory.Inventory, client *clob.Client, dryRun bool) *Executor {
 	m := merger.New()
 	return &Executor{
 		inv:    inv,
 		client: client,
 		merger: m,
+		orders: newOrderTracker(),
 		dryRun: dryRun || config.DryRun,
 	}
 }
 
-// BuyMarket places a market (FOK) BUY order for the given side.
+// SetStore enables recording orders, merges, splits and redeems in db.
+func (e *Executor) SetStore(db *store.DB) {
+	e.db = db
+}
+
+// BookSource serves live order books (ws.Pricer).
+type BookSource interface {
+	Book(tokenID string) *book.Book
+}
+
+// SetBooks sets where hedges read the current book from. Without one they
+// fetch it over REST.
+func (e *Executor) SetBooks(src BookSource) {
+	e.books = src
+}
+
+// PriceSource serves current prices (REST pricer).
+type PriceSource interface {
+	GetPrices(ctx context.Context, upTokenID, downTokenID string) (*types.Prices, error)
+}
+
+// SetPrices sets where a dry run without an on-chain merger learns that a
+// market resolved (its prices show the winner), so RedeemResolved can
+// simulate redemptions.
+func (e *Executor) SetPrices(src PriceSource) {
+	e.prices = src
+}
+
+// errShuttingDown is reported for orders refused after Shutdown.
+const errShuttingDown = "executor shutting down"
+
+// begin registers an in-flight operation. Returns false after Shutdown.
+func (e *Executor) begin() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closing {
+		return false
+	}
+	e.inflight.Add(1)
+	return true
+}
+
+// Shutdown stops accepting new orders and merges, then waits for the ones
+// in flight to finish or for ctx to expire.
+func (e *Executor) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	e.closing = true
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("in-flight orders/merges still running: %w", ctx.Err())
+	}
+}
+
+// BuyMarket places a market (FOK) BUY order for the given side, spending
+// usdcAmount on asks up to priceHint. tokens is the fill expected when the
+// order walks several levels, used to book dry runs and responses that
+// leave the amounts out (0 = usdcAmount / priceHint).
 func (e *Executor) BuyMarket(
+	ctx context.Context,
 	conditionID, upTokenID, downTokenID, side string,
-	usdcAmount, priceHint float64,
+	usdcAmount, priceHint, tokens float64,
 ) types.OrderResult {
 	tokenID := upTokenID
 	if side == "DOWN" {
 		tokenID = downTokenID
 	}
+	if !e.begin() {
+		return types.OrderResult{TokenID: tokenID, Side: side, Error: errShuttingDown}
+	}
+	defer e.inflight.Done()
+
+	if tokens <= 0 {
+		tokens = usdcAmount / max64(priceHint, 0.01)
+	}
 
 	if e.dryRun {
-		estimated := usdcAmount / max64(priceHint, 0.01)
+		estimated := tokens
 		log.Printf("[executor] [DRY_RUN] Would BUY %s | $%.2f USDC | token: %s...",
 			side, usdcAmount, tokenID[:12])
 		e.inv.RecordBuy(conditionID, upTokenID, downTokenID, side, estimated, usdcAmount)
-		return types.OrderResult{
+		res := types.OrderResult{
 			Success:        true,
 			TokenID:        tokenID,
 			Side:           side,
@@ -55,9 +146,11 @@ func (e *Executor) BuyMarket(
 			TokensReceived: estimated,
 			OrderID:        "dry-run",
 		}
+		e.recordOrder(conditionID, types.SideBuy, priceHint, estimated, usdcAmount, res)
+		return res
 	}
 
-	resp, err := e.client.PlaceMarketOrder(clob.MarketOrderRequest{
+	resp, err := e.client.PlaceMarketOrder(ctx, clob.MarketOrderRequest{
 		ConditionID: conditionID,
 		UpTokenID:   upTokenID,
 		DownTokenID: downTokenID,
@@ -67,12 +160,14 @@ func (e *Executor) BuyMarket(
 	})
 	if err != nil {
 		log.Printf("[executor] Order failed (%s $%.2f): %v", side, usdcAmount, err)
-		return types.OrderResult{
+		res := types.OrderResult{
 			Success: false,
 			TokenID: tokenID,
 			Side:    side,
 			Error:   err.Error(),
 		}
+		e.recordOrder(conditionID, types.SideBuy, priceHint, 0, usdcAmount, res)
+		return res
 	}
 
 	// Parse response: orderID, takingAmount (tokens), makingAmount (USDC)
@@ -83,16 +178,16 @@ func (e *Executor) BuyMarket(
 		usdcSpent = usdcAmount
 	}
 	if tokensReceived == 0 && usdcSpent > 0 {
-		tokensReceived = usdcSpent / max64(priceHint, 0.01)
-		log.Printf("[executor] takingAmount missing — estimating: $%.2f / %.3f = %.2f",
-			usdcSpent, priceHint, tokensReceived)
+		tokensReceived = tokens * usdcSpent / usdcAmount
+		log.Printf("[executor] takingAmount missing — estimating: $%.2f for %.2f tokens (limit %.3f)",
+			usdcSpent, tokensReceived, priceHint)
 	}
 
 	log.Printf("[executor] BUY %s executed | $%.2f USDC → %.3f tokens | order: %s",
 		side, usdcSpent, tokensReceived, orderID)
 	e.inv.RecordBuy(conditionID, upTokenID, downTokenID, side, tokensReceived, usdcSpent)
 
-	return types.OrderResult{
+	res := types.OrderResult{
 		Success:        true,
 		TokenID:        tokenID,
 		Side:           side,
@@ -100,21 +195,331 @@ func (e *Executor) BuyMarket(
 		TokensReceived: tokensReceived,
 		OrderID:        orderID,
 	}
+	e.recordOrder(conditionID, types.SideBuy, priceHint, tokensReceived, usdcSpent, res)
+	e.trackOrder(orderID, conditionID, upTokenID, downTokenID, side, types.SideBuy, tokensReceived, usdcSpent)
+	return res
 }
 
-// HandleFill is called by the user WebSocket when a fill is confirmed.
-func (e *Executor) HandleFill(fill types.FillEvent) {
-	log.Printf("[executor] ✅ WS fill | order=%s... %s %.4f @ %.4f outcome=%s tx=%s...",
-		fill.OrderID[:16], fill.Side, fill.Size, fill.Price, fill.Outcome, fill.TxHash[:16])
+// SellMarket places a market (FOK) SELL order for tokens of the given side,
+// accepting no less than priceHint USDC per token.
+func (e *Executor) SellMarket(
+	ctx context.Context,
+	conditionID, upTokenID, downTokenID, side string,
+	tokens, priceHint float64,
+) types.OrderResult {
+	tokenID := upTokenID
+	if side == "DOWN" {
+		tokenID = downTokenID
+	}
+	if !e.begin() {
+		return types.OrderResult{TokenID: tokenID, Side: side, Error: errShuttingDown}
+	}
+	defer e.inflight.Done()
+
+	if e.dryRun {
+		estimated := tokens * priceHint
+		log.Printf("[executor] [DRY_RUN] Would SELL %s | %.3f tokens @ ≥%.3f | token: %s...",
+			side, tokens, priceHint, tokenID[:12])
+		e.inv.RecordSell(conditionID, side, tokens, estimated)
+		res := types.OrderResult{
+			Success:      true,
+			TokenID:      tokenID,
+			Side:         side,
+			TokensSold:   tokens,
+			USDCReceived: estimated,
+			OrderID:      "dry-run",
+		}
+		e.recordOrder(conditionID, types.SideSell, priceHint, tokens, estimated, res)
+		return res
+	}
+
+	resp, err := e.client.PlaceMarketOrder(ctx, clob.MarketOrderRequest{
+		ConditionID: conditionID,
+		UpTokenID:   upTokenID,
+		DownTokenID: downTokenID,
+		Side:        side,
+		OrderSide:   types.SideSell,
+		Tokens:      tokens,
+		PriceHint:   priceHint,
+	})
+	if err != nil {
+		log.Printf("[executor] Sell failed (%s %.3f tokens): %v", side, tokens, err)
+		res := types.OrderResult{
+			Success: false,
+			TokenID: tokenID,
+			Side:    side,
+			Error:   err.Error(),
+		}
+		e.recordOrder(conditionID, types.SideSell, priceHint, tokens, 0, res)
+		return res
+	}
+
+	// SELL: makingAmount is tokens given, takingAmount is USDC received
+	orderID := getString(resp, "orderID")
+	tokensSold := getFloat(resp, "makingAmount")
+	if tokensSold == 0 {
+		tokensSold = tokens
+	}
+	usdcReceived := getFloat(resp, "takingAmount")
+	if usdcReceived == 0 {
+		usdcReceived = tokensSold * priceHint
+		log.Printf("[executor] takingAmount missing — estimating: %.3f × %.3f = $%.2f",
+			tokensSold, priceHint, usdcReceived)
+	}
+
+	log.Printf("[executor] SELL %s executed | %.3f tokens → $%.2f USDC | order: %s",
+		side, tokensSold, usdcReceived, orderID)
+	e.inv.RecordSell(conditionID, side, tokensSold, usdcReceived)
+
+	res := types.OrderResult{
+		Success:      true,
+		TokenID:      tokenID,
+		Side:         side,
+		TokensSold:   tokensSold,
+		USDCReceived: usdcReceived,
+		OrderID:      orderID,
+	}
+	e.recordOrder(conditionID, types.SideSell, priceHint, tokensSold, usdcReceived, res)
+	e.trackOrder(orderID, conditionID, upTokenID, downTokenID, side, types.SideSell, tokensSold, usdcReceived)
+	return res
+}
+
+// pairHedgeRetries is how many times the failed leg of an ARB pair is
+// re-submitted before the filled leg is unwound.
+const pairHedgeRetries = 2
+
+// pairHedgeMaxSlippage is the most (USDC per token) a hedge may pay above
+// the price the pair was planned at.
+const pairHedgeMaxSlippage = 0.03
+
+// pairHedgeRetryDelay is the pause before each hedge attempt, so the book
+// the failed leg hit can refill.
+const pairHedgeRetryDelay = 500 * time.Millisecond
+
+// pairUnwindMaxLoss is the most (USDC per token) an unwind sell may give up
+// against the filled leg's entry price.
+const pairUnwindMaxLoss = 0.05
+
+// PairResult holds the outcome of both legs of an ARB pair.
+type PairResult struct {
+	Up    types.OrderResult
+	Down  types.OrderResult
+	Pairs float64 // min(UP, DOWN) tokens received = mergeable pairs added
+}
+
+// BuyArbPair buys pairs UP and DOWN tokens concurrently, spending upUSDC
+// and downUSDC on asks up to upPrice and downPrice. If one leg fails it is
+// re-submitted (hedged) up to pairHedgeRetries times, re-priced from the
+// book, so the cycle still ends in mergeable pairs; if it keeps failing, the
+// unmatched part of the filled leg is sold back (unwound). Anything left over
+// is handled by the FSM's rebalance.
+func (e *Executor) BuyArbPair(
+	ctx context.Context,
+	conditionID, upTokenID, downTokenID string,
+	pairs, upUSDC, downUSDC, upPrice, downPrice float64,
+) PairResult {
+	var res PairResult
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		res.Up = e.BuyMarket(ctx, conditionID, upTokenID, downTokenID, "UP", upUSDC, upPrice, pairs)
+	}()
+	go func() {
+		defer wg.Done()
+		res.Down = e.BuyMarket(ctx, conditionID, upTokenID, downTokenID, "DOWN", downUSDC, downPrice, pairs)
+	}()
+	wg.Wait()
+
+	switch {
+	case !res.Up.Success && !res.Down.Success:
+		return res
+	case !res.Up.Success:
+		res.Up = e.hedgeLeg(ctx, conditionID, upTokenID, downTokenID, "UP", upUSDC, upPrice, res.Down.TokensReceived)
+	case !res.Down.Success:
+		res.Down = e.hedgeLeg(ctx, conditionID, upTokenID, downTokenID, "DOWN", downUSDC, downPrice, res.Up.TokensReceived)
+	}
+
+	res.Pairs = min64(res.Up.TokensReceived, res.Down.TokensReceived)
+	if !res.Up.Success || !res.Down.Success {
+		filled := res.Up
+		if !res.Up.Success {
+			filled = res.Down
+		}
+		e.unwindLeg(ctx, conditionID, upTokenID, downTokenID, filled)
+	}
+	return res
+}
+
+// unwindLeg sells back the tokens of a filled leg whose partner never filled.
+func (e *Executor) unwindLeg(ctx context.Context, conditionID, upTokenID, downTokenID string, filled types.OrderResult) {
+	entry := filled.USDCSpent / max64(filled.TokensReceived, 0.000001)
+	floor := max64(entry-pairUnwindMaxLoss, 0.01)
+	log.Printf("[executor] unwinding ARB %s leg for %s... | %.3f tokens @ ≥%.3f",
+		filled.Side, conditionID[:8], filled.TokensReceived, floor)
+	sell := e.SellMarket(ctx, conditionID, upTokenID, downTokenID, filled.Side, filled.TokensReceived, floor)
+	if !sell.Success {
+		log.Printf("[executor] ⚠ ARB %s leg left unhedged for %s... (%.3f tokens): %s",
+			filled.Side, conditionID[:8], filled.TokensReceived, sell.Error)
+	}
+}
+
+// hedgeLeg re-submits the failed leg of a pair, sized to match the tokens
+// the other leg received. Each attempt is re-priced from the current book
+// and gives up once it asks more than pairHedgeMaxSlippage above priceHint.
+func (e *Executor) hedgeLeg(
+	ctx context.Context,
+	conditionID, upTokenID, downTokenID, side string,
+	usdc, priceHint, targetTokens float64,
+) types.OrderResult {
+	tokenID := upTokenID
+	if side == "DOWN" {
+		tokenID = downTokenID
+	}
+	maxPrice := math.Min(priceHint+pairHedgeMaxSlippage, 0.99)
+	result := types.OrderResult{TokenID: tokenID, Side: side}
+	for i := 1; i <= pairHedgeRetries; i++ {
+		select {
+		case <-ctx.Done():
+			result.Success, result.Error = false, ctx.Err().Error()
+			return result
+		case <-time.After(pairHedgeRetryDelay):
+		}
+
+		price, ok := e.hedgePrice(ctx, tokenID, targetTokens)
+		switch {
+		case !ok:
+			price = priceHint // no book: the planned price is all we have
+		case price > maxPrice:
+			log.Printf("[executor] ⚠ ARB %s leg for %s... not hedged: book asks %.3f, above %.3f (planned %.3f + %.2f slippage)",
+				side, conditionID[:8], price, maxPrice, priceHint, pairHedgeMaxSlippage)
+			result.Success = false
+			result.Error = fmt.Sprintf("hedge price %.3f above max %.3f", price, maxPrice)
+			return result
+		}
+		amount := usdc
+		if targetTokens > 0 {
+			amount = targetTokens * price
+		}
+		log.Printf("[executor] hedging ARB %s leg (attempt %d/%d) | $%.2f for %.3f tokens @ ≤%.3f",
+			side, i, pairHedgeRetries, amount, targetTokens, price)
+		result = e.BuyMarket(ctx, conditionID, upTokenID, downTokenID, side, amount, price, targetTokens)
+		if result.Success {
+			return result
+		}
+	}
+	return result
+}
+
+// hedgePrice returns the worst ask a buy of tokens reaches in the token's
+// current book (the best ask if tokens is 0, the last one if the book is too
+// thin). ok=false if there is no book to price from.
+func (e *Executor) hedgePrice(ctx context.Context, tokenID string, tokens float64) (float64, bool) {
+	var b *book.Book
+	if e.books != nil {
+		b = e.books.Book(tokenID)
+	}
+	if b == nil && e.client != nil {
+		var err error
+		if b, err = e.client.GetBook(ctx, tokenID); err != nil {
+			log.Printf("[executor] book for hedge unavailable: %v", err)
+		}
+	}
+	if b == nil {
+		return 0, false
+	}
+	worst, ok := 0.0, false
+	for _, l := range b.Asks() {
+		worst, ok = l.Price, true
+		if tokens -= l.Size; tokens <= 1e-9 {
+			break
+		}
+	}
+	return worst, ok
+}
+
+// SplitResult holds the outcome of a reverse ARB: the split and both sells.
+type SplitResult struct {
+	Pairs float64 // pairs minted by splitPosition (0 = split failed)
+	Up    types.OrderResult
+	Down  types.OrderResult
+}
+
+// SplitAndSell splits pairs USDC into UP+DOWN pairs on-chain, then sells
+// both legs concurrently at no less than upFloor/downFloor per token.
+// Unsold pairs are merged back at market close; a single unsold leg is
+// redeemed once the market resolves.
+func (e *Executor) SplitAndSell(
+	ctx context.Context,
+	conditionID, upTokenID, downTokenID string,
+	pairs, upFloor, downFloor float64,
+) SplitResult {
+	var res SplitResult
+	if !e.begin() {
+		log.Printf("[executor] SPLIT refused for %s...: %s", conditionID[:8], errShuttingDown)
+		return res
+	}
+
+	if e.dryRun {
+		log.Printf("[executor] [DRY_RUN] Would SPLIT $%.2f USDC → %.2f pairs | market: %s...",
+			pairs, pairs, conditionID[:8])
+		e.inv.RecordSplit(conditionID, upTokenID, downTokenID, pairs)
+		e.db.RecordChainTx(store.TxSplit, conditionID, pairs, "", store.TxDryRun)
+		res.Pairs = pairs
+	} else if !e.merger.Ready() {
+		log.Printf("[executor] on-chain SPLIT unavailable for %s...", conditionID[:8])
+	} else {
+		var txHash string
+		res.Pairs, txHash = e.merger.Split(ctx, conditionID, pairs)
+		if res.Pairs > 0 {
+			e.inv.RecordSplit(conditionID, upTokenID, downTokenID, res.Pairs)
+			e.db.RecordChainTx(store.TxSplit, conditionID, res.Pairs, txHash, store.TxOK)
+		} else {
+			e.db.RecordChainTx(store.TxSplit, conditionID, pairs, txHash, store.TxFailed)
+		}
+	}
+	// The sells register themselves; release the split's slot first so a
+	// Shutdown in between refuses them instead of waiting on us.
+	e.inflight.Done()
+	if res.Pairs == 0 {
+		return res
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		res.Up = e.SellMarket(ctx, conditionID, upTokenID, downTokenID, "UP", res.Pairs, upFloor)
+	}()
+	go func() {
+		defer wg.Done()
+		res.Down = e.SellMarket(ctx, conditionID, upTokenID, downTokenID, "DOWN", res.Pairs, downFloor)
+	}()
+	wg.Wait()
+
+	if !res.Up.Success || !res.Down.Success {
+		log.Printf("[executor] ⚠ SPLIT sells incomplete for %s... | UP ok=%v DOWN ok=%v — leftovers merged at close or redeemed",
+			conditionID[:8], res.Up.Success, res.Down.Success)
+	}
+	return res
 }
 
 // MergePairs executes on-chain MERGE for available UP+DOWN pairs.
 // Returns the number of pairs merged (= USDC received).
-func (e *Executor) MergePairs(conditionID string) float64 {
-	// Pre-merge reconcile
-	if _, err := e.inv.ReconcileFromAPI(e.client, false); err != nil {
+func (e *Executor) MergePairs(ctx context.Context, conditionID string) float64 {
+	if !e.begin() {
+		log.Printf("[executor] MERGE refused for %s...: %s", conditionID[:8], errShuttingDown)
+		return 0
+	}
+	defer e.inflight.Done()
+
+	// Pre-merge reconcile: trade history, then on-chain balances on top
+	if _, err := e.inv.ReconcileFromAPI(ctx, e.client, false); err != nil {
 		log.Printf("[executor] pre-merge reconcile failed: %v", err)
 	}
+	if _, err := e.ReconcileOnChain(ctx); err != nil {
+		log.Printf("[executor] pre-merge on-chain reconcile failed: %v", err)
+	}
 
 	pairs := e.inv.GetMergeablePairs(conditionID)
 	if pairs < 0.01 {
@@ -126,6 +531,7 @@ func (e *Executor) MergePairs(conditionID string) float64 {
 		log.Printf("[executor] [DRY_RUN] Would MERGE %.2f pairs → +$%.2f USDC | market: %s...",
 			pairs, pairs, conditionID[:8])
 		e.inv.RecordMerge(conditionID, pairs)
+		e.db.RecordChainTx(store.TxMerge, conditionID, pairs, "", store.TxDryRun)
 		return pairs
 	}
 
@@ -135,15 +541,165 @@ func (e *Executor) MergePairs(conditionID string) float64 {
 		return 0
 	}
 
-	merged := e.merger.Merge(conditionID, pairs)
+	merged, txHash := e.merger.Merge(ctx, conditionID, pairs)
 	if merged > 0 {
 		e.inv.RecordMerge(conditionID, merged)
+		e.db.RecordChainTx(store.TxMerge, conditionID, merged, txHash, store.TxOK)
+	} else {
+		e.db.RecordChainTx(store.TxMerge, conditionID, pairs, txHash, store.TxFailed)
 	}
 	return merged
 }
 
+// ReconcileOnChain overwrites inventory balances with the Safe's on-chain
+// balances for every tracked condition. No-op in dry run, without an
+// on-chain merger or with RECONCILE_ONCHAIN=false. Returns the number of
+// discrepancies corrected.
+func (e *Executor) ReconcileOnChain(ctx context.Context) (int, error) {
+	if e.dryRun || !e.merger.Ready() || !config.ReconcileOnChain {
+		return 0, nil
+	}
+	snapshot := e.inv.Snapshot()
+	if len(snapshot) == 0 {
+		return 0, nil
+	}
+	positions := make([]onchain.Position, 0, len(snapshot))
+	for cid, entry := range snapshot {
+		positions = append(positions, onchain.Position{
+			ConditionID: cid,
+			UpTokenID:   entry.UpTokenID,
+			DownTokenID: entry.DownTokenID,
+		})
+	}
+	balances, err := e.merger.Balances(ctx, positions)
+	if err != nil {
+		return 0, err
+	}
+	fixed := e.inv.ReconcileOnChain(balances)
+	log.Printf("[executor] on-chain reconcile: %d/%d conditions read, %d balances corrected",
+		len(balances), len(positions), fixed)
+	return fixed, nil
+}
+
+// RedeemResolved redeems every condition in inventory that still holds
+// tokens and has been resolved on-chain. Overlapping sweeps are skipped.
+// Returns the USDC redeemed.
+func (e *Executor) RedeemResolved(ctx context.Context) float64 {
+	e.mu.Lock()
+	if e.redeeming {
+		e.mu.Unlock()
+		return 0
+	}
+	e.redeeming = true
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		e.redeeming = false
+		e.mu.Unlock()
+	}()
+
+	// A dry run needs no merger to redeem, only to know the resolution
+	if !e.merger.Ready() && (!e.dryRun || e.prices == nil) {
+		return 0
+	}
+
+	total := 0.0
+	for cid, entry := range e.inv.Snapshot() {
+		if ctx.Err() != nil {
+			break
+		}
+		if entry.UpBalance < 0.01 && entry.DownBalance < 0.01 {
+			continue
+		}
+		payouts, resolved, err := e.resolution(ctx, cid, entry)
+		if err != nil {
+			log.Printf("[executor] resolution check for %s... failed: %v", cid[:8], err)
+			continue
+		}
+		if !resolved {
+			continue
+		}
+		total += e.redeem(ctx, cid, entry, payouts)
+	}
+	if total > 0 {
+		log.Printf("[executor] redeem sweep: +$%.2f USDC", total)
+	}
+	return total
+}
+
+// resolution returns a condition's payouts per outcome (UP, DOWN) once it
+// has resolved: on-chain when the merger is ready, else from its prices.
+func (e *Executor) resolution(ctx context.Context, conditionID string, entry inventory.Entry) ([2]float64, bool, error) {
+	if e.merger.Ready() {
+		return e.merger.Resolution(ctx, conditionID)
+	}
+	prices, err := e.prices.GetPrices(ctx, entry.UpTokenID, entry.DownTokenID)
+	if err != nil || prices.State != types.StateResolved {
+		return [2]float64{}, false, err
+	}
+	if prices.Winner() == "UP" {
+		return [2]float64{1, 0}, true, nil
+	}
+	return [2]float64{0, 1}, true, nil
+}
+
+// redeem redeems one resolved condition and records the payout.
+func (e *Executor) redeem(ctx context.Context, conditionID string, entry inventory.Entry, payouts [2]float64) float64 {
+	if !e.begin() {
+		log.Printf("[executor] REDEEM refused for %s...: %s", conditionID[:8], errShuttingDown)
+		return 0
+	}
+	defer e.inflight.Done()
+
+	if e.dryRun {
+		usdc := entry.UpBalance*payouts[0] + entry.DownBalance*payouts[1]
+		log.Printf("[executor] [DRY_RUN] Would REDEEM UP=%.2f DOWN=%.2f → +$%.2f USDC | market: %s...",
+			entry.UpBalance, entry.DownBalance, usdc, conditionID[:8])
+		e.inv.RecordRedeem(conditionID, usdc)
+		e.db.RecordChainTx(store.TxRedeem, conditionID, usdc, "", store.TxDryRun)
+		return usdc
+	}
+
+	usdc, txHash, err := e.merger.Redeem(ctx, conditionID, entry.UpTokenID, entry.DownTokenID)
+	if err != nil {
+		log.Printf("[executor] REDEEM failed for %s...: %v", conditionID[:8], err)
+		e.db.RecordChainTx(store.TxRedeem, conditionID, 0, txHash, store.TxFailed)
+		return 0
+	}
+	e.inv.RecordRedeem(conditionID, usdc)
+	if txHash != "" {
+		e.db.RecordChainTx(store.TxRedeem, conditionID, usdc, txHash, store.TxOK)
+	}
+	return usdc
+}
+
 // ── Helpers ───────────────────────────────────────────────────────────────
 
+// recordOrder stores a market (FOK) order in the history database. tokens
+// and usdc are what was filled, or what was asked for when it failed.
+func (e *Executor) recordOrder(conditionID string, orderSide types.OrderSide, price, tokens, usdc float64, res types.OrderResult) {
+	o := store.Order{
+		OrderID:     res.OrderID,
+		ConditionID: conditionID,
+		TokenID:     res.TokenID,
+		Outcome:     res.Side,
+		Side:        orderSide,
+		Type:        "FOK",
+		Price:       price,
+		Size:        tokens,
+		USDC:        usdc,
+		Status:      string(types.OrderSubmitted), // the tracker takes it from here
+		Error:       res.Error,
+	}
+	switch {
+	case res.Error != "":
+		o.Status = store.OrderRejected
+	case e.dryRun:
+		o.OrderID, o.Status = "", store.OrderDryRun
+	}
+	e.db.RecordOrder(o)
+}
+
 func getString(m map[string]interface{}, key string) string {
 	if v, ok := m[key]; ok {
 		if s, ok := v.(string); ok {
@@ -168,6 +724,21 @@ func getFloat(m map[string]interface{}, key string) float64 {
 	return 0
 }
 
+// short returns the first n characters of an ID or hash for logging.
+func short(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+func min64(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func max64(a, b float64) float64 {
 	if a > b {
 		return a
@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/book"
+)
+
+// books is a BookSource holding fixed books.
+type books map[string]*book.Book
+
+func (b books) Book(tokenID string) *book.Book { return b[tokenID] }
+
+func askBook(levels ...book.Level) *book.Book {
+	b := book.New()
+	b.Snapshot(nil, levels, time.Unix(0, 0))
+	return b
+}
+
+func TestHedgePrice(t *testing.T) {
+	src := books{"up": askBook(book.Level{Price: 0.50, Size: 10}, book.Level{Price: 0.52, Size: 20})}
+	tests := []struct {
+		name      string
+		tokenID   string
+		tokens    float64
+		wantPrice float64
+		wantOK    bool
+	}{
+		{name: "within the best level", tokenID: "up", tokens: 8, wantPrice: 0.50, wantOK: true},
+		{name: "reaching the second level", tokenID: "up", tokens: 15, wantPrice: 0.52, wantOK: true},
+		{name: "thin book: its last ask", tokenID: "up", tokens: 100, wantPrice: 0.52, wantOK: true},
+		{name: "no size: the best ask", tokenID: "up", wantPrice: 0.50, wantOK: true},
+		{name: "no book", tokenID: "down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Executor{books: src}
+			price, ok := e.hedgePrice(context.Background(), tt.tokenID, tt.tokens)
+			if price != tt.wantPrice || ok != tt.wantOK {
+				t.Errorf("hedgePrice = %.3f, %v; want %.3f, %v", price, ok, tt.wantPrice, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestHedgeLegStopsBeyondSlippage(t *testing.T) {
+	// Planned at 0.45; the book now asks 0.50, more than 3¢ above
+	e := &Executor{books: books{"up": askBook(book.Level{Price: 0.50, Size: 100})}}
+	res := e.hedgeLeg(context.Background(), testCID, "up", "down", "UP", 4.5, 0.45, 10)
+	if res.Success || !strings.Contains(res.Error, "above max") {
+		t.Errorf("hedge = %+v, want it given up above the slippage bound", res)
+	}
+}
@@ -0,0 +1,336 @@
+package executor
+
+import (
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/metrics"
+	"github.com/gipsh/polymarket-bot-go/internal/store"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+// Market (FOK) order lifecycle.
+//
+// Inventory is booked from the POST /order response as soon as an order
+// matches (or from a price-hint estimate when the response leaves the
+// amounts out). The user WebSocket then reports each trade of the order as
+// it settles on-chain:
+//
+//	submitted → matched → mined → confirmed
+//	                 ↘        ↘
+//	                   failed
+//
+// Every live order is followed by the ID PlaceMarketOrder returned. When its
+// trades add up to a different size or price than was booked, inventory is
+// corrected by the difference, so it ends at the actual fill (nothing, if
+// every trade failed). Orders not confirmed or failed within
+// ORDER_CONFIRM_TIMEOUT_SEC are flagged.
+
+const (
+	// earlyKeep is how long fills of an order not tracked yet are kept:
+	// the WebSocket can report a trade before the POST returns.
+	earlyKeep = time.Minute
+
+	// settledKeep is how long a confirmed or failed order is remembered
+	// (repeated events for it are absorbed meanwhile).
+	settledKeep = 10 * time.Minute
+
+	// openKeep is how long an order that never settles is followed.
+	openKeep = 6 * time.Hour
+
+	// fillTolerance is the smallest fill difference worth a correction.
+	fillTolerance = 0.0001
+)
+
+// trackedOrder is a market order followed through its lifecycle.
+type trackedOrder struct {
+	id          string
+	conditionID string
+	upTokenID   string
+	downTokenID string
+	side        string          // UP / DOWN
+	orderSide   types.OrderSide // BUY / SELL
+
+	// What inventory holds for this order (tokens bought or sold, USDC
+	// spent or received)
+	bookedTokens float64
+	bookedUSDC   float64
+
+	trades    map[string]types.FillEvent // latest event per trade ID
+	matched   bool                       // order event: size matched
+	cancelled bool                       // order event: cancelled with nothing matched
+
+	status    types.OrderStatus
+	submitted time.Time
+	settled   time.Time // confirmed or failed (zero while open)
+	flagged   bool      // reported as not confirming in time
+}
+
+// earlyFills holds fills of an order ID not tracked yet.
+type earlyFills struct {
+	received time.Time
+	fills    []types.FillEvent
+}
+
+// orderTracker holds the market orders being followed.
+type orderTracker struct {
+	mu     sync.Mutex
+	orders map[string]*trackedOrder
+	early  map[string]*earlyFills
+}
+
+func newOrderTracker() *orderTracker {
+	return &orderTracker{
+		orders: make(map[string]*trackedOrder),
+		early:  make(map[string]*earlyFills),
+	}
+}
+
+// trackOrder starts following a market order that matched and was booked
+// as tokens / usdc, replaying fills that arrived before the POST response.
+func (e *Executor) trackOrder(
+	orderID, conditionID, upTokenID, downTokenID, side string,
+	orderSide types.OrderSide, tokens, usdc float64,
+) {
+	if orderID == "" {
+		log.Printf("[executor] ⚠ %s %s order for %s... returned no order ID — fill not tracked",
+			orderSide, side, conditionID[:8])
+		return
+	}
+	t := e.orders
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	o := &trackedOrder{
+		id:           orderID,
+		conditionID:  conditionID,
+		upTokenID:    upTokenID,
+		downTokenID:  downTokenID,
+		side:         side,
+		orderSide:    orderSide,
+		bookedTokens: tokens,
+		bookedUSDC:   usdc,
+		trades:       make(map[string]types.FillEvent),
+		status:       types.OrderSubmitted,
+		submitted:    time.Now(),
+	}
+	t.orders[orderID] = o
+	if early, ok := t.early[orderID]; ok {
+		delete(t.early, orderID)
+		for _, f := range early.fills {
+			o.addTrade(f)
+		}
+		e.updateOrder(o)
+	}
+}
+
+// HandleFill is called by the user WebSocket for fills that are not the
+// order manager's: the trades of our market orders.
+func (e *Executor) HandleFill(fill types.FillEvent) {
+	log.Printf("[executor] WS fill | order=%s... %s %.4f @ %.4f outcome=%s status=%s tx=%s...",
+		short(fill.OrderID, 16), fill.Side, fill.Size, fill.Price, fill.Outcome, fill.Status, short(fill.TxHash, 16))
+	if fill.OrderID == "" {
+		return
+	}
+	t := e.orders
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	o, ok := t.orders[fill.OrderID]
+	if !ok {
+		early, ok := t.early[fill.OrderID]
+		if !ok {
+			early = &earlyFills{received: time.Now()}
+			t.early[fill.OrderID] = early
+		}
+		early.fills = append(early.fills, fill)
+		return
+	}
+	o.addTrade(fill)
+	e.updateOrder(o)
+}
+
+// HandleOrder is called by the user WebSocket for order events. They only
+// matter for a market order before its trades arrive: an update shows it
+// matched, a cancellation with nothing matched shows it never filled.
+func (e *Executor) HandleOrder(ev types.OrderEvent) {
+	t := e.orders
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	o, ok := t.orders[ev.OrderID]
+	if !ok {
+		return
+	}
+	switch strings.ToUpper(ev.Type) {
+	case "UPDATE":
+		o.matched = o.matched || ev.SizeMatched > 0
+	case "CANCELLATION":
+		if ev.SizeMatched > 0 {
+			o.matched = true
+		} else if len(o.trades) == 0 {
+			o.cancelled = true
+		}
+	default:
+		return
+	}
+	e.updateOrder(o)
+}
+
+// SweepOrders flags market orders not settled within
+// ORDER_CONFIRM_TIMEOUT_SEC and forgets the ones no longer followed.
+// Called from the main loop.
+func (e *Executor) SweepOrders() {
+	timeout := time.Duration(config.OrderConfirmTimeoutSec) * time.Second
+	now := time.Now()
+	t := e.orders
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, o := range t.orders {
+		age := now.Sub(o.submitted)
+		switch {
+		case !o.settled.IsZero():
+			if now.Sub(o.settled) >= settledKeep {
+				delete(t.orders, id)
+			}
+		case age >= openKeep:
+			log.Printf("[executor] ⚠ order %s... still %s after %s — no longer tracked",
+				short(id, 16), o.status, age.Round(time.Minute))
+			delete(t.orders, id)
+		case !o.flagged && timeout > 0 && age >= timeout:
+			o.flagged = true
+			log.Printf("[executor] ⚠ %s %s order %s... for %s... not confirmed after %s (status %s, booked %.4f tokens / $%.4f)",
+				o.orderSide, o.side, short(id, 16), o.conditionID[:8], age.Round(time.Second),
+				o.status, o.bookedTokens, o.bookedUSDC)
+			metrics.OrderLifecycle(store.OrderUnconfirmed)
+			e.db.OrderStatus(id, store.OrderUnconfirmed)
+		}
+	}
+	for id, early := range t.early {
+		if now.Sub(early.received) >= earlyKeep {
+			delete(t.early, id)
+		}
+	}
+}
+
+// updateOrder corrects inventory to the order's fill so far and records a
+// status change. Caller holds e.orders.mu.
+func (e *Executor) updateOrder(o *trackedOrder) {
+	if len(o.trades) > 0 || o.cancelled {
+		tokens, usdc := o.filled()
+		dTokens, dUSDC := tokens-o.bookedTokens, usdc-o.bookedUSDC
+		if math.Abs(dTokens) > fillTolerance || math.Abs(dUSDC) > fillTolerance {
+			log.Printf("[executor] %s %s order %s... filled %.4f tokens / $%.4f, booked %.4f / $%.4f — correcting inventory",
+				o.orderSide, o.side, short(o.id, 16), tokens, usdc, o.bookedTokens, o.bookedUSDC)
+			e.inv.RecordCorrection(o.conditionID, o.upTokenID, o.downTokenID, o.side, o.orderSide, dTokens, dUSDC)
+			e.db.CorrectOrder(o.id, tokens, usdc)
+			metrics.FillCorrection()
+			o.bookedTokens, o.bookedUSDC = tokens, usdc
+		}
+	}
+
+	status := o.derive()
+	if status == o.status {
+		return
+	}
+	log.Printf("[executor] order %s... %s → %s", short(o.id, 16), o.status, status)
+	o.status = status
+	metrics.OrderLifecycle(string(status))
+	e.db.OrderStatus(o.id, string(status))
+	switch status {
+	case types.OrderConfirmed, types.OrderFailed:
+		o.settled = time.Now()
+		if o.flagged {
+			log.Printf("[executor] flagged order %s... finally %s after %s",
+				short(o.id, 16), status, o.settled.Sub(o.submitted).Round(time.Second))
+		}
+	default:
+		// A trade reported after the others settled reopens the order
+		o.settled = time.Time{}
+	}
+}
+
+// ── Helpers ───────────────────────────────────────────────────────────────
+
+// addTrade stores the latest event of a trade. A confirmed or failed trade
+// is final; other statuses can go back and forth (MINED → RETRYING).
+func (o *trackedOrder) addTrade(f types.FillEvent) {
+	if prev, ok := o.trades[f.TradeID]; ok {
+		if s := tradeStatus(prev.Status); s == types.OrderConfirmed || s == types.OrderFailed {
+			return
+		}
+	}
+	o.trades[f.TradeID] = f
+}
+
+// filled returns the tokens and USDC of the trades that have not failed.
+func (o *trackedOrder) filled() (tokens, usdc float64) {
+	for _, f := range o.trades {
+		if tradeStatus(f.Status) == types.OrderFailed {
+			continue
+		}
+		tokens += f.Size
+		usdc += f.Size * f.Price
+	}
+	return tokens, usdc
+}
+
+// derive returns the order's status: that of its least advanced trade
+// still alive, or failed once every trade failed.
+func (o *trackedOrder) derive() types.OrderStatus {
+	if len(o.trades) == 0 {
+		switch {
+		case o.cancelled:
+			return types.OrderFailed
+		case o.matched && o.status == types.OrderSubmitted:
+			return types.OrderMatched
+		}
+		return o.status
+	}
+	status, alive := types.OrderConfirmed, false
+	for _, f := range o.trades {
+		s := tradeStatus(f.Status)
+		if s == types.OrderFailed {
+			continue
+		}
+		alive = true
+		if progress(s) < progress(status) {
+			status = s
+		}
+	}
+	if !alive {
+		return types.OrderFailed
+	}
+	return status
+}
+
+// tradeStatus maps a CLOB trade status to the order lifecycle. RETRYING (the
+// settlement tx failed and is being resent) counts as matched.
+func tradeStatus(s string) types.OrderStatus {
+	switch strings.ToUpper(s) {
+	case "MINED":
+		return types.OrderMined
+	case "CONFIRMED":
+		return types.OrderConfirmed
+	case "FAILED":
+		return types.OrderFailed
+	}
+	return types.OrderMatched
+}
+
+func progress(s types.OrderStatus) int {
+	switch s {
+	case types.OrderMatched:
+		return 1
+	case types.OrderMined:
+		return 2
+	case types.OrderConfirmed:
+		return 3
+	}
+	return 0
+}
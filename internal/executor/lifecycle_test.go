@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+const (
+	testCID   = "0xcondition0000000000000000000000000000000000000000000000000000000"
+	testOrder = "0xorder"
+)
+
+func TestOrderLifecycle(t *testing.T) {
+	// The POST response booked 10 UP tokens for $5
+	track := func(e *Executor) {
+		e.inv.RecordBuy(testCID, "up", "down", "UP", 10, 5)
+		e.trackOrder(testOrder, testCID, "up", "down", "UP", types.SideBuy, 10, 5)
+	}
+	fill := func(trade string, size, price float64, status string) func(*Executor) {
+		return func(e *Executor) {
+			e.HandleFill(types.FillEvent{TradeID: trade, OrderID: testOrder, Side: "BUY", Size: size, Price: price, Status: status})
+		}
+	}
+	order := func(kind string, matched float64) func(*Executor) {
+		return func(e *Executor) {
+			e.HandleOrder(types.OrderEvent{OrderID: testOrder, Type: kind, SizeMatched: matched})
+		}
+	}
+
+	tests := []struct {
+		name       string
+		steps      []func(*Executor)
+		wantStatus types.OrderStatus
+		wantTokens float64 // UP balance
+		wantCost   float64 // UP cost
+	}{
+		{
+			name:       "one trade settles as booked",
+			steps:      []func(*Executor){track, fill("t1", 10, 0.5, "MATCHED"), fill("t1", 10, 0.5, "MINED"), fill("t1", 10, 0.5, "CONFIRMED")},
+			wantStatus: types.OrderConfirmed, wantTokens: 10, wantCost: 5,
+		},
+		{
+			name:       "early fill before the POST returns",
+			steps:      []func(*Executor){fill("t1", 10, 0.48, "CONFIRMED"), track},
+			wantStatus: types.OrderConfirmed, wantTokens: 10, wantCost: 4.8,
+		},
+		{
+			name:       "multi-trade order waits for its slowest trade",
+			steps:      []func(*Executor){track, fill("t1", 6, 0.5, "CONFIRMED"), fill("t2", 4, 0.52, "MINED")},
+			wantStatus: types.OrderMined, wantTokens: 10, wantCost: 5.08,
+		},
+		{
+			name:       "multi-trade order confirmed",
+			steps:      []func(*Executor){track, fill("t1", 6, 0.5, "CONFIRMED"), fill("t2", 4, 0.52, "MINED"), fill("t2", 4, 0.52, "CONFIRMED")},
+			wantStatus: types.OrderConfirmed, wantTokens: 10, wantCost: 5.08,
+		},
+		{
+			name:       "one failed trade is taken back out",
+			steps:      []func(*Executor){track, fill("t1", 6, 0.5, "CONFIRMED"), fill("t2", 4, 0.5, "FAILED")},
+			wantStatus: types.OrderConfirmed, wantTokens: 6, wantCost: 3,
+		},
+		{
+			name:       "every trade failed",
+			steps:      []func(*Executor){track, fill("t1", 10, 0.5, "MATCHED"), fill("t1", 10, 0.5, "FAILED")},
+			wantStatus: types.OrderFailed,
+		},
+		{
+			name:       "a confirmed trade is final",
+			steps:      []func(*Executor){track, fill("t1", 10, 0.5, "CONFIRMED"), fill("t1", 10, 0.5, "FAILED")},
+			wantStatus: types.OrderConfirmed, wantTokens: 10, wantCost: 5,
+		},
+		{
+			name:       "retrying counts as matched",
+			steps:      []func(*Executor){track, fill("t1", 10, 0.5, "MINED"), fill("t1", 10, 0.5, "RETRYING")},
+			wantStatus: types.OrderMatched, wantTokens: 10, wantCost: 5,
+		},
+		{
+			name:       "cancelled with nothing matched",
+			steps:      []func(*Executor){track, order("CANCELLATION", 0)},
+			wantStatus: types.OrderFailed,
+		},
+		{
+			name:       "update before any trade",
+			steps:      []func(*Executor){track, order("UPDATE", 10)},
+			wantStatus: types.OrderMatched, wantTokens: 10, wantCost: 5,
+		},
+		{
+			name:       "cancellation after a match keeps the booking",
+			steps:      []func(*Executor){track, order("CANCELLATION", 4)},
+			wantStatus: types.OrderMatched, wantTokens: 10, wantCost: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Executor{inv: inventory.NewMemory(), orders: newOrderTracker()}
+			for _, step := range tt.steps {
+				step(e)
+			}
+
+			o, ok := e.orders.orders[testOrder]
+			if !ok {
+				t.Fatal("order not tracked")
+			}
+			if o.status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", o.status, tt.wantStatus)
+			}
+			entry, _ := e.inv.Get(testCID)
+			if math.Abs(entry.UpBalance-tt.wantTokens) > 1e-9 || math.Abs(entry.UpCost-tt.wantCost) > 1e-9 {
+				t.Errorf("inventory UP %.4f / $%.4f, want %.4f / $%.4f",
+					entry.UpBalance, entry.UpCost, tt.wantTokens, tt.wantCost)
+			}
+			if tokens, usdc := o.filled(); o.status != types.OrderFailed && len(o.trades) > 0 &&
+				(math.Abs(tokens-tt.wantTokens) > 1e-9 || math.Abs(usdc-tt.wantCost) > 1e-9) {
+				t.Errorf("filled() = %.4f / $%.4f, want %.4f / $%.4f", tokens, usdc, tt.wantTokens, tt.wantCost)
+			}
+			if settled := tt.wantStatus == types.OrderConfirmed || tt.wantStatus == types.OrderFailed; settled == o.settled.IsZero() {
+				t.Errorf("settled at %v for status %s", o.settled, o.status)
+			}
+		})
+	}
+}
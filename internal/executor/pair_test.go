@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"context"
+	"encoding/hex"
+	"math"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clob"
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/fakeclob"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+)
+
+// fakeCLOB starts a fake CLOB with one market and returns a client whose
+// L2 creds were derived from it.
+func fakeCLOB(t *testing.T) (*fakeclob.Server, *clob.Client) {
+	t.Helper()
+	srv := fakeclob.New()
+	srv.AddMarket(testCID, "1001", "1002")
+	hs := httptest.NewServer(srv.Handler())
+	t.Cleanup(hs.Close)
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := config.PrivateKey
+	config.PrivateKey = hex.EncodeToString(crypto.FromECDSA(key))
+	defer func() { config.PrivateKey = prev }()
+	c, err := clob.NewClientWithHost(hs.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := c.CreateOrDeriveAPICreds(context.Background())
+	if err != nil {
+		t.Fatalf("derive creds: %v", err)
+	}
+	c.SetAPICreds(creds)
+	return srv, c
+}
+
+func TestBuyArbPairWalksTheBook(t *testing.T) {
+	srv, c := fakeCLOB(t)
+	// 20 pairs: UP 10 @ 0.45 + 10 @ 0.47, DOWN 10 @ 0.48 + 10 @ 0.50
+	srv.SetBook("1001", nil, []fakeclob.Level{{Price: "0.45", Size: "10"}, {Price: "0.47", Size: "100"}})
+	srv.SetBook("1002", nil, []fakeclob.Level{{Price: "0.48", Size: "10"}, {Price: "0.50", Size: "100"}})
+
+	e := &Executor{inv: inventory.NewMemory(), client: c, orders: newOrderTracker()}
+	res := e.BuyArbPair(context.Background(), testCID, "1001", "1002", 20, 9.2, 9.8, 0.47, 0.50)
+	if !res.Up.Success || !res.Down.Success {
+		t.Fatalf("pair not filled: UP=%q DOWN=%q", res.Up.Error, res.Down.Error)
+	}
+	if math.Abs(res.Pairs-20) > 1e-6 {
+		t.Errorf("pairs = %.6f, want 20", res.Pairs)
+	}
+	entry, _ := e.inv.Get(testCID)
+	if math.Abs(entry.UpBalance-20) > 1e-6 || math.Abs(entry.DownBalance-20) > 1e-6 {
+		t.Errorf("inventory UP %.6f / DOWN %.6f, want 20 / 20", entry.UpBalance, entry.DownBalance)
+	}
+	if math.Abs(entry.UpCost-9.2) > 1e-6 || math.Abs(entry.DownCost-9.8) > 1e-6 {
+		t.Errorf("cost UP $%.6f / DOWN $%.6f, want $9.2 / $9.8", entry.UpCost, entry.DownCost)
+	}
+	if n := len(srv.Trades()); n != 2 {
+		t.Errorf("%d trades, want one per leg", n)
+	}
+}
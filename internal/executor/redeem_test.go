@@ -0,0 +1,52 @@
+package executor
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/merger"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+// fixedPrices is a PriceSource quoting every market the same.
+type fixedPrices types.Prices
+
+func (p *fixedPrices) GetPrices(context.Context, string, string) (*types.Prices, error) {
+	q := types.Prices(*p)
+	return &q, nil
+}
+
+func TestRedeemResolvedDryRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		prices   *fixedPrices // nil = no price source
+		wantUSDC float64
+	}{
+		{name: "resolved UP pays the UP tokens", prices: &fixedPrices{Up: 0.999, Down: 0.001, State: types.StateResolved}, wantUSDC: 10},
+		{name: "resolved DOWN pays the DOWN tokens", prices: &fixedPrices{Up: 0.001, Down: 0.999, State: types.StateResolved}, wantUSDC: 4},
+		{name: "not resolved yet", prices: &fixedPrices{Up: 0.6, Down: 0.41, State: types.StateGrey}},
+		{name: "no resolution source"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := inventory.NewMemory()
+			inv.RecordBuy(testCID, "up", "down", "UP", 10, 5)
+			inv.RecordBuy(testCID, "up", "down", "DOWN", 4, 2)
+			// No MERGE_PRIVATE_KEY: the merger is never ready in a dry run
+			e := &Executor{inv: inv, merger: &merger.Merger{}, orders: newOrderTracker(), dryRun: true}
+			if tt.prices != nil {
+				e.SetPrices(tt.prices)
+			}
+
+			if got := e.RedeemResolved(context.Background()); math.Abs(got-tt.wantUSDC) > 1e-9 {
+				t.Errorf("redeemed $%.2f, want $%.2f", got, tt.wantUSDC)
+			}
+			entry, _ := inv.Get(testCID)
+			if redeemed := tt.wantUSDC > 0; entry.Redeemed != redeemed {
+				t.Errorf("entry redeemed = %v, want %v", entry.Redeemed, redeemed)
+			}
+		})
+	}
+}
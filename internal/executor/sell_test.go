@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/gipsh/polymarket-bot-go/internal/fakeclob"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+)
+
+func TestSellMarket(t *testing.T) {
+	tests := []struct {
+		name     string
+		tokens   float64
+		limit    float64
+		dryRun   bool
+		wantErr  bool
+		wantUSDC float64
+	}{
+		// 10 @ 0.48 then 5 @ 0.46
+		{name: "walks the bids down to the limit", tokens: 15, limit: 0.46, wantUSDC: 4.8 + 2.3},
+		{name: "killed below the limit", tokens: 15, limit: 0.47, wantErr: true},
+		{name: "dry run books the limit price", tokens: 15, limit: 0.46, dryRun: true, wantUSDC: 15 * 0.46},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := fakeCLOB(t)
+			srv.SetBook("1001", []fakeclob.Level{{Price: "0.48", Size: "10"}, {Price: "0.46", Size: "100"}}, nil)
+			inv := inventory.NewMemory()
+			inv.RecordBuy(testCID, "1001", "1002", "UP", 20, 8)
+			e := &Executor{inv: inv, client: c, orders: newOrderTracker(), dryRun: tt.dryRun}
+
+			up := "1001"
+			if tt.dryRun {
+				up = "100100000000000000" // dry runs log a token ID prefix
+			}
+			res := e.SellMarket(context.Background(), testCID, up, "1002", "UP", tt.tokens, tt.limit)
+			entry, _ := inv.Get(testCID)
+			if tt.wantErr {
+				if res.Success || res.Error == "" {
+					t.Fatalf("result = %+v, want a killed order", res)
+				}
+				if entry.UpBalance != 20 || entry.TotalSold != 0 {
+					t.Errorf("inventory UP %.4f sold $%.4f after a killed sell, want 20 / $0", entry.UpBalance, entry.TotalSold)
+				}
+				return
+			}
+			if !res.Success || res.TokensSold != tt.tokens || math.Abs(res.USDCReceived-tt.wantUSDC) > 1e-6 {
+				t.Fatalf("sold %.4f for $%.4f (%s), want %.4f for $%.4f", res.TokensSold, res.USDCReceived, res.Error, tt.tokens, tt.wantUSDC)
+			}
+			// 15 of 20 tokens sold: they take 3/4 of the $8 cost basis with them
+			if math.Abs(entry.UpBalance-5) > 1e-6 || math.Abs(entry.UpCost-2) > 1e-6 || math.Abs(entry.TotalSold-tt.wantUSDC) > 1e-6 {
+				t.Errorf("inventory UP %.4f cost $%.4f sold $%.4f, want 5 / $2 / $%.4f",
+					entry.UpBalance, entry.UpCost, entry.TotalSold, tt.wantUSDC)
+			}
+			if n := len(srv.Trades()); (n == 1) == tt.dryRun {
+				t.Errorf("%d trades on the CLOB (dry run %v)", n, tt.dryRun)
+			}
+		})
+	}
+}
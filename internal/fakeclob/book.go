@@ -0,0 +1,200 @@
+package fakeclob
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+)
+
+// priceEpsilon absorbs float rounding when comparing against limit prices.
+const priceEpsilon = 1e-9
+
+// Level is one price level (decimal strings, as in the CLOB API).
+type Level struct {
+	Price string `json:"price"`
+	Size  string `json:"size"`
+}
+
+type level struct {
+	price float64
+	size  float64
+}
+
+// book holds resting liquidity for one token.
+// bids are sorted best (highest) first, asks best (lowest) first.
+type book struct {
+	bids []level
+	asks []level
+}
+
+func newBook(bids, asks []Level) *book {
+	b := &book{bids: parseLevels(bids), asks: parseLevels(asks)}
+	sort.Slice(b.bids, func(i, j int) bool { return b.bids[i].price > b.bids[j].price })
+	sort.Slice(b.asks, func(i, j int) bool { return b.asks[i].price < b.asks[j].price })
+	return b
+}
+
+func parseLevels(in []Level) []level {
+	out := make([]level, 0, len(in))
+	for _, l := range in {
+		p, err1 := strconv.ParseFloat(l.Price, 64)
+		sz, err2 := strconv.ParseFloat(l.Size, 64)
+		if err1 == nil && err2 == nil && p > 0 && sz > 0 {
+			out = append(out, level{p, sz})
+		}
+	}
+	return out
+}
+
+// levels returns the book in API form (bids best first, asks best first).
+func (b *book) levels() (bids, asks []Level) {
+	bids = make([]Level, 0, len(b.bids))
+	for _, l := range b.bids {
+		bids = append(bids, Level{formatAmount(l.price), formatAmount(l.size)})
+	}
+	asks = make([]Level, 0, len(b.asks))
+	for _, l := range b.asks {
+		asks = append(asks, Level{formatAmount(l.price), formatAmount(l.size)})
+	}
+	return bids, asks
+}
+
+func (b *book) bestBid() (float64, bool) {
+	if len(b.bids) == 0 {
+		return 0, false
+	}
+	return b.bids[0].price, true
+}
+
+func (b *book) bestAsk() (float64, bool) {
+	if len(b.asks) == 0 {
+		return 0, false
+	}
+	return b.asks[0].price, true
+}
+
+type fillResult struct {
+	tokens float64
+	usdc   float64
+}
+
+// buyFOK spends exactly usdc against asks priced at or below limit.
+// Either the whole amount fills (and the book is consumed) or nothing does.
+func (b *book) buyFOK(usdc, limit float64) (fillResult, bool) {
+	var res fillResult
+	remaining := usdc
+	consumed := make([]float64, len(b.asks))
+	for i, a := range b.asks {
+		if remaining <= priceEpsilon || a.price > limit+priceEpsilon {
+			break
+		}
+		take := a.size
+		if cost := take * a.price; cost > remaining {
+			take = remaining / a.price
+		}
+		consumed[i] = take
+		res.tokens += take
+		res.usdc += take * a.price
+		remaining -= take * a.price
+	}
+	if remaining > 1e-6 {
+		return fillResult{}, false
+	}
+	b.asks = consume(b.asks, consumed)
+	return res, true
+}
+
+// sellFOK sells exactly tokens against bids priced at or above limit.
+func (b *book) sellFOK(tokens, limit float64) (fillResult, bool) {
+	var res fillResult
+	remaining := tokens
+	consumed := make([]float64, len(b.bids))
+	for i, bid := range b.bids {
+		if remaining <= priceEpsilon || bid.price < limit-priceEpsilon {
+			break
+		}
+		take := bid.size
+		if take > remaining {
+			take = remaining
+		}
+		consumed[i] = take
+		res.tokens += take
+		res.usdc += take * bid.price
+		remaining -= take
+	}
+	if remaining > 1e-6 {
+		return fillResult{}, false
+	}
+	b.bids = consume(b.bids, consumed)
+	return res, true
+}
+
+// buyLimit buys up to tokens against asks priced at or below limit and
+// consumes what it took (a limit order's marketable part).
+func (b *book) buyLimit(tokens, limit float64) fillResult {
+	var res fillResult
+	b.asks, res = takeUpTo(b.asks, tokens, func(p float64) bool { return p <= limit+priceEpsilon })
+	return res
+}
+
+// sellLimit sells up to tokens against bids priced at or above limit.
+func (b *book) sellLimit(tokens, limit float64) fillResult {
+	var res fillResult
+	b.bids, res = takeUpTo(b.bids, tokens, func(p float64) bool { return p >= limit-priceEpsilon })
+	return res
+}
+
+// takeUpTo fills up to tokens from levels, best first, while within(price)
+// holds, and returns the levels left.
+func takeUpTo(levels []level, tokens float64, within func(float64) bool) ([]level, fillResult) {
+	var res fillResult
+	consumed := make([]float64, len(levels))
+	for i, l := range levels {
+		remaining := tokens - res.tokens
+		if remaining <= priceEpsilon || !within(l.price) {
+			break
+		}
+		take := min(l.size, remaining)
+		consumed[i] = take
+		res.tokens += take
+		res.usdc += take * l.price
+	}
+	return consume(levels, consumed), res
+}
+
+func consume(levels []level, taken []float64) []level {
+	out := levels[:0]
+	for i, l := range levels {
+		l.size -= taken[i]
+		if l.size > 1e-9 {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// ── Number helpers ────────────────────────────────────────────────────────
+
+func parseBig(name, s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s %q", name, s)
+	}
+	return n, nil
+}
+
+// unitsToFloat converts 6-decimal fixed-point units to a float.
+func unitsToFloat(n *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(n), big.NewFloat(1e6)).Float64()
+	return f
+}
+
+func formatAmount(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func base64URL(b []byte) string {
+	return base64.URLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,890 @@
+// Package fakeclob is a local stand-in for the Polymarket CLOB REST API,
+// for integration tests and paper trading.
+//
+// It implements the endpoints clob.Client and pricer.Pricer use:
+//
+//	GET  /auth/api-key   L1 auth (personal_sign of timestamp) → derives L2 creds
+//	POST /order          L2 auth + EIP-712 order signature → FOK match against
+//	                     book; GTC/GTD match what crosses and rest the rest
+//	DELETE /order, /orders, /cancel-all, /cancel-market-orders
+//	                     L2 auth → cancel the caller's resting orders
+//	GET  /data/orders    L2 auth → the caller's resting orders (?market=, ?asset_id=)
+//	GET  /data/order/{id} L2 auth → one resting order
+//	GET  /data/trades    L2 auth → trades recorded for the caller (paged, ?after=)
+//	GET  /price          best ask (side=BUY) / best bid (side=SELL)
+//	GET  /midpoint       (best bid + best ask) / 2
+//	GET  /book           full order book
+//
+// Books are seeded in-process with SetBook or over HTTP with POST /fake/book.
+// Resting orders never enter the books; Fill matches one against an outside
+// taker and records the maker trade.
+// Signatures are verified against the same EIP-712 domain as
+// clob.BuildAndSignOrder.
+package fakeclob
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clob"
+)
+
+// maxClockSkew bounds how far POLY_TIMESTAMP may drift from server time.
+const maxClockSkew = 5 * time.Minute
+
+// tradesPageSize is how many trades one GET /data/trades page holds.
+const tradesPageSize = 100
+
+// Server is an in-memory fake CLOB.
+type Server struct {
+	mu      sync.Mutex
+	keys    map[string]*apiKey       // API key → creds
+	derived map[string]*apiKey       // L1 address → creds (derive is idempotent)
+	books   map[string]*book         // tokenID → order book
+	tokens  map[string]tokenInfo     // tokenID → market + outcome
+	orders  map[string]*restingOrder // orderID → live GTC/GTD order
+	trades  []clob.Trade
+	owners  []string // owner address per trade (parallel to trades)
+	seq     int
+
+	httpSrv *http.Server
+}
+
+type apiKey struct {
+	key        string
+	secret     string
+	passphrase string
+	address    common.Address // POLY_ADDRESS used for L1 (Safe or EOA)
+	signer     common.Address // EOA that signed the L1 request
+}
+
+type tokenInfo struct {
+	market  string
+	outcome string
+}
+
+// restingOrder is the unmatched part of a GTC/GTD order.
+type restingOrder struct {
+	id         string
+	owner      string // signer address, lower case (as s.owners)
+	apiKey     string
+	maker      common.Address
+	tokenID    string
+	side       string // BUY / SELL
+	orderType  string // GTC / GTD
+	price      float64
+	size       float64 // original size in tokens
+	matched    float64
+	expiration int64 // unix seconds (0 = none)
+	createdAt  int64
+}
+
+// New creates an empty fake CLOB.
+func New() *Server {
+	return &Server{
+		keys:    make(map[string]*apiKey),
+		derived: make(map[string]*apiKey),
+		books:   make(map[string]*book),
+		tokens:  make(map[string]tokenInfo),
+		orders:  make(map[string]*restingOrder),
+	}
+}
+
+// ── Setup ─────────────────────────────────────────────────────────────────
+
+// AddMarket registers the UP/DOWN token IDs of a condition so recorded
+// trades carry the market and outcome, as /data/trades does.
+func (s *Server) AddMarket(conditionID, upTokenID, downTokenID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[upTokenID] = tokenInfo{conditionID, "Up"}
+	s.tokens[downTokenID] = tokenInfo{conditionID, "Down"}
+}
+
+// SetTokenInfo sets the market (condition ID) and outcome label of one token.
+func (s *Server) SetTokenInfo(tokenID, conditionID, outcome string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[tokenID] = tokenInfo{conditionID, outcome}
+}
+
+// SetBook replaces the resting liquidity for a token.
+func (s *Server) SetBook(tokenID string, bids, asks []Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.books[tokenID] = newBook(bids, asks)
+}
+
+// Trades returns a copy of every trade matched so far.
+func (s *Server) Trades() []clob.Trade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]clob.Trade(nil), s.trades...)
+}
+
+// ── Serving ───────────────────────────────────────────────────────────────
+
+// Handler returns the HTTP handler implementing the fake API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/api-key", s.handleAPIKey)
+	mux.HandleFunc("/order", s.handleOrder)
+	mux.HandleFunc("/orders", s.handleCancel)
+	mux.HandleFunc("/cancel-all", s.handleCancel)
+	mux.HandleFunc("/cancel-market-orders", s.handleCancel)
+	mux.HandleFunc("/data/orders", s.handleOpenOrders)
+	mux.HandleFunc("/data/order/", s.handleGetOrder)
+	mux.HandleFunc("/data/trades", s.handleTrades)
+	mux.HandleFunc("/price", s.handlePrice)
+	mux.HandleFunc("/midpoint", s.handleMidpoint)
+	mux.HandleFunc("/book", s.handleBook)
+	mux.HandleFunc("/fake/book", s.handleSetBook)
+	return mux
+}
+
+// Start listens on addr (e.g. "127.0.0.1:0") in the background and returns
+// the base URL to pass to clob.NewClientWithHost / pricer.NewPricerWithHost.
+func (s *Server) Start(addr string) (string, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	s.httpSrv = &http.Server{Handler: s.Handler()}
+	go func() {
+		if err := s.httpSrv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("[fakeclob] serve: %v", err)
+		}
+	}()
+	url := "http://" + ln.Addr().String()
+	log.Printf("[fakeclob] listening on %s", url)
+	return url, nil
+}
+
+// Close stops a server started with Start.
+func (s *Server) Close() error {
+	if s.httpSrv == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return s.httpSrv.Shutdown(ctx)
+}
+
+// ── Auth ──────────────────────────────────────────────────────────────────
+
+// handleAPIKey verifies L1 headers and returns (deterministically) the same
+// L2 credentials for the same address, like create-or-derive does.
+func (s *Server) handleAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeErr(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	addrHex := r.Header.Get("POLY_ADDRESS")
+	ts := r.Header.Get("POLY_TIMESTAMP")
+	if !common.IsHexAddress(addrHex) || ts == "" {
+		writeErr(w, http.StatusUnauthorized, "missing L1 headers")
+		return
+	}
+	if err := checkTimestamp(ts); err != nil {
+		writeErr(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	signer, err := clob.RecoverPersonalSigner(ts, r.Header.Get("POLY_SIGNATURE"))
+	if err != nil {
+		writeErr(w, http.StatusUnauthorized, "bad L1 signature: "+err.Error())
+		return
+	}
+	addr := common.HexToAddress(addrHex)
+
+	s.mu.Lock()
+	k, ok := s.derived[strings.ToLower(addr.Hex())]
+	if !ok {
+		k = &apiKey{
+			key:        randomHex(16),
+			secret:     randomSecret(),
+			passphrase: randomHex(16),
+			address:    addr,
+			signer:     signer,
+		}
+		s.derived[strings.ToLower(addr.Hex())] = k
+		s.keys[k.key] = k
+	}
+	s.mu.Unlock()
+
+	if k.signer != signer {
+		writeErr(w, http.StatusUnauthorized, "L1 signer does not own this address")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"apiKey":     k.key,
+		"secret":     k.secret,
+		"passphrase": k.passphrase,
+	})
+}
+
+// authL2 verifies the HMAC headers of r against body and returns the key.
+func (s *Server) authL2(r *http.Request, body []byte) (*apiKey, error) {
+	s.mu.Lock()
+	k, ok := s.keys[r.Header.Get("POLY_API_KEY")]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown api key")
+	}
+	if r.Header.Get("POLY_PASSPHRASE") != k.passphrase {
+		return nil, fmt.Errorf("bad passphrase")
+	}
+	if !strings.EqualFold(r.Header.Get("POLY_ADDRESS"), k.signer.Hex()) {
+		return nil, fmt.Errorf("POLY_ADDRESS does not match api key")
+	}
+	ts := r.Header.Get("POLY_TIMESTAMP")
+	if err := checkTimestamp(ts); err != nil {
+		return nil, err
+	}
+	want := clob.L2Signature(k.secret, ts, r.Method, r.URL.RequestURI(), string(body))
+	if r.Header.Get("POLY_SIGNATURE") != want {
+		return nil, fmt.Errorf("bad L2 signature")
+	}
+	return k, nil
+}
+
+// ── Orders ────────────────────────────────────────────────────────────────
+
+type orderJSON struct {
+	Salt          string `json:"salt"`
+	Maker         string `json:"maker"`
+	Signer        string `json:"signer"`
+	Taker         string `json:"taker"`
+	TokenID       string `json:"tokenId"`
+	MakerAmount   string `json:"makerAmount"`
+	TakerAmount   string `json:"takerAmount"`
+	Expiration    string `json:"expiration"`
+	Nonce         string `json:"nonce"`
+	FeeRateBps    string `json:"feeRateBps"`
+	Side          int    `json:"side"`
+	SignatureType int    `json:"signatureType"`
+	Signature     string `json:"signature"`
+}
+
+func (s *Server) handleOrder(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete {
+		s.handleCancel(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeErr(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	k, err := s.authL2(r, body)
+	if err != nil {
+		writeErr(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var req struct {
+		Order     orderJSON `json:"order"`
+		OrderType string    `json:"orderType"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid order payload")
+		return
+	}
+	if req.OrderType != "FOK" && req.OrderType != "GTC" && req.OrderType != "GTD" {
+		writeErr(w, http.StatusBadRequest, "unsupported orderType "+req.OrderType)
+		return
+	}
+
+	params, err := req.Order.params()
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := verifyOrder(params, req.Order.Signature, k); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid signature: "+err.Error())
+		return
+	}
+
+	makerAmt := unitsToFloat(params.MakerAmount)
+	takerAmt := unitsToFloat(params.TakerAmount)
+	if makerAmt <= 0 || takerAmt <= 0 {
+		writeErr(w, http.StatusBadRequest, "invalid amounts")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.books[req.Order.TokenID]
+	if b == nil {
+		b = newBook(nil, nil)
+		s.books[req.Order.TokenID] = b
+	}
+
+	if req.OrderType != "FOK" {
+		s.placeLimit(w, k, req.Order.TokenID, req.OrderType, params, b)
+		return
+	}
+
+	// BUY: maker gives USDC, takes tokens; SELL: maker gives tokens, takes USDC.
+	var fill fillResult
+	var ok bool
+	if params.Side == 0 {
+		fill, ok = b.buyFOK(makerAmt, makerAmt/takerAmt)
+	} else {
+		fill, ok = b.sellFOK(makerAmt, takerAmt/makerAmt)
+	}
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success":  false,
+			"errorMsg": "order couldn't be fully filled. FOK orders are fully filled or killed.",
+		})
+		return
+	}
+
+	s.seq++
+	orderID := fmt.Sprintf("0x%064x", s.seq)
+	side := sideName(params.Side)
+	s.recordTrade(strings.ToLower(k.signer.Hex()), req.Order.TokenID, side, fill, "TAKER", nil)
+
+	log.Printf("[fakeclob] %s %s... %.4f @ %.4f (order %s)",
+		side, shortID(req.Order.TokenID), fill.tokens, fill.usdc/fill.tokens, orderID[:10])
+	writeJSON(w, http.StatusOK, orderResponse(orderID, "matched", params.Side, fill))
+}
+
+// placeLimit matches the part of a GTC/GTD order that crosses the book and
+// rests the remainder. Caller holds s.mu.
+func (s *Server) placeLimit(w http.ResponseWriter, k *apiKey, tokenID, orderType string, p clob.OrderParams, b *book) {
+	exp := p.Expiration.Int64()
+	switch {
+	case orderType == "GTC" && exp != 0:
+		writeErr(w, http.StatusBadRequest, "GTC orders cannot have an expiration")
+		return
+	case orderType == "GTD" && exp <= time.Now().Unix():
+		writeErr(w, http.StatusBadRequest, "invalid expiration for GTD order")
+		return
+	}
+
+	// BUY: maker gives USDC for tokens; SELL: maker gives tokens for USDC.
+	makerAmt, takerAmt := unitsToFloat(p.MakerAmount), unitsToFloat(p.TakerAmount)
+	tokens, price := takerAmt, makerAmt/takerAmt
+	var fill fillResult
+	if p.Side == 0 {
+		fill = b.buyLimit(tokens, price)
+	} else {
+		tokens, price = makerAmt, takerAmt/makerAmt
+		fill = b.sellLimit(tokens, price)
+	}
+
+	s.seq++
+	orderID := fmt.Sprintf("0x%064x", s.seq)
+	side := sideName(p.Side)
+	if fill.tokens > 0 {
+		s.recordTrade(strings.ToLower(k.signer.Hex()), tokenID, side, fill, "TAKER", nil)
+	}
+	status := "matched"
+	if tokens-fill.tokens > 1e-6 {
+		status = "live"
+		s.orders[orderID] = &restingOrder{
+			id:         orderID,
+			owner:      strings.ToLower(k.signer.Hex()),
+			apiKey:     k.key,
+			maker:      p.Maker,
+			tokenID:    tokenID,
+			side:       side,
+			orderType:  orderType,
+			price:      price,
+			size:       tokens,
+			matched:    fill.tokens,
+			expiration: exp,
+			createdAt:  time.Now().Unix(),
+		}
+	}
+
+	log.Printf("[fakeclob] %s %s %s... %.4f @ %.4f → %s, %.4f matched (order %s)",
+		orderType, side, shortID(tokenID), tokens, price, status, fill.tokens, orderID[:10])
+	writeJSON(w, http.StatusOK, orderResponse(orderID, status, p.Side, fill))
+}
+
+// Fill matches tokens of a resting order against an outside taker, as if
+// someone crossed it, and records the maker trade. A fully matched order
+// stops resting.
+func (s *Server) Fill(orderID string, tokens float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireOrders()
+	o, ok := s.orders[orderID]
+	if !ok {
+		return fmt.Errorf("no resting order %s", orderID)
+	}
+	if left := o.size - o.matched; tokens <= 0 || tokens > left+1e-9 {
+		return fmt.Errorf("cannot fill %.4f of order %s (%.4f left)", tokens, orderID, left)
+	}
+	o.matched += tokens
+	if o.size-o.matched <= 1e-6 {
+		delete(s.orders, orderID)
+	}
+
+	// The trade's top-level fields are the taker's, the other side of ours
+	info := s.tokens[o.tokenID]
+	taker := "SELL"
+	if o.side == "SELL" {
+		taker = "BUY"
+	}
+	fill := fillResult{tokens: tokens, usdc: tokens * o.price}
+	s.recordTrade(o.owner, o.tokenID, taker, fill, "MAKER", []clob.MakerOrder{{
+		OrderID:       o.id,
+		Owner:         o.apiKey,
+		MakerAddress:  o.maker.Hex(),
+		MatchedAmount: formatAmount(tokens),
+		Price:         formatAmount(o.price),
+		FeeRateBps:    "0",
+		AssetID:       o.tokenID,
+		Outcome:       info.outcome,
+		Side:          o.side,
+	}})
+	log.Printf("[fakeclob] maker %s %s... %.4f @ %.4f (order %s)",
+		o.side, shortID(o.tokenID), tokens, o.price, orderID[:10])
+	return nil
+}
+
+// recordTrade appends a CONFIRMED trade of owner (signer address, lower
+// case). Caller holds s.mu.
+func (s *Server) recordTrade(owner, tokenID, side string, fill fillResult, traderSide string, makers []clob.MakerOrder) {
+	info := s.tokens[tokenID]
+	s.trades = append(s.trades, clob.Trade{
+		ID:          fmt.Sprintf("fake-trade-%d", len(s.trades)+1),
+		Market:      info.market,
+		Side:        side,
+		Outcome:     info.outcome,
+		Size:        formatAmount(fill.tokens),
+		Price:       formatAmount(fill.usdc / fill.tokens),
+		Status:      "CONFIRMED",
+		AssetID:     tokenID,
+		Timestamp:   strconv.FormatInt(time.Now().Unix(), 10),
+		TraderSide:  traderSide,
+		MakerOrders: makers,
+	})
+	s.owners = append(s.owners, owner)
+}
+
+// orderResponse is the POST /order reply: what the order gave and got so far.
+func orderResponse(orderID, status string, side uint8, fill fillResult) map[string]interface{} {
+	making, taking := fill.usdc, fill.tokens
+	if side == 1 {
+		making, taking = fill.tokens, fill.usdc
+	}
+	var txs []string
+	if fill.tokens > 0 {
+		txs = []string{"0x" + randomHex(32)}
+	}
+	return map[string]interface{}{
+		"success":            true,
+		"orderID":            orderID,
+		"status":             status,
+		"makingAmount":       formatAmount(making),
+		"takingAmount":       formatAmount(taking),
+		"transactionsHashes": txs,
+		"errorMsg":           "",
+	}
+}
+
+// handleCancel answers the cancel endpoints for the caller's resting orders:
+// one (/order), a list (/orders), all (/cancel-all) or one market's
+// (/cancel-market-orders, narrowed by market and/or asset_id).
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeErr(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	k, err := s.authL2(r, body)
+	if err != nil {
+		writeErr(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	owner := strings.ToLower(k.signer.Hex())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireOrders()
+
+	var ids []string
+	switch r.URL.Path {
+	case "/order":
+		var one struct {
+			OrderID string `json:"orderID"`
+		}
+		if json.Unmarshal(body, &one) == nil && one.OrderID != "" {
+			ids = []string{one.OrderID}
+		}
+	case "/orders":
+		_ = json.Unmarshal(body, &ids)
+	default:
+		var filter struct {
+			Market  string `json:"market"`
+			AssetID string `json:"asset_id"`
+		}
+		_ = json.Unmarshal(body, &filter)
+		for _, o := range s.ownOrders(owner, filter.Market, filter.AssetID) {
+			ids = append(ids, o.id)
+		}
+	}
+
+	resp := clob.CancelResponse{Canceled: []string{}, NotCanceled: map[string]string{}}
+	for _, id := range ids {
+		if o, ok := s.orders[id]; ok && o.owner == owner {
+			delete(s.orders, id)
+			resp.Canceled = append(resp.Canceled, id)
+		} else {
+			resp.NotCanceled[id] = "order can't be found - already canceled or matched"
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleOpenOrders(w http.ResponseWriter, r *http.Request) {
+	k, err := s.authL2(r, nil)
+	if err != nil {
+		writeErr(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	s.expireOrders()
+	out := []clob.OpenOrder{}
+	for _, o := range s.ownOrders(strings.ToLower(k.signer.Hex()), q.Get("market"), q.Get("asset_id")) {
+		out = append(out, s.openOrder(o))
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":        out,
+		"next_cursor": "LTE=",
+	})
+}
+
+func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request) {
+	k, err := s.authL2(r, nil)
+	if err != nil {
+		writeErr(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/data/order/")
+
+	s.mu.Lock()
+	s.expireOrders()
+	o, ok := s.orders[id]
+	ok = ok && o.owner == strings.ToLower(k.signer.Hex())
+	var out clob.OpenOrder
+	if ok {
+		out = s.openOrder(o)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeErr(w, http.StatusNotFound, "order not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// ownOrders returns owner's resting orders, oldest first, optionally
+// narrowed to a market and/or token. Caller holds s.mu.
+func (s *Server) ownOrders(owner, market, tokenID string) []*restingOrder {
+	var out []*restingOrder
+	for _, o := range s.orders {
+		if o.owner != owner ||
+			(market != "" && s.tokens[o.tokenID].market != market) ||
+			(tokenID != "" && o.tokenID != tokenID) {
+			continue
+		}
+		out = append(out, o)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].id < out[j].id })
+	return out
+}
+
+// openOrder returns o in /data/orders form. Caller holds s.mu.
+func (s *Server) openOrder(o *restingOrder) clob.OpenOrder {
+	info := s.tokens[o.tokenID]
+	return clob.OpenOrder{
+		ID:           o.id,
+		Status:       "LIVE",
+		Market:       info.market,
+		AssetID:      o.tokenID,
+		Side:         o.side,
+		OriginalSize: formatAmount(o.size),
+		SizeMatched:  formatAmount(o.matched),
+		Price:        formatAmount(o.price),
+		Outcome:      info.outcome,
+		OrderType:    o.orderType,
+		Expiration:   strconv.FormatInt(o.expiration, 10),
+		CreatedAt:    o.createdAt,
+	}
+}
+
+// expireOrders drops GTD orders past their expiration. Caller holds s.mu.
+func (s *Server) expireOrders() {
+	now := time.Now().Unix()
+	for id, o := range s.orders {
+		if o.expiration != 0 && o.expiration <= now {
+			delete(s.orders, id)
+		}
+	}
+}
+
+func (o orderJSON) params() (clob.OrderParams, error) {
+	var p clob.OrderParams
+	var err error
+	if p.Salt, err = parseBig("salt", o.Salt); err != nil {
+		return p, err
+	}
+	if p.TokenID, err = parseBig("tokenId", o.TokenID); err != nil {
+		return p, err
+	}
+	if p.MakerAmount, err = parseBig("makerAmount", o.MakerAmount); err != nil {
+		return p, err
+	}
+	if p.TakerAmount, err = parseBig("takerAmount", o.TakerAmount); err != nil {
+		return p, err
+	}
+	if p.Expiration, err = parseBig("expiration", o.Expiration); err != nil {
+		return p, err
+	}
+	if p.Nonce, err = parseBig("nonce", o.Nonce); err != nil {
+		return p, err
+	}
+	if p.FeeRateBps, err = parseBig("feeRateBps", o.FeeRateBps); err != nil {
+		return p, err
+	}
+	for _, a := range []string{o.Maker, o.Signer, o.Taker} {
+		if !common.IsHexAddress(a) {
+			return p, fmt.Errorf("invalid address %q", a)
+		}
+	}
+	if o.Side != 0 && o.Side != 1 {
+		return p, fmt.Errorf("invalid side %d", o.Side)
+	}
+	p.Maker = common.HexToAddress(o.Maker)
+	p.Signer = common.HexToAddress(o.Signer)
+	p.Taker = common.HexToAddress(o.Taker)
+	p.Side = uint8(o.Side)
+	p.SignatureType = uint8(o.SignatureType)
+	return p, nil
+}
+
+// verifyOrder checks the EIP-712 signature (CTF Exchange domain, falling back
+// to Neg Risk) and that the signer / maker belong to the API key owner.
+func verifyOrder(p clob.OrderParams, sig string, k *apiKey) error {
+	if p.Signer != k.signer {
+		return fmt.Errorf("order signer %s is not the api key owner", p.Signer.Hex())
+	}
+	if p.Maker != k.signer && p.Maker != k.address {
+		return fmt.Errorf("order maker %s is not the api key owner", p.Maker.Hex())
+	}
+	for _, negRisk := range []bool{false, true} {
+		if got, err := clob.RecoverOrderSigner(p, sig, negRisk); err == nil && got == p.Signer {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature does not recover to %s", p.Signer.Hex())
+}
+
+// ── Trades / prices ───────────────────────────────────────────────────────
+
+func (s *Server) handleTrades(w http.ResponseWriter, r *http.Request) {
+	k, err := s.authL2(r, nil)
+	if err != nil {
+		writeErr(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	owner := strings.ToLower(k.signer.Hex())
+	after, _ := strconv.ParseInt(r.URL.Query().Get("after"), 10, 64)
+	offset := 0
+	if c, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("next_cursor")); err == nil {
+		offset, _ = strconv.Atoi(string(c))
+	}
+
+	s.mu.Lock()
+	out := []clob.Trade{}
+	for i, t := range s.trades {
+		ts, _ := strconv.ParseInt(t.Timestamp, 10, 64)
+		if s.owners[i] == owner && ts > after {
+			out = append(out, t)
+		}
+	}
+	s.mu.Unlock()
+
+	// Paged like the real API: next_cursor is the base64 offset, "LTE=" at the end
+	offset = min(max(offset, 0), len(out))
+	end := min(offset+tradesPageSize, len(out))
+	next := "LTE="
+	if end < len(out) {
+		next = base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":        out[offset:end],
+		"next_cursor": next,
+	})
+}
+
+func (s *Server) handlePrice(w http.ResponseWriter, r *http.Request) {
+	tokenID := r.URL.Query().Get("token_id")
+	side := strings.ToUpper(r.URL.Query().Get("side"))
+
+	s.mu.Lock()
+	b := s.books[tokenID]
+	var price float64
+	var ok bool
+	if b != nil {
+		if side == "SELL" {
+			price, ok = b.bestBid()
+		} else {
+			price, ok = b.bestAsk()
+		}
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeErr(w, http.StatusNotFound, "no orderbook exists for the requested token id")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"price": formatAmount(price)})
+}
+
+func (s *Server) handleMidpoint(w http.ResponseWriter, r *http.Request) {
+	tokenID := r.URL.Query().Get("token_id")
+
+	s.mu.Lock()
+	b := s.books[tokenID]
+	var bid, ask float64
+	var okBid, okAsk bool
+	if b != nil {
+		bid, okBid = b.bestBid()
+		ask, okAsk = b.bestAsk()
+	}
+	s.mu.Unlock()
+
+	if !okBid || !okAsk {
+		writeErr(w, http.StatusNotFound, "no orderbook exists for the requested token id")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"mid": formatAmount((bid + ask) / 2)})
+}
+
+func (s *Server) handleBook(w http.ResponseWriter, r *http.Request) {
+	tokenID := r.URL.Query().Get("token_id")
+
+	s.mu.Lock()
+	b := s.books[tokenID]
+	var bids, asks []Level
+	if b != nil {
+		bids, asks = b.levels()
+	}
+	info := s.tokens[tokenID]
+	s.mu.Unlock()
+
+	if b == nil {
+		writeErr(w, http.StatusNotFound, "no orderbook exists for the requested token id")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"market":    info.market,
+		"asset_id":  tokenID,
+		"timestamp": strconv.FormatInt(time.Now().UnixMilli(), 10),
+		"bids":      bids,
+		"asks":      asks,
+	})
+}
+
+// handleSetBook seeds a book over HTTP:
+//
+//	POST /fake/book {"token_id":"…","market":"0x…","outcome":"Up",
+//	                 "bids":[{"price":"0.48","size":"100"}],"asks":[…]}
+func (s *Server) handleSetBook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErr(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req struct {
+		TokenID string  `json:"token_id"`
+		Market  string  `json:"market"`
+		Outcome string  `json:"outcome"`
+		Bids    []Level `json:"bids"`
+		Asks    []Level `json:"asks"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TokenID == "" {
+		writeErr(w, http.StatusBadRequest, "invalid book payload")
+		return
+	}
+	s.SetBook(req.TokenID, req.Bids, req.Asks)
+	if req.Market != "" {
+		s.SetTokenInfo(req.TokenID, req.Market, req.Outcome)
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// ── Helpers ───────────────────────────────────────────────────────────────
+
+func checkTimestamp(ts string) error {
+	n, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid POLY_TIMESTAMP")
+	}
+	if d := time.Since(time.Unix(n, 0)); d > maxClockSkew || d < -maxClockSkew {
+		return fmt.Errorf("POLY_TIMESTAMP outside allowed skew")
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeErr(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func sideName(side uint8) string {
+	if side == 1 {
+		return "SELL"
+	}
+	return "BUY"
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// randomSecret returns a base64url secret, like the real API.
+func randomSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64URL(b)
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
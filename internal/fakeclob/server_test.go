@@ -0,0 +1,294 @@
+package fakeclob
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clob"
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+const (
+	testCID = "0xcondition"
+	upToken = "1001"
+	dnToken = "1002"
+)
+
+// setup starts a fake CLOB with one market and returns it with a client
+// whose L2 creds were derived from it.
+func setup(t *testing.T) (*Server, *clob.Client, *httptest.Server) {
+	t.Helper()
+	srv := New()
+	srv.AddMarket(testCID, upToken, dnToken)
+	srv.SetBook(upToken,
+		[]Level{{"0.48", "100"}, {"0.47", "100"}},
+		[]Level{{"0.50", "10"}, {"0.52", "100"}})
+	hs := httptest.NewServer(srv.Handler())
+	t.Cleanup(hs.Close)
+	return srv, newClient(t, hs.URL), hs
+}
+
+func newClient(t *testing.T, url string) *clob.Client {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := config.PrivateKey
+	config.PrivateKey = hex.EncodeToString(crypto.FromECDSA(key))
+	defer func() { config.PrivateKey = prev }()
+
+	c, err := clob.NewClientWithHost(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateOrDeriveAPICreds(context.Background()); err != nil {
+		t.Fatalf("derive creds: %v", err)
+	}
+	return c
+}
+
+func TestAuth(t *testing.T) {
+	_, c, hs := setup(t)
+	ctx := context.Background()
+
+	key1, _ := c.Identity()
+	creds, err := c.CreateOrDeriveAPICreds(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.APIKey != key1 {
+		t.Errorf("derive returned a new key %s, want %s again", creds.APIKey, key1)
+	}
+	if _, err := c.GetTrades(ctx, 0); err != nil {
+		t.Errorf("GetTrades with derived creds: %v", err)
+	}
+
+	c.SetAPICreds(&types.APICreds{APIKey: creds.APIKey, APISecret: creds.APISecret, Passphrase: "wrong"})
+	if _, err := c.GetTrades(ctx, 0); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("GetTrades with a bad passphrase: err = %v, want HTTP 401", err)
+	}
+
+	// Another account's creds do not authenticate this signer
+	other := newClient(t, hs.URL)
+	k, _ := other.Identity()
+	c.SetAPICreds(&types.APICreds{APIKey: k, APISecret: creds.APISecret, Passphrase: creds.Passphrase})
+	if _, err := c.GetTrades(ctx, 0); err == nil {
+		t.Error("GetTrades with another account's API key succeeded")
+	}
+}
+
+func TestOrderSignatureChecked(t *testing.T) {
+	_, c, hs := setup(t)
+	_, maker := c.Identity()
+	creds, err := c.CreateOrDeriveAPICreds(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An order claiming our address but signed by another key
+	forger, _ := crypto.GenerateKey()
+	params := clob.OrderParams{
+		Salt:        big.NewInt(1),
+		Maker:       common.HexToAddress(maker),
+		Signer:      common.HexToAddress(maker),
+		TokenID:     big.NewInt(1001),
+		MakerAmount: clob.USDCToUnits(5),
+		TakerAmount: clob.USDCToUnits(10),
+		Expiration:  big.NewInt(0),
+		Nonce:       big.NewInt(0),
+		FeeRateBps:  big.NewInt(0),
+	}
+	sig, err := clob.BuildAndSignOrder(params, forger, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"orderType": "FOK",
+		"order": map[string]interface{}{
+			"salt": "1", "maker": maker, "signer": maker,
+			"taker":   "0x0000000000000000000000000000000000000000",
+			"tokenId": upToken, "makerAmount": "5000000", "takerAmount": "10000000",
+			"expiration": "0", "nonce": "0", "feeRateBps": "0",
+			"side": 0, "signatureType": 0, "signature": sig,
+		},
+	})
+	req, _ := http.NewRequest(http.MethodPost, hs.URL+"/order", bytes.NewReader(body))
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("POLY_ADDRESS", strings.ToLower(maker))
+	req.Header.Set("POLY_API_KEY", creds.APIKey)
+	req.Header.Set("POLY_PASSPHRASE", creds.Passphrase)
+	req.Header.Set("POLY_TIMESTAMP", ts)
+	req.Header.Set("POLY_SIGNATURE", clob.L2Signature(creds.APISecret, ts, http.MethodPost, "/order", string(body)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var out map[string]string
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(out["error"], "invalid signature") {
+		t.Errorf("forged order: HTTP %d %v, want 400 invalid signature", resp.StatusCode, out)
+	}
+}
+
+func TestMarketOrders(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        clob.MarketOrderRequest
+		wantErr    string
+		wantTokens float64 // tokens in the recorded trade
+		wantPrice  float64
+	}{
+		{
+			name:       "FOK buy within the best ask",
+			req:        clob.MarketOrderRequest{Side: "UP", USDCAmount: 4, PriceHint: 0.50},
+			wantTokens: 8, wantPrice: 0.50,
+		},
+		{
+			name:       "FOK buy walking two levels",
+			req:        clob.MarketOrderRequest{Side: "UP", USDCAmount: 10.2, PriceHint: 0.52},
+			wantTokens: 20, wantPrice: 0.51,
+		},
+		{
+			name:    "FOK buy killed by the price limit",
+			req:     clob.MarketOrderRequest{Side: "UP", USDCAmount: 10.2, PriceHint: 0.50},
+			wantErr: "fully filled or killed",
+		},
+		{
+			name:       "FOK sell",
+			req:        clob.MarketOrderRequest{Side: "UP", OrderSide: types.SideSell, Tokens: 10, PriceHint: 0.48},
+			wantTokens: 10, wantPrice: 0.48,
+		},
+		{
+			name:    "FOK sell killed by thin bids",
+			req:     clob.MarketOrderRequest{Side: "UP", OrderSide: types.SideSell, Tokens: 300, PriceHint: 0.40},
+			wantErr: "fully filled or killed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c, _ := setup(t)
+			tt.req.ConditionID, tt.req.UpTokenID, tt.req.DownTokenID = testCID, upToken, dnToken
+			resp, err := c.PlaceMarketOrder(context.Background(), tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				if n := len(srv.Trades()); n != 0 {
+					t.Errorf("%d trades recorded for a killed order", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp["status"] != "matched" || resp["orderID"] == "" {
+				t.Errorf("response = %v, want a matched order", resp)
+			}
+			trades, err := c.GetTrades(context.Background(), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(trades) != 1 {
+				t.Fatalf("%d trades, want 1", len(trades))
+			}
+			tr := trades[0]
+			size, _ := strconv.ParseFloat(tr.Size, 64)
+			price, _ := strconv.ParseFloat(tr.Price, 64)
+			if !near(size, tt.wantTokens) || !near(price, tt.wantPrice) {
+				t.Errorf("trade %s @ %s, want %v @ %v", tr.Size, tr.Price, tt.wantTokens, tt.wantPrice)
+			}
+			if tr.Market != testCID || tr.Outcome != "Up" || tr.TraderSide != "TAKER" || tr.Side != tt.req.OrderSide.String() {
+				t.Errorf("trade = %+v", tr)
+			}
+		})
+	}
+}
+
+func TestRestingOrders(t *testing.T) {
+	srv, c, hs := setup(t)
+	ctx := context.Background()
+
+	// Crosses the 0.50 ask for 10 tokens, rests the other 15
+	resp, err := c.PlaceLimitOrder(ctx, clob.LimitOrderRequest{
+		TokenID: upToken, OrderSide: types.SideBuy, Price: 0.50, Size: 25,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := resp["orderID"].(string)
+	if resp["status"] != "live" || resp["takingAmount"] != "10" {
+		t.Errorf("GTC response = %v, want live with 10 tokens taken", resp)
+	}
+
+	// GTD must expire in the future
+	_, err = c.PlaceLimitOrder(ctx, clob.LimitOrderRequest{
+		TokenID: upToken, OrderSide: types.SideBuy, Price: 0.40, Size: 5,
+		OrderType: "GTD", Expiration: time.Now().Add(-time.Minute).Unix(),
+	})
+	if err == nil {
+		t.Error("GTD order with a past expiration accepted")
+	}
+	gtd, err := c.PlaceLimitOrder(ctx, clob.LimitOrderRequest{
+		TokenID: upToken, OrderSide: types.SideBuy, Price: 0.40, Size: 5,
+		OrderType: "GTD", Expiration: time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	open, err := c.GetOpenOrders(ctx, testCID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 2 || open[0].ID != id || open[0].SizeMatched != "10" || open[1].OrderType != "GTD" {
+		t.Fatalf("open orders = %+v", open)
+	}
+	if other, _ := newClient(t, hs.URL).GetOpenOrders(ctx, "", ""); len(other) != 0 {
+		t.Errorf("another account sees %d of our orders", len(other))
+	}
+
+	// A taker sells into the rest: we are the maker of a BUY
+	if err := srv.Fill(id, 15); err != nil {
+		t.Fatal(err)
+	}
+	trades, _ := c.GetTrades(ctx, 0)
+	if len(trades) != 2 {
+		t.Fatalf("%d trades, want 2", len(trades))
+	}
+	apiKey, maker := c.Identity()
+	legs := trades[1].Legs(apiKey, maker)
+	if len(legs) != 1 || legs[0].Side != "BUY" || legs[0].Size != "15" || legs[0].Price != "0.5" {
+		t.Errorf("maker legs = %+v, want BUY 15 @ 0.5", legs)
+	}
+
+	open, _ = c.GetOpenOrders(ctx, "", "")
+	if len(open) != 1 {
+		t.Fatalf("%d open orders after the fill, want 1 (the GTD)", len(open))
+	}
+	cr, err := c.CancelOrder(ctx, gtd["orderID"].(string))
+	if err != nil || len(cr.Canceled) != 1 {
+		t.Fatalf("cancel = %+v, %v", cr, err)
+	}
+	if cr, _ := c.CancelOrder(ctx, id); len(cr.NotCanceled) != 1 {
+		t.Errorf("cancelling a filled order: %+v, want not canceled", cr)
+	}
+}
+
+func near(a, b float64) bool {
+	return a-b < 1e-6 && b-a < 1e-6
+}
@@ -0,0 +1,123 @@
+package fsm
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/book"
+)
+
+func asks(levels ...book.Level) *book.Book {
+	b := book.New()
+	b.Snapshot(nil, levels, time.Unix(0, 0))
+	return b
+}
+
+func bids(levels ...book.Level) *book.Book {
+	b := book.New()
+	b.Snapshot(levels, nil, time.Unix(0, 0))
+	return b
+}
+
+func TestArbDepth(t *testing.T) {
+	tests := []struct {
+		name      string
+		up, down  *book.Book
+		wantPairs float64 // within the 0.01 search resolution
+		wantCost  float64 // average per pair, checked when wantPairs > 0
+	}{
+		{
+			name:      "whole book under the threshold",
+			up:        asks(book.Level{Price: 0.45, Size: 100}),
+			down:      asks(book.Level{Price: 0.50, Size: 80}),
+			wantPairs: 80, wantCost: 0.95,
+		},
+		{
+			// 50 @ 0.45 then 0.55 on UP against 0.50 on DOWN:
+			// (22.5 + 0.55(q-50) + 0.50q) / q < 0.97 ⇔ q < 62.5
+			name:      "second level caps the size",
+			up:        asks(book.Level{Price: 0.45, Size: 50}, book.Level{Price: 0.55, Size: 100}),
+			down:      asks(book.Level{Price: 0.50, Size: 200}),
+			wantPairs: 62.5, wantCost: 0.97,
+		},
+		{
+			name: "best asks already too expensive",
+			up:   asks(book.Level{Price: 0.50, Size: 100}),
+			down: asks(book.Level{Price: 0.50, Size: 100}),
+		},
+		{
+			name: "one side empty",
+			up:   asks(book.Level{Price: 0.40, Size: 100}),
+			down: book.New(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs, cost := arbDepth(tt.up, tt.down, 0.97)
+			if tt.wantPairs == 0 {
+				if pairs != 0 || cost != 0 {
+					t.Errorf("arbDepth = %.4f pairs @ %.4f, want none", pairs, cost)
+				}
+				return
+			}
+			if pairs > tt.wantPairs || tt.wantPairs-pairs > 0.01 {
+				t.Errorf("pairs = %.4f, want %.2f (within 0.01 below)", pairs, tt.wantPairs)
+			}
+			if cost >= 0.97 || math.Abs(cost-tt.wantCost) > 0.001 {
+				t.Errorf("pair cost = %.5f, want ≈ %.3f and below 0.97", cost, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestSplitDepth(t *testing.T) {
+	tests := []struct {
+		name         string
+		up, down     *book.Book
+		wantPairs    float64 // within the 0.01 search resolution
+		wantProceeds float64 // average per pair, checked when wantPairs > 0
+	}{
+		{
+			name:      "whole book above the threshold",
+			up:        bids(book.Level{Price: 0.55, Size: 100}),
+			down:      bids(book.Level{Price: 0.50, Size: 120}),
+			wantPairs: 100, wantProceeds: 1.05,
+		},
+		{
+			// 50 @ 0.55 then 0.45 on UP against 0.50 on DOWN:
+			// (27.5 + 0.45(q-50) + 0.50q) / q > 1.02 ⇔ q < 71.43
+			name:      "second level caps the size",
+			up:        bids(book.Level{Price: 0.55, Size: 50}, book.Level{Price: 0.45, Size: 100}),
+			down:      bids(book.Level{Price: 0.50, Size: 200}),
+			wantPairs: 5 / 0.07, wantProceeds: 1.02,
+		},
+		{
+			name: "best bids too low",
+			up:   bids(book.Level{Price: 0.51, Size: 100}),
+			down: bids(book.Level{Price: 0.50, Size: 100}),
+		},
+		{
+			name: "one side empty",
+			up:   book.New(),
+			down: bids(book.Level{Price: 0.60, Size: 100}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs, proceeds := splitDepth(tt.up, tt.down, 1.02)
+			if tt.wantPairs == 0 {
+				if pairs != 0 || proceeds != 0 {
+					t.Errorf("splitDepth = %.4f pairs @ %.4f, want none", pairs, proceeds)
+				}
+				return
+			}
+			if pairs > tt.wantPairs || tt.wantPairs-pairs > 0.01 {
+				t.Errorf("pairs = %.4f, want %.2f (within 0.01 below)", pairs, tt.wantPairs)
+			}
+			if proceeds <= 1.02 || math.Abs(proceeds-tt.wantProceeds) > 0.001 {
+				t.Errorf("proceeds = %.5f, want ≈ %.3f and above 1.02", proceeds, tt.wantProceeds)
+			}
+		})
+	}
+}
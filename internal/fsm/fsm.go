@@ -3,12 +3,18 @@
 package fsm
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"os"
 	"sync"
 	"time"
 
+	"github.com/gipsh/polymarket-bot-go/internal/book"
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
 	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/fileutil"
 	"github.com/gipsh/polymarket-bot-go/internal/inventory"
 	"github.com/gipsh/polymarket-bot-go/internal/types"
 )
@@ -17,37 +23,65 @@ import (
 // Mostly stateless; tracks per-market cooldowns and spending caps.
 type FSM struct {
 	mu             sync.Mutex
+	clock          clock.Clock
+	filepath       string // "" = not persisted
 	lastMomentumTS map[string]time.Time
 	momentumSpent  map[string]float64
 	lastArbTS      map[string]time.Time
 	arbSpent       map[string]float64
+	lastSplitTS    map[string]time.Time
+	splitSpent     map[string]float64
+	closesAt       map[string]time.Time // market close time, for expiring the above
+	unreadable     string               // why the state file could not be loaded ("" = fine)
 }
 
-// New creates a new FSM instance.
+// New creates an FSM driven by the default clock whose cooldowns and spend
+// caps are persisted to the configured state file, so a restart mid-market
+// can't spend past ARB_MAX_USDC / MOMENTUM_MAX_USDC.
 func New() *FSM {
+	f := NewWithClock(clock.Default())
+	f.filepath = config.FSMStateFile
+	f.load()
+	return f
+}
+
+// NewWithClock creates an in-memory FSM whose cooldowns are measured
+// against clk. Used by the backtester and replay mode to run against
+// simulated time.
+func NewWithClock(clk clock.Clock) *FSM {
 	return &FSM{
+		clock:          clk,
 		lastMomentumTS: make(map[string]time.Time),
 		momentumSpent:  make(map[string]float64),
 		lastArbTS:      make(map[string]time.Time),
 		arbSpent:       make(map[string]float64),
+		lastSplitTS:    make(map[string]time.Time),
+		splitSpent:     make(map[string]float64),
+		closesAt:       make(map[string]time.Time),
 	}
 }
 
 const (
 	momentumCooldown = 120 * time.Second // 2 min between momentum fills
 	arbCooldown      = 5 * time.Second   // 5s between arb orders
+	splitCooldown    = 30 * time.Second  // 30s between splits (each waits for a receipt)
+	minArbOrderUSDC  = 1.0               // CLOB minimum market order size
+	quoteTick        = 0.01              // CLOB price tick for maker quotes
+	unknownCloseHold = 24 * time.Hour    // keeps spend of a market not stepped yet
 )
 
 // Step evaluates market conditions and returns (botState, action).
 func (f *FSM) Step(
 	conditionID string,
 	prices *types.Prices,
-	inv *inventory.Inventory,
+	inv inventory.Inventory,
 	minutesToClose float64,
 ) (types.BotState, types.Action) {
 
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	now := f.clock.Now()
+	f.closesAt[conditionID] = now.Add(time.Duration(minutesToClose * float64(time.Minute)))
 
 	// ── Market resolved or about to close: MERGE ──────────────────────
 	if prices.State == types.StateResolved || minutesToClose < 1 {
@@ -60,6 +94,11 @@ func (f *FSM) Step(
 		return types.BotResolution, types.SkipAction("market resolved, no pairs to merge")
 	}
 
+	// ── Reverse ARB: bids sum above $1, mint pairs and sell both legs ──
+	if prices.State == types.StateSplit {
+		return types.BotSplit, f.splitSell(conditionID, prices, now)
+	}
+
 	// ── MOMENTUM modes ────────────────────────────────────────────────
 	if prices.State == types.StateMomentumUp || prices.State == types.StateMomentumDown {
 		var mainSide, hedgeSide string
@@ -89,7 +128,7 @@ func (f *FSM) Step(
 
 		// Cooldown
 		if last, ok := f.lastMomentumTS[conditionID]; ok {
-			if remaining := momentumCooldown - time.Since(last); remaining > 0 {
+			if remaining := momentumCooldown - now.Sub(last); remaining > 0 {
 				return botState, types.WaitAction(
 					fmt.Sprintf("MOMENTUM cooldown: %.0fs remaining", remaining.Seconds()),
 				)
@@ -101,8 +140,9 @@ func (f *FSM) Step(
 		if leftover := config.MomentumMaxUSDC - spent; leftover < remaining {
 			remaining = leftover
 		}
-		f.lastMomentumTS[conditionID] = time.Now()
+		f.lastMomentumTS[conditionID] = now
 		f.momentumSpent[conditionID] = spent + remaining + config.MomentumHedgeUSDC
+		f.save()
 
 		fillNum := int(spent/config.MomentumMainUSDC) + 1
 		return botState, types.BuyMomentumAction(
@@ -112,8 +152,11 @@ func (f *FSM) Step(
 		)
 	}
 
-	// ── GREY zone: wait ────────────────────────────────────────────────
+	// ── GREY zone: wait (or rest maker bids) ───────────────────────────
 	if prices.State == types.StateGrey {
+		if config.ARBMakerEnabled {
+			return types.BotGrey, f.quoteArb(conditionID, prices, inv)
+		}
 		return types.BotGrey, types.WaitAction(
 			fmt.Sprintf("grey zone: spread=%.3f | winner=%.3f", prices.Spread, prices.WinnerPrice()),
 		)
@@ -132,33 +175,445 @@ func (f *FSM) Step(
 
 		// Cooldown
 		if last, ok := f.lastArbTS[conditionID]; ok {
-			if remaining := arbCooldown - time.Since(last); remaining > 0 {
+			if remaining := arbCooldown - now.Sub(last); remaining > 0 {
 				return types.BotARB, types.SkipAction(
 					fmt.Sprintf("ARB cooldown: %.1fs", remaining.Seconds()),
 				)
 			}
 		}
 
-		// Pick cheaper side; override with inventory rebalancing if skewed >20
-		var sideToBuy, reason string
+		budget := config.ARBMaxUSDC - arbSp
+
+		// Inventory skewed >20 (e.g. a leg failed earlier): buy only the short side
 		imbalanceSide, imbalanceAmt := inv.GetImbalance(conditionID)
 		if imbalanceAmt > 20 {
-			sideToBuy = imbalanceSide
-			reason = fmt.Sprintf("ARB rebalance: need %s (%.1f excess on other side)", sideToBuy, imbalanceAmt)
-		} else if prices.Up <= prices.Down {
-			sideToBuy = "UP"
-			reason = fmt.Sprintf("ARB: buy UP (cheaper at %.3f) | spread=%.3f", prices.Up, prices.Spread)
-		} else {
-			sideToBuy = "DOWN"
-			reason = fmt.Sprintf("ARB: buy DOWN (cheaper at %.3f) | spread=%.3f", prices.Down, prices.Spread)
+			sideToBuy := imbalanceSide
+			reason := fmt.Sprintf("ARB rebalance: need %s (%.1f excess on other side)", sideToBuy, imbalanceAmt)
+
+			// Size from book depth when known, else the fixed ARB_ORDER_USDC
+			// at the quoted price. price is the worst ask the buy reaches.
+			sideBook, price := prices.UpBook, prices.Up
+			if sideToBuy == "DOWN" {
+				sideBook, price = prices.DownBook, prices.Down
+			}
+			var tokens, usdc float64
+			if prices.UpBook != nil && prices.DownBook != nil {
+				pairs, pairCost := arbDepth(prices.UpBook, prices.DownBook, config.ARBThreshold)
+				tokens = math.Min(pairs, imbalanceAmt)
+				usdc, _ = sideBook.CostToBuy(tokens)
+				price = worstAsk(sideBook, tokens)
+				if pairs > 0 {
+					reason += fmt.Sprintf(" | depth %.1f pairs @ %.3f (edge %.3f/pair)", pairs, pairCost, 1-pairCost)
+				}
+			} else if price > 0 {
+				usdc = config.ARBOrderUSDC
+				tokens = usdc / price
+			}
+			if usdc > budget {
+				tokens *= budget / usdc // average cost only falls as size shrinks
+				usdc = budget
+			}
+			if usdc < minArbOrderUSDC {
+				return types.BotARB, types.SkipAction(
+					fmt.Sprintf("ARB: no depth below %.3f (size $%.2f) | spread=%.3f",
+						config.ARBThreshold, usdc, prices.Spread),
+				)
+			}
+
+			f.lastArbTS[conditionID] = now
+			f.arbSpent[conditionID] = arbSp + usdc
+			f.save()
+			return types.BotARB, types.BuyArbAction(sideToBuy, tokens, usdc, price, reason)
+		}
+
+		// Buy UP and DOWN together in equal token counts.
+		// Size from book depth when known, else ARB_ORDER_USDC at quoted prices.
+		// Each leg's limit is the worst ask its size reaches.
+		var pairs, upUSDC, downUSDC float64
+		upPrice, downPrice := prices.Up, prices.Down
+		reason := fmt.Sprintf("ARB: buy pair UP=%.3f DOWN=%.3f | spread=%.3f", prices.Up, prices.Down, prices.Spread)
+		if prices.UpBook != nil && prices.DownBook != nil {
+			var pairCost float64
+			pairs, pairCost = arbDepth(prices.UpBook, prices.DownBook, config.ARBThreshold)
+			if pairs > 0 && pairs*pairCost > budget {
+				pairs = budget / pairCost // pair cost only falls as size shrinks
+			}
+			upUSDC, _ = prices.UpBook.CostToBuy(pairs)
+			downUSDC, _ = prices.DownBook.CostToBuy(pairs)
+			upPrice, downPrice = worstAsk(prices.UpBook, pairs), worstAsk(prices.DownBook, pairs)
+			if pairs > 0 {
+				reason += fmt.Sprintf(" | depth %.1f pairs @ %.3f (edge %.3f/pair)", pairs, pairCost, 1-pairCost)
+			}
+		} else if prices.Spread > 0 {
+			pairs = math.Min(config.ARBOrderUSDC, budget) / prices.Spread
+			upUSDC = pairs * prices.Up
+			downUSDC = pairs * prices.Down
+		}
+		if upUSDC < minArbOrderUSDC || downUSDC < minArbOrderUSDC {
+			return types.BotARB, types.SkipAction(
+				fmt.Sprintf("ARB: no depth below %.3f (legs $%.2f/$%.2f) | spread=%.3f",
+					config.ARBThreshold, upUSDC, downUSDC, prices.Spread),
+			)
 		}
 
-		f.lastArbTS[conditionID] = time.Now()
-		f.arbSpent[conditionID] = arbSp + config.ARBOrderUSDC
-		return types.BotARB, types.BuyArbAction(sideToBuy, config.ARBOrderUSDC, reason)
+		f.lastArbTS[conditionID] = now
+		f.arbSpent[conditionID] = arbSp + upUSDC + downUSDC
+		f.save()
+		return types.BotARB, types.BuyArbPairAction(pairs, upUSDC, downUSDC, upPrice, downPrice, reason)
 	}
 
 	// ── Fallback ───────────────────────────────────────────────────────
 	log.Printf("[fsm] unknown state %s for %s...", prices.State, conditionID[:8])
 	return types.BotIdle, types.SkipAction(fmt.Sprintf("unknown state: %s", prices.State))
 }
+
+// RecordArbSpend adds USDC spent on ARB outside Step (maker fills) to the
+// per-condition ARB cap. A fill can arrive for a market not stepped since
+// the last restart; its close time is then unknown, so the spend is kept for
+// unknownCloseHold (until the next Step sets the real close).
+func (f *FSM) RecordArbSpend(conditionID string, usdc float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.closesAt[conditionID]; !ok {
+		f.closesAt[conditionID] = f.clock.Now().Add(unknownCloseHold)
+	}
+	f.arbSpent[conditionID] += usdc
+	f.save()
+}
+
+// Unreadable returns why the state file could not be loaded at startup, or
+// "" if it was loaded (or did not exist). Caps and cooldowns then start from
+// zero, so the caller should refuse to trade until an operator has looked.
+func (f *FSM) Unreadable() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.unreadable
+}
+
+// Status is a snapshot of the FSM's per-condition caps and cooldowns.
+type Status struct {
+	MomentumSpent    float64 `json:"momentum_spent_usdc"`
+	ArbSpent         float64 `json:"arb_spent_usdc"`
+	SplitSpent       float64 `json:"split_spent_usdc"`
+	MomentumCooldown float64 `json:"momentum_cooldown_sec"` // seconds left, 0 = ready
+	ArbCooldown      float64 `json:"arb_cooldown_sec"`
+	SplitCooldown    float64 `json:"split_cooldown_sec"`
+}
+
+// Status returns the spend and remaining cooldowns for a condition.
+func (f *FSM) Status(conditionID string) Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.clock.Now()
+	left := func(last time.Time, cooldown time.Duration) float64 {
+		return math.Max(0, (cooldown - now.Sub(last)).Seconds()) // zero last = ready
+	}
+	return Status{
+		MomentumSpent:    f.momentumSpent[conditionID],
+		ArbSpent:         f.arbSpent[conditionID],
+		SplitSpent:       f.splitSpent[conditionID],
+		MomentumCooldown: left(f.lastMomentumTS[conditionID], momentumCooldown),
+		ArbCooldown:      left(f.lastArbTS[conditionID], arbCooldown),
+		SplitCooldown:    left(f.lastSplitTS[conditionID], splitCooldown),
+	}
+}
+
+// ── Reverse ARB ───────────────────────────────────────────────────────────
+
+// splitSell sizes a split: as many pairs as the two bid books absorb while
+// the proceeds per pair stay above SPLIT_THRESHOLD, or SPLIT_ORDER_USDC at
+// the best bids without depth, within what is left of SPLIT_MAX_USDC.
+// Caller holds f.mu.
+func (f *FSM) splitSell(conditionID string, prices *types.Prices, now time.Time) types.Action {
+	spent := f.splitSpent[conditionID]
+	if spent >= config.SplitMaxUSDC {
+		return types.SkipAction(
+			fmt.Sprintf("SPLIT cap reached ($%.0f/$%.0f) for %s...",
+				spent, config.SplitMaxUSDC, conditionID[:8]),
+		)
+	}
+	if last, ok := f.lastSplitTS[conditionID]; ok {
+		if remaining := splitCooldown - now.Sub(last); remaining > 0 {
+			return types.SkipAction(fmt.Sprintf("SPLIT cooldown: %.1fs", remaining.Seconds()))
+		}
+	}
+	budget := config.SplitMaxUSDC - spent
+
+	bids := prices.UpBid + prices.DownBid
+	pairs, upFloor, downFloor := math.Min(config.SplitOrderUSDC, budget), prices.UpBid, prices.DownBid
+	reason := fmt.Sprintf("SPLIT: bids UP=%.3f + DOWN=%.3f = %.3f", prices.UpBid, prices.DownBid, bids)
+	if prices.UpBook != nil && prices.DownBook != nil {
+		var proceeds float64
+		pairs, proceeds = splitDepth(prices.UpBook, prices.DownBook, config.SplitThreshold)
+		pairs = math.Min(pairs, budget)
+		upFloor, downFloor = worstBid(prices.UpBook, pairs), worstBid(prices.DownBook, pairs)
+		if pairs > 0 {
+			reason += fmt.Sprintf(" | depth %.1f pairs @ %.3f (edge %.3f/pair)", pairs, proceeds, proceeds-1)
+		}
+	}
+	if pairs*math.Min(upFloor, downFloor) < minArbOrderUSDC {
+		return types.SkipAction(
+			fmt.Sprintf("SPLIT: no bid depth above %.3f (%.2f pairs) | bids=%.3f",
+				config.SplitThreshold, pairs, bids),
+		)
+	}
+
+	f.lastSplitTS[conditionID] = now
+	f.splitSpent[conditionID] = spent + pairs
+	f.save()
+	return types.SplitSellAction(pairs, upFloor, downFloor, reason)
+}
+
+// ── Maker quotes ──────────────────────────────────────────────────────────
+
+// quoteArb prices resting bids on both sides so that they sum to
+// ARB_THRESHOLD, split in proportion to the current prices. Each side bids
+// for ARB_MAKER_PAIRS tokens within the remaining ARB budget; the side
+// already long in inventory bids for that much less.
+func (f *FSM) quoteArb(conditionID string, prices *types.Prices, inv inventory.Inventory) types.Action {
+	if prices.Spread <= 0 {
+		return types.WaitAction("grey zone: no prices to quote")
+	}
+	upBid := floorTick(prices.Up * config.ARBThreshold / prices.Spread)
+	downBid := floorTick(prices.Down * config.ARBThreshold / prices.Spread)
+	if upBid <= 0 || downBid <= 0 {
+		return types.WaitAction(fmt.Sprintf("grey zone: no quote (up=%.3f down=%.3f)", prices.Up, prices.Down))
+	}
+
+	budget := config.ARBMaxUSDC - f.arbSpent[conditionID]
+	pairs := math.Min(config.ARBMakerPairs, budget/(upBid+downBid))
+	upSize, downSize := pairs, pairs
+	if short, amt := inv.GetImbalance(conditionID); short == "UP" {
+		downSize = math.Max(0, pairs-amt)
+	} else {
+		upSize = math.Max(0, pairs-amt)
+	}
+	if upSize*upBid < minArbOrderUSDC {
+		upSize = 0
+	}
+	if downSize*downBid < minArbOrderUSDC {
+		downSize = 0
+	}
+	if upSize == 0 && downSize == 0 {
+		return types.SkipAction(fmt.Sprintf("ARB maker: budget left $%.2f too small to quote", budget))
+	}
+
+	return types.QuoteArbAction(upBid, downBid, upSize, downSize,
+		fmt.Sprintf("ARB maker: bid UP %.2f×%.1f + DOWN %.2f×%.1f (sum %.2f) | spread=%.3f",
+			upBid, upSize, downBid, downSize, upBid+downBid, prices.Spread))
+}
+
+// floorTick rounds a price down to the quote tick (with a little slack for
+// float error, so 0.56 stays 0.56).
+func floorTick(p float64) float64 {
+	return math.Floor(p/quoteTick+1e-9) * quoteTick
+}
+
+// ── Depth sizing ──────────────────────────────────────────────────────────
+
+// arbDepth returns the largest number of UP+DOWN pairs that can be bought
+// from the two books while the average cost per pair (VWAP of both legs)
+// stays below threshold, together with that average cost.
+//
+// Pair cost is non-decreasing in size (each leg walks up its asks), so a
+// binary search over [0, min(total asks)] finds the boundary.
+func arbDepth(up, down *book.Book, threshold float64) (pairs, pairCost float64) {
+	upTokens, _ := up.AskDepth(1) // token prices never exceed $1
+	downTokens, _ := down.AskDepth(1)
+	hi := math.Min(upTokens, downTokens)
+	if hi <= 0 {
+		return 0, 0
+	}
+
+	cost := func(q float64) float64 {
+		u, _ := up.CostToBuy(q)
+		d, _ := down.CostToBuy(q)
+		return (u + d) / q
+	}
+
+	if cost(hi) < threshold {
+		return hi, cost(hi)
+	}
+	lo := 0.0
+	for i := 0; i < 40 && hi-lo > 0.01; i++ {
+		mid := (lo + hi) / 2
+		if cost(mid) < threshold {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	if lo <= 0 {
+		return 0, 0
+	}
+	return lo, cost(lo)
+}
+
+// splitDepth returns the largest number of pairs whose two legs can be sold
+// into the bid books while the average proceeds per pair stay above
+// threshold, together with those proceeds. Mirror image of arbDepth:
+// proceeds per pair only fall as size grows.
+func splitDepth(up, down *book.Book, threshold float64) (pairs, proceeds float64) {
+	upTokens, _ := up.BidDepth(0)
+	downTokens, _ := down.BidDepth(0)
+	hi := math.Min(upTokens, downTokens)
+	if hi <= 0 {
+		return 0, 0
+	}
+
+	value := func(q float64) float64 {
+		_, u, _ := up.SellVWAP(q)
+		_, d, _ := down.SellVWAP(q)
+		return (u + d) / q
+	}
+
+	if value(hi) > threshold {
+		return hi, value(hi)
+	}
+	lo := 0.0
+	for i := 0; i < 40 && hi-lo > 0.01; i++ {
+		mid := (lo + hi) / 2
+		if value(mid) > threshold {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	if lo <= 0 {
+		return 0, 0
+	}
+	return lo, value(lo)
+}
+
+// worstAsk returns the highest ask price a buy of tokens reaches (the last
+// ask if the book is too thin).
+func worstAsk(b *book.Book, tokens float64) float64 {
+	worst := 0.0
+	for _, l := range b.Asks() {
+		worst = l.Price
+		if tokens -= l.Size; tokens <= 1e-9 {
+			break
+		}
+	}
+	return worst
+}
+
+// worstBid returns the lowest bid price a sell of tokens reaches.
+func worstBid(b *book.Book, tokens float64) float64 {
+	worst := 0.0
+	for _, l := range b.Bids() {
+		worst = l.Price
+		if tokens -= l.Size; tokens <= 1e-9 {
+			break
+		}
+	}
+	return worst
+}
+
+// ── Persistence ───────────────────────────────────────────────────────────
+
+// savedMarket is the on-disk form of one condition's cooldowns and spend.
+type savedMarket struct {
+	ClosesAt       time.Time `json:"closes_at"`
+	LastMomentumTS time.Time `json:"last_momentum_ts"`
+	MomentumSpent  float64   `json:"momentum_spent_usdc"`
+	LastArbTS      time.Time `json:"last_arb_ts"`
+	ArbSpent       float64   `json:"arb_spent_usdc"`
+	LastSplitTS    time.Time `json:"last_split_ts,omitempty"`
+	SplitSpent     float64   `json:"split_spent_usdc,omitempty"`
+}
+
+// expire drops every condition whose market has closed. Caller holds f.mu.
+func (f *FSM) expire(now time.Time) {
+	for cid, closes := range f.closesAt {
+		if closes.Before(now) {
+			delete(f.closesAt, cid)
+			delete(f.lastMomentumTS, cid)
+			delete(f.momentumSpent, cid)
+			delete(f.lastArbTS, cid)
+			delete(f.arbSpent, cid)
+			delete(f.lastSplitTS, cid)
+			delete(f.splitSpent, cid)
+		}
+	}
+}
+
+func (f *FSM) load() {
+	if _, err := os.Stat(f.filepath); os.IsNotExist(err) {
+		return
+	}
+	data, err := os.ReadFile(f.filepath)
+	if err != nil {
+		f.setAside(err)
+		return
+	}
+	var saved map[string]savedMarket
+	if err := json.Unmarshal(data, &saved); err != nil {
+		f.setAside(err)
+		return
+	}
+	for cid, s := range saved {
+		f.closesAt[cid] = s.ClosesAt
+		if !s.LastMomentumTS.IsZero() {
+			f.lastMomentumTS[cid] = s.LastMomentumTS
+		}
+		if s.MomentumSpent > 0 {
+			f.momentumSpent[cid] = s.MomentumSpent
+		}
+		if !s.LastArbTS.IsZero() {
+			f.lastArbTS[cid] = s.LastArbTS
+		}
+		if s.ArbSpent > 0 {
+			f.arbSpent[cid] = s.ArbSpent
+		}
+		if !s.LastSplitTS.IsZero() {
+			f.lastSplitTS[cid] = s.LastSplitTS
+		}
+		if s.SplitSpent > 0 {
+			f.splitSpent[cid] = s.SplitSpent
+		}
+	}
+	f.expire(f.clock.Now())
+	log.Printf("[fsm] loaded: %d open markets with caps/cooldowns", len(f.closesAt))
+}
+
+// setAside handles a state file that cannot be loaded: it is kept for
+// inspection as *.corrupt-<unix> and the error recorded for Unreadable.
+func (f *FSM) setAside(err error) {
+	bad := fmt.Sprintf("%s.corrupt-%d", f.filepath, time.Now().Unix())
+	if rerr := os.Rename(f.filepath, bad); rerr != nil {
+		bad = f.filepath
+	}
+	f.unreadable = fmt.Sprintf("fsm state unreadable (%v), kept at %s", err, bad)
+	log.Printf("[fsm] 🛑 %s — ARB/momentum caps and cooldowns unknown", f.unreadable)
+}
+
+// save persists caps and cooldowns of open markets. Caller holds f.mu.
+func (f *FSM) save() {
+	if f.filepath == "" {
+		return
+	}
+	f.expire(f.clock.Now())
+	saved := make(map[string]savedMarket, len(f.closesAt))
+	for cid, closes := range f.closesAt {
+		if f.momentumSpent[cid] == 0 && f.arbSpent[cid] == 0 && f.splitSpent[cid] == 0 {
+			continue // stepped but never traded: nothing to restore
+		}
+		saved[cid] = savedMarket{
+			ClosesAt:       closes,
+			LastMomentumTS: f.lastMomentumTS[cid],
+			MomentumSpent:  f.momentumSpent[cid],
+			LastArbTS:      f.lastArbTS[cid],
+			ArbSpent:       f.arbSpent[cid],
+			LastSplitTS:    f.lastSplitTS[cid],
+			SplitSpent:     f.splitSpent[cid],
+		}
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err == nil {
+		err = fileutil.WriteAtomic(f.filepath, data, 0600)
+	}
+	if err != nil {
+		log.Printf("[fsm] save error: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package fsm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+)
+
+// stateFile points config.FSMStateFile at a file in a temp dir for one test.
+func stateFile(t *testing.T) string {
+	t.Helper()
+	prev := config.FSMStateFile
+	t.Cleanup(func() { config.FSMStateFile = prev })
+	config.FSMStateFile = filepath.Join(t.TempDir(), "fsm_state.json")
+	return config.FSMStateFile
+}
+
+func TestMakerSpendPersisted(t *testing.T) {
+	stateFile(t)
+	// A maker fill for a market not stepped since the restart
+	New().RecordArbSpend(testCID, 12.5)
+
+	f := New()
+	if got := f.Status(testCID).ArbSpent; got != 12.5 {
+		t.Errorf("ARB spend after restart = $%.2f, want $12.50", got)
+	}
+	if reason := f.Unreadable(); reason != "" {
+		t.Errorf("unreadable = %q, want loaded", reason)
+	}
+}
+
+func TestUnreadableStateSetAside(t *testing.T) {
+	path := stateFile(t)
+	if err := os.WriteFile(path, []byte(`{"0xcond`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	f := New()
+	if reason := f.Unreadable(); !strings.Contains(reason, ".corrupt-") {
+		t.Errorf("unreadable = %q, want the file set aside", reason)
+	}
+	bad, _ := filepath.Glob(path + ".corrupt-*")
+	if len(bad) != 1 {
+		t.Fatalf("set aside files = %v, want one", bad)
+	}
+	if data, _ := os.ReadFile(bad[0]); string(data) != `{"0xcond` {
+		t.Errorf("set aside %q, want the original bytes", data)
+	}
+
+	// The next save starts a fresh file, which later loads fine
+	f.RecordArbSpend(testCID, 1)
+	if reason := New().Unreadable(); reason != "" {
+		t.Errorf("unreadable after a fresh save = %q", reason)
+	}
+}
@@ -0,0 +1,91 @@
+package fsm
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/book"
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+const testCID = "0xcondition0000000000000000000000000000000000000000000000000000000"
+
+// arbConfig sets the ARB sizing knobs for one test.
+func arbConfig(t *testing.T) {
+	t.Helper()
+	threshold, max, order := config.ARBThreshold, config.ARBMaxUSDC, config.ARBOrderUSDC
+	t.Cleanup(func() { config.ARBThreshold, config.ARBMaxUSDC, config.ARBOrderUSDC = threshold, max, order })
+	config.ARBThreshold, config.ARBMaxUSDC, config.ARBOrderUSDC = 0.98, 1000, 10
+}
+
+func TestStepArbRebalanceLimit(t *testing.T) {
+	arbConfig(t)
+	tests := []struct {
+		name       string
+		up, down   *book.Book
+		wantTokens float64
+		wantUSDC   float64
+		wantPrice  float64
+	}{
+		{
+			// 30 DOWN short: 10 @ 0.50 then 20 @ 0.52
+			name:       "depth: limit at the worst ask reached",
+			up:         asks(book.Level{Price: 0.45, Size: 100}),
+			down:       asks(book.Level{Price: 0.50, Size: 10}, book.Level{Price: 0.52, Size: 100}),
+			wantTokens: 30, wantUSDC: 15.4, wantPrice: 0.52,
+		},
+		{
+			name:       "no depth: ARB_ORDER_USDC at the quoted ask",
+			wantTokens: 20, wantUSDC: 10, wantPrice: 0.50,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := inventory.NewMemory()
+			inv.RecordBuy(testCID, "up", "down", "UP", 30, 13.5)
+			f := NewWithClock(clock.NewSim(time.Unix(0, 0)))
+			prices := &types.Prices{Up: 0.45, Down: 0.50, Spread: 0.95, State: types.StateARB, UpBook: tt.up, DownBook: tt.down}
+			if tt.up == nil {
+				prices.UpBook, prices.DownBook = nil, nil
+			}
+
+			_, a := f.Step(testCID, prices, inv, 30)
+			if a.Kind != types.ActionBuyArb || a.Side != "DOWN" {
+				t.Fatalf("action = %s %s (%s), want buy_arb DOWN", a.Kind, a.Side, a.Reason)
+			}
+			if math.Abs(a.Pairs-tt.wantTokens) > 1e-9 || math.Abs(a.ArbUSDC-tt.wantUSDC) > 1e-9 || a.ArbPrice != tt.wantPrice {
+				t.Errorf("buy %.4f tokens for $%.4f ≤ %.3f, want %.4f for $%.4f ≤ %.3f",
+					a.Pairs, a.ArbUSDC, a.ArbPrice, tt.wantTokens, tt.wantUSDC, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestStepArbPairLimits(t *testing.T) {
+	arbConfig(t)
+	// 20 pairs cost $19 (UP 10 @ 0.45 + 10 @ 0.47, DOWN 10 @ 0.48 + 10 @
+	// 0.50), then 0.60 on both legs: (19 + 1.2x) / (20 + x) < 0.98 ⇔ x < 2.73
+	up := asks(book.Level{Price: 0.45, Size: 10}, book.Level{Price: 0.47, Size: 10}, book.Level{Price: 0.60, Size: 100})
+	down := asks(book.Level{Price: 0.48, Size: 10}, book.Level{Price: 0.50, Size: 10}, book.Level{Price: 0.60, Size: 100})
+	f := NewWithClock(clock.NewSim(time.Unix(0, 0)))
+	prices := &types.Prices{Up: 0.45, Down: 0.48, Spread: 0.93, State: types.StateARB, UpBook: up, DownBook: down}
+
+	_, a := f.Step(testCID, prices, inventory.NewMemory(), 30)
+	if a.Kind != types.ActionBuyArbPair {
+		t.Fatalf("action = %s (%s), want buy_arb_pair", a.Kind, a.Reason)
+	}
+	if want := 20 + 0.6/0.22; math.Abs(a.Pairs-want) > 0.01 {
+		t.Errorf("pairs = %.3f, want %.3f", a.Pairs, want)
+	}
+	// Past 20 pairs both legs reach the 0.60 level
+	if a.UpPrice != 0.60 || a.DownPrice != 0.60 {
+		t.Errorf("limits UP %.3f / DOWN %.3f, want the worst asks reached (0.60 / 0.60)", a.UpPrice, a.DownPrice)
+	}
+	if want, _ := up.CostToBuy(a.Pairs); math.Abs(a.UpUSDC-want) > 1e-9 {
+		t.Errorf("UP leg $%.4f, want $%.4f", a.UpUSDC, want)
+	}
+}
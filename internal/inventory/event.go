@@ -0,0 +1,184 @@
+package inventory
+
+import (
+	"math"
+	"time"
+)
+
+// Event kinds.
+const (
+	evBuy       = "buy"
+	evSell      = "sell"
+	evMerge     = "merge"
+	evSplit     = "split"
+	evRedeem    = "redeem"
+	evFee       = "fee"
+	evCorrect   = "correct"   // booked buy/sell adjusted to the actual fill
+	evReconcile = "reconcile" // state rebuilt from trade history
+	evOnChain   = "onchain"   // balances overwritten from chain
+)
+
+// event is one change to the inventory.
+type event struct {
+	Seq         uint64                `json:"seq"`
+	TS          time.Time             `json:"ts"`
+	Kind        string                `json:"kind"`
+	ConditionID string                `json:"condition_id,omitempty"`
+	UpTokenID   string                `json:"up_token_id,omitempty"`
+	DownTokenID string                `json:"down_token_id,omitempty"`
+	Side        string                `json:"side,omitempty"`
+	OrderSide   string                `json:"order_side,omitempty"` // correct: BUY / SELL
+	Tokens      float64               `json:"tokens,omitempty"`
+	USDC        float64               `json:"usdc,omitempty"`
+	Markets     map[string]*Entry     `json:"markets,omitempty"`  // reconcile
+	Balances    map[string][2]float64 `json:"balances,omitempty"` // onchain
+}
+
+// apply changes state as ev describes and returns it (a reconcile replaces
+// the map). It must not log: it also runs for every event replayed at
+// startup.
+func apply(state map[string]*Entry, ev event) map[string]*Entry {
+	switch ev.Kind {
+	case evBuy:
+		e := ensure(state, ev.ConditionID, ev.UpTokenID, ev.DownTokenID)
+		if ev.Side == "UP" {
+			e.UpBalance += ev.Tokens
+			e.UpCost += ev.USDC
+		} else {
+			e.DownBalance += ev.Tokens
+			e.DownCost += ev.USDC
+		}
+		e.TotalInvested += ev.USDC
+
+	case evSell:
+		if e, ok := state[ev.ConditionID]; ok {
+			e.removeTokens(ev.Side, ev.Tokens)
+			e.TotalSold += ev.USDC
+		}
+
+	case evMerge:
+		if e, ok := state[ev.ConditionID]; ok {
+			mergeable := math.Min(ev.Tokens, math.Min(e.UpBalance, e.DownBalance))
+			e.removeTokens("UP", mergeable)
+			e.removeTokens("DOWN", mergeable)
+			e.TotalMerged += mergeable
+		}
+
+	case evSplit:
+		e := ensure(state, ev.ConditionID, ev.UpTokenID, ev.DownTokenID)
+		e.UpBalance += ev.Tokens
+		e.DownBalance += ev.Tokens
+		e.UpCost += ev.Tokens / 2
+		e.DownCost += ev.Tokens / 2
+		e.TotalInvested += ev.Tokens
+		e.TotalSplit += ev.Tokens
+
+	case evRedeem:
+		if e, ok := state[ev.ConditionID]; ok {
+			e.removeTokens("UP", e.UpBalance)
+			e.removeTokens("DOWN", e.DownBalance)
+			e.TotalRedeemed += ev.USDC
+			e.Redeemed = true
+		}
+
+	case evFee:
+		if e, ok := state[ev.ConditionID]; ok {
+			e.Fees += ev.USDC
+		}
+
+	case evCorrect:
+		// Tokens and USDC are what the fill differs from what was booked
+		// (negative when the order filled less or cheaper)
+		e := ensure(state, ev.ConditionID, ev.UpTokenID, ev.DownTokenID)
+		if ev.OrderSide == "SELL" {
+			bal := e.UpBalance
+			if ev.Side == "DOWN" {
+				bal = e.DownBalance
+			}
+			e.setBalance(ev.Side, math.Max(0, bal-ev.Tokens))
+			e.TotalSold += ev.USDC
+			break
+		}
+		bal, cost := &e.UpBalance, &e.UpCost
+		if ev.Side == "DOWN" {
+			bal, cost = &e.DownBalance, &e.DownCost
+		}
+		*bal = math.Max(0, *bal+ev.Tokens)
+		*cost = math.Max(0, *cost+ev.USDC)
+		e.TotalInvested += ev.USDC
+
+	case evReconcile:
+		return cloneState(ev.Markets)
+
+	case evOnChain:
+		for cid, onChain := range ev.Balances {
+			if e, ok := state[cid]; ok {
+				e.setBalance("UP", onChain[0])
+				e.setBalance("DOWN", onChain[1])
+			}
+		}
+	}
+	return state
+}
+
+// affected returns the conditions whose entries ev changed.
+func (ev event) affected() []string {
+	switch ev.Kind {
+	case evReconcile:
+		cids := make([]string, 0, len(ev.Markets))
+		for cid := range ev.Markets {
+			cids = append(cids, cid)
+		}
+		return cids
+	case evOnChain:
+		cids := make([]string, 0, len(ev.Balances))
+		for cid := range ev.Balances {
+			cids = append(cids, cid)
+		}
+		return cids
+	}
+	return []string{ev.ConditionID}
+}
+
+// ── Helpers ───────────────────────────────────────────────────────────────
+
+// ensure returns the entry of a condition, creating it if needed.
+func ensure(state map[string]*Entry, conditionID, upTokenID, downTokenID string) *Entry {
+	e, ok := state[conditionID]
+	if !ok {
+		e = &Entry{
+			UpTokenID:   upTokenID,
+			DownTokenID: downTokenID,
+		}
+		state[conditionID] = e
+	}
+	return e
+}
+
+// setBalance sets one side's balance. Tokens removed take their share of
+// the cost basis; tokens added keep the side's average cost (zero cost if
+// the side was empty).
+func (e *Entry) setBalance(side string, tokens float64) {
+	bal, cost := &e.UpBalance, &e.UpCost
+	if side == "DOWN" {
+		bal, cost = &e.DownBalance, &e.DownCost
+	}
+	switch {
+	case tokens < *bal:
+		e.removeTokens(side, *bal-tokens)
+	case tokens > *bal:
+		if *bal > 0 {
+			*cost *= tokens / *bal
+		}
+		*bal = tokens
+	}
+}
+
+func cloneState(in map[string]*Entry) map[string]*Entry {
+	out := make(map[string]*Entry, len(in))
+	for cid, e := range in {
+		c := *e
+		out[cid] = &c
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package inventory
+
+import "testing"
+
+func TestApply(t *testing.T) {
+	long := func() *Entry {
+		return &Entry{UpTokenID: "up", DownTokenID: "down",
+			UpBalance: 10, UpCost: 4, DownBalance: 6, DownCost: 3, TotalInvested: 7}
+	}
+	tests := []struct {
+		name  string
+		start *Entry // nil = no entry yet
+		ev    event
+		want  *Entry // nil = still no entry
+	}{
+		{
+			name: "buy opens an entry",
+			ev:   event{Kind: evBuy, ConditionID: testCID, UpTokenID: "up", DownTokenID: "down", Side: "UP", Tokens: 10, USDC: 4},
+			want: &Entry{UpBalance: 10, UpCost: 4, TotalInvested: 4},
+		},
+		{
+			name:  "sell takes its share of the cost",
+			start: long(),
+			ev:    event{Kind: evSell, ConditionID: testCID, Side: "UP", Tokens: 5, USDC: 2.5},
+			want:  &Entry{UpBalance: 5, UpCost: 2, DownBalance: 6, DownCost: 3, TotalInvested: 7, TotalSold: 2.5},
+		},
+		{
+			name: "sell of an unknown market is ignored",
+			ev:   event{Kind: evSell, ConditionID: testCID, Side: "UP", Tokens: 5, USDC: 2.5},
+		},
+		{
+			name:  "merge capped by the smaller side",
+			start: long(),
+			ev:    event{Kind: evMerge, ConditionID: testCID, Tokens: 8},
+			want:  &Entry{UpBalance: 4, UpCost: 1.6, TotalInvested: 7, TotalMerged: 6},
+		},
+		{
+			name: "split adds pairs at $0.50 a side",
+			ev:   event{Kind: evSplit, ConditionID: testCID, UpTokenID: "up", DownTokenID: "down", Tokens: 10},
+			want: &Entry{UpBalance: 10, UpCost: 5, DownBalance: 10, DownCost: 5, TotalInvested: 10, TotalSplit: 10},
+		},
+		{
+			name:  "redeem clears both sides",
+			start: long(),
+			ev:    event{Kind: evRedeem, ConditionID: testCID, USDC: 10},
+			want:  &Entry{TotalInvested: 7, TotalRedeemed: 10, Redeemed: true},
+		},
+		{
+			name:  "buy correction: filled less and cheaper",
+			start: long(),
+			ev:    event{Kind: evCorrect, ConditionID: testCID, Side: "UP", OrderSide: "BUY", Tokens: -2, USDC: -1},
+			want:  &Entry{UpBalance: 8, UpCost: 3, DownBalance: 6, DownCost: 3, TotalInvested: 6},
+		},
+		{
+			name:  "buy correction never goes negative",
+			start: long(),
+			ev:    event{Kind: evCorrect, ConditionID: testCID, Side: "DOWN", OrderSide: "BUY", Tokens: -9, USDC: -5},
+			want:  &Entry{UpBalance: 10, UpCost: 4, TotalInvested: 2},
+		},
+		{
+			name:  "sell correction: sold more",
+			start: long(),
+			ev:    event{Kind: evCorrect, ConditionID: testCID, Side: "UP", OrderSide: "SELL", Tokens: 2, USDC: 0.9},
+			want:  &Entry{UpBalance: 8, UpCost: 3.2, DownBalance: 6, DownCost: 3, TotalInvested: 7, TotalSold: 0.9},
+		},
+		{
+			name:  "on-chain balances keep the average cost",
+			start: long(),
+			ev:    event{Kind: evOnChain, Balances: map[string][2]float64{testCID: {5, 12}}},
+			want:  &Entry{UpBalance: 5, UpCost: 2, DownBalance: 12, DownCost: 6, TotalInvested: 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := map[string]*Entry{}
+			if tt.start != nil {
+				state[testCID] = tt.start
+			}
+			state = apply(state, tt.ev)
+			e, ok := state[testCID]
+			if ok != (tt.want != nil) {
+				t.Fatalf("entry present = %v, want %v", ok, tt.want != nil)
+			}
+			if !ok {
+				return
+			}
+			check(t, "UpBalance", e.UpBalance, tt.want.UpBalance)
+			check(t, "UpCost", e.UpCost, tt.want.UpCost)
+			check(t, "DownBalance", e.DownBalance, tt.want.DownBalance)
+			check(t, "DownCost", e.DownCost, tt.want.DownCost)
+			check(t, "TotalInvested", e.TotalInvested, tt.want.TotalInvested)
+			check(t, "TotalSold", e.TotalSold, tt.want.TotalSold)
+			check(t, "TotalMerged", e.TotalMerged, tt.want.TotalMerged)
+			check(t, "TotalSplit", e.TotalSplit, tt.want.TotalSplit)
+			check(t, "TotalRedeemed", e.TotalRedeemed, tt.want.TotalRedeemed)
+			if e.Redeemed != tt.want.Redeemed {
+				t.Errorf("Redeemed = %v, want %v", e.Redeemed, tt.want.Redeemed)
+			}
+		})
+	}
+}
+
+func TestSetBalance(t *testing.T) {
+	tests := []struct {
+		name              string
+		balance, cost     float64
+		set               float64
+		wantBal, wantCost float64
+	}{
+		{name: "unchanged", balance: 10, cost: 4, set: 10, wantBal: 10, wantCost: 4},
+		{name: "lower removes cost pro rata", balance: 10, cost: 4, set: 2.5, wantBal: 2.5, wantCost: 1},
+		{name: "to zero clears the cost", balance: 10, cost: 4, set: 0, wantBal: 0, wantCost: 0},
+		{name: "higher keeps the average", balance: 10, cost: 4, set: 15, wantBal: 15, wantCost: 6},
+		{name: "from empty at zero cost", set: 3, wantBal: 3, wantCost: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, side := range []string{"UP", "DOWN"} {
+				e := &Entry{}
+				if side == "UP" {
+					e.UpBalance, e.UpCost = tt.balance, tt.cost
+				} else {
+					e.DownBalance, e.DownCost = tt.balance, tt.cost
+				}
+				e.setBalance(side, tt.set)
+				bal, cost, other := e.UpBalance, e.UpCost, e.DownBalance+e.DownCost
+				if side == "DOWN" {
+					bal, cost, other = e.DownBalance, e.DownCost, e.UpBalance+e.UpCost
+				}
+				check(t, side+" balance", bal, tt.wantBal)
+				check(t, side+" cost", cost, tt.wantCost)
+				check(t, side+" other side", other, 0)
+			}
+		})
+	}
+}
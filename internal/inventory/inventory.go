@@ -1,20 +1,25 @@
 // Package inventory tracks token holdings per market condition.
-// Persists state to a JSON file so inventory survives restarts.
+// Every change is an event (event.go) applied to the in-memory state and
+// persisted, so inventory survives restarts: as a JSON snapshot plus an
+// append-only journal (journal.go), or in SQLite next to the rest of the
+// bot's history (sqlite.go).
 // Mirror of Python inventory.py.
 package inventory
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
 	"math"
-	"os"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gipsh/polymarket-bot-go/internal/clob"
 	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/store"
+	"github.com/gipsh/polymarket-bot-go/internal/trades"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
 )
 
 const reconcileInterval = 120 * time.Second // max 1 reconcile per 2 minutes
@@ -27,30 +32,130 @@ type Entry struct {
 	DownBalance   float64 `json:"down_balance"`
 	TotalInvested float64 `json:"total_invested_usdc"`
 	TotalMerged   float64 `json:"total_merged_usdc"`
+	TotalSplit    float64 `json:"total_split_usdc,omitempty"` // USDC split into pairs (part of TotalInvested)
+	TotalSold     float64 `json:"total_sold_usdc,omitempty"`
+	TotalRedeemed float64 `json:"total_redeemed_usdc,omitempty"`
+	Fees          float64 `json:"fees_usdc,omitempty"`
+	Redeemed      bool    `json:"redeemed,omitempty"` // redeemPositions burned every token
+
+	// Cost basis (average cost) of the tokens still held on each side
+	UpCost   float64 `json:"up_cost_usdc,omitempty"`
+	DownCost float64 `json:"down_cost_usdc,omitempty"`
+}
+
+// Realized returns the P&L already locked in: merge, sell and redemption
+// proceeds minus the cost of the tokens given up for them, minus fees.
+func (e Entry) Realized() float64 {
+	disposedCost := e.TotalInvested - e.UpCost - e.DownCost
+	return e.TotalMerged + e.TotalSold + e.TotalRedeemed - disposedCost - e.Fees
+}
+
+// removeTokens takes tokens off one side at average cost and returns the
+// cost basis removed.
+func (e *Entry) removeTokens(side string, tokens float64) float64 {
+	bal, cost := &e.UpBalance, &e.UpCost
+	if side == "DOWN" {
+		bal, cost = &e.DownBalance, &e.DownCost
+	}
+	tokens = math.Min(tokens, *bal)
+	removed := 0.0
+	if *bal > 0 {
+		removed = *cost * tokens / *bal
+	}
+	*bal -= tokens
+	*cost -= removed
+	if *bal <= 0 {
+		*bal, *cost = 0, 0
+	}
+	return removed
 }
 
-// Inventory tracks all condition→token holdings.
-type Inventory struct {
+// Inventory tracks all condition→token holdings. Implementations differ
+// only in where the changes are persisted: New (JSON files), NewSQLite or
+// NewMemory (nowhere).
+type Inventory interface {
+	// Reads
+	GetBalance(conditionID, side string) float64
+	GetMergeablePairs(conditionID string) float64
+	GetImbalance(conditionID string) (string, float64)
+	Get(conditionID string) (Entry, bool)
+	Snapshot() map[string]Entry
+	Summary(conditionID string) string
+
+	// Writes
+	RecordBuy(conditionID, upTokenID, downTokenID, side string, tokens, usdc float64)
+	RecordSell(conditionID, side string, tokens, usdc float64)
+	RecordMerge(conditionID string, pairs float64)
+	RecordSplit(conditionID, upTokenID, downTokenID string, pairs float64)
+	RecordRedeem(conditionID string, usdc float64)
+	RecordFee(conditionID string, usdc float64)
+	RecordCorrection(conditionID, upTokenID, downTokenID, side string, orderSide types.OrderSide, tokens, usdc float64)
+	ReconcileFromAPI(ctx context.Context, client *clob.Client, force bool) (int, error)
+	ReconcileOnChain(balances map[string][2]float64) int
+
+	// Flush persists everything still buffered. Called on shutdown.
+	Flush() error
+}
+
+// persister saves inventory events and loads the state they built.
+type persister interface {
+	// load returns the saved state and the sequence number of its last event.
+	load() (map[string]*Entry, uint64, error)
+	// write persists ev, already applied to state.
+	write(ev event, state map[string]*Entry) error
+	// flush persists state as of event seq in one piece.
+	flush(seq uint64, state map[string]*Entry) error
+}
+
+// tracker implements Inventory for every backend: the state lives in
+// memory and each change is handed to the persister.
+type tracker struct {
 	mu            sync.Mutex
-	filepath      string
+	backend       persister         // nil = not persisted
+	seq           uint64            // last event applied
 	state         map[string]*Entry // conditionID → Entry
+	trades        *trades.Store     // CLOB trade history reconcile rebuilds from
 	lastReconcile time.Time
 }
 
-// New creates an inventory backed by the configured file.
-func New() *Inventory {
-	inv := &Inventory{
-		filepath: config.InventoryFile,
-		state:    make(map[string]*Entry),
-	}
-	inv.load()
+// New creates an inventory backed by the configured JSON snapshot and
+// journal. It fails if neither the snapshot nor the previous one with the
+// journals can rebuild the saved state.
+func New() (Inventory, error) {
+	return newTracker(&jsonStore{
+		filepath:    config.InventoryFile,
+		journalFile: config.InventoryJournalFile,
+	})
+}
+
+// NewSQLite creates an inventory kept in the inventory tables of db.
+func NewSQLite(db *store.DB) Inventory {
+	inv, _ := newTracker(&sqliteStore{db: db.SQL()}) // SQLite load errors are logged, never returned
 	return inv
 }
 
+// NewMemory creates an inventory that is never persisted to disk.
+// Used by the backtester so simulated fills don't touch live state.
+func NewMemory() Inventory {
+	return &tracker{
+		state:  make(map[string]*Entry),
+		trades: trades.NewMemory(),
+	}
+}
+
+func newTracker(p persister) (*tracker, error) {
+	inv := &tracker{backend: p, trades: trades.New()}
+	var err error
+	if inv.state, inv.seq, err = p.load(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
 // ── Reads ─────────────────────────────────────────────────────────────────
 
 // GetBalance returns the token balance for a side ("UP" or "DOWN").
-func (inv *Inventory) GetBalance(conditionID, side string) float64 {
+func (inv *tracker) GetBalance(conditionID, side string) float64 {
 	inv.mu.Lock()
 	defer inv.mu.Unlock()
 	e, ok := inv.state[conditionID]
@@ -64,7 +169,7 @@ func (inv *Inventory) GetBalance(conditionID, side string) float64 {
 }
 
 // GetMergeablePairs returns the number of UP+DOWN pairs that can be merged.
-func (inv *Inventory) GetMergeablePairs(conditionID string) float64 {
+func (inv *tracker) GetMergeablePairs(conditionID string) float64 {
 	inv.mu.Lock()
 	defer inv.mu.Unlock()
 	e, ok := inv.state[conditionID]
@@ -75,7 +180,7 @@ func (inv *Inventory) GetMergeablePairs(conditionID string) float64 {
 }
 
 // GetImbalance returns (excessSide, excessAmount) to guide arb rebalancing.
-func (inv *Inventory) GetImbalance(conditionID string) (string, float64) {
+func (inv *tracker) GetImbalance(conditionID string) (string, float64) {
 	inv.mu.Lock()
 	defer inv.mu.Unlock()
 	e, ok := inv.state[conditionID]
@@ -88,8 +193,30 @@ func (inv *Inventory) GetImbalance(conditionID string) (string, float64) {
 	return "UP", e.DownBalance - e.UpBalance
 }
 
+// Get returns a copy of a condition's entry.
+func (inv *tracker) Get(conditionID string) (Entry, bool) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	e, ok := inv.state[conditionID]
+	if !ok {
+		return Entry{}, false
+	}
+	return *e, true
+}
+
+// Snapshot returns a copy of every tracked condition's entry.
+func (inv *tracker) Snapshot() map[string]Entry {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	out := make(map[string]Entry, len(inv.state))
+	for id, e := range inv.state {
+		out[id] = *e
+	}
+	return out
+}
+
 // Summary returns a human-readable state string for a condition.
-func (inv *Inventory) Summary(conditionID string) string {
+func (inv *tracker) Summary(conditionID string) string {
 	inv.mu.Lock()
 	defer inv.mu.Unlock()
 	e, ok := inv.state[conditionID]
@@ -97,31 +224,42 @@ func (inv *Inventory) Summary(conditionID string) string {
 		return fmt.Sprintf("[%s...] No inventory", conditionID[:8])
 	}
 	pairs := math.Min(e.UpBalance, e.DownBalance)
-	return fmt.Sprintf("[%s...] UP=%.2f DOWN=%.2f | Pairs=%.2f | Invested=$%.2f | Merged=$%.2f",
-		conditionID[:8], e.UpBalance, e.DownBalance, pairs, e.TotalInvested, e.TotalMerged)
+	sold := ""
+	if e.TotalSold > 0 {
+		sold = fmt.Sprintf(" | Sold=$%.2f", e.TotalSold)
+	}
+	return fmt.Sprintf("[%s...] UP=%.2f DOWN=%.2f | Pairs=%.2f | Invested=$%.2f | Merged=$%.2f%s",
+		conditionID[:8], e.UpBalance, e.DownBalance, pairs, e.TotalInvested, e.TotalMerged, sold)
 }
 
 // ── Writes ────────────────────────────────────────────────────────────────
 
 // RecordBuy records a completed buy order.
-func (inv *Inventory) RecordBuy(conditionID, upTokenID, downTokenID, side string, tokens, usdc float64) {
+func (inv *tracker) RecordBuy(conditionID, upTokenID, downTokenID, side string, tokens, usdc float64) {
 	inv.mu.Lock()
 	defer inv.mu.Unlock()
-	inv.ensure(conditionID, upTokenID, downTokenID)
+	inv.record(event{Kind: evBuy, ConditionID: conditionID, UpTokenID: upTokenID, DownTokenID: downTokenID,
+		Side: side, Tokens: tokens, USDC: usdc})
 	e := inv.state[conditionID]
-	if side == "UP" {
-		e.UpBalance += tokens
-	} else {
-		e.DownBalance += tokens
-	}
-	e.TotalInvested += usdc
-	inv.save()
 	log.Printf("[inventory] [%s...] +%.2f %s | UP=%.2f DOWN=%.2f",
 		conditionID[:8], tokens, side, e.UpBalance, e.DownBalance)
 }
 
+// RecordSell records a completed sell order, removing the sold tokens.
+func (inv *tracker) RecordSell(conditionID, side string, tokens, usdc float64) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	e, ok := inv.state[conditionID]
+	if !ok {
+		return
+	}
+	inv.record(event{Kind: evSell, ConditionID: conditionID, Side: side, Tokens: tokens, USDC: usdc})
+	log.Printf("[inventory] [%s...] -%.2f %s (+$%.2f) | UP=%.2f DOWN=%.2f",
+		conditionID[:8], tokens, side, usdc, e.UpBalance, e.DownBalance)
+}
+
 // RecordMerge records a MERGE operation, removing matched pairs.
-func (inv *Inventory) RecordMerge(conditionID string, pairs float64) {
+func (inv *tracker) RecordMerge(conditionID string, pairs float64) {
 	inv.mu.Lock()
 	defer inv.mu.Unlock()
 	e, ok := inv.state[conditionID]
@@ -129,19 +267,66 @@ func (inv *Inventory) RecordMerge(conditionID string, pairs float64) {
 		return
 	}
 	mergeable := math.Min(pairs, math.Min(e.UpBalance, e.DownBalance))
-	e.UpBalance -= mergeable
-	e.DownBalance -= mergeable
-	e.TotalMerged += mergeable
-	inv.save()
+	inv.record(event{Kind: evMerge, ConditionID: conditionID, Tokens: pairs})
 	log.Printf("[inventory] MERGE [%s...]: %.2f pairs → +$%.2f USDC",
 		conditionID[:8], mergeable, mergeable)
 }
 
+// RecordSplit records a splitPosition: pairs USDC turned into pairs UP and
+// pairs DOWN tokens. The cost basis is split evenly between the sides.
+func (inv *tracker) RecordSplit(conditionID, upTokenID, downTokenID string, pairs float64) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	inv.record(event{Kind: evSplit, ConditionID: conditionID, UpTokenID: upTokenID, DownTokenID: downTokenID,
+		Tokens: pairs})
+	log.Printf("[inventory] SPLIT [%s...]: $%.4f USDC → %.4f pairs", conditionID[:8], pairs, pairs)
+}
+
+// RecordRedeem records a redeemPositions payout. Redeeming burns every
+// token of the condition (losers pay nothing), so both sides go to zero.
+func (inv *tracker) RecordRedeem(conditionID string, usdc float64) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if _, ok := inv.state[conditionID]; !ok {
+		return
+	}
+	inv.record(event{Kind: evRedeem, ConditionID: conditionID, USDC: usdc})
+	log.Printf("[inventory] REDEEM [%s...]: +$%.4f USDC", conditionID[:8], usdc)
+}
+
+// RecordFee records a trading fee paid in a market.
+func (inv *tracker) RecordFee(conditionID string, usdc float64) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if _, ok := inv.state[conditionID]; !ok || usdc <= 0 {
+		return
+	}
+	inv.record(event{Kind: evFee, ConditionID: conditionID, USDC: usdc})
+}
+
+// RecordCorrection adjusts a buy or sell already recorded by the difference
+// between its actual fill and what was booked: tokens and usdc are actual
+// minus booked. A buy correction moves the balance and cost basis; a sell
+// correction moves the balance at average cost and the proceeds.
+func (inv *tracker) RecordCorrection(conditionID, upTokenID, downTokenID, side string, orderSide types.OrderSide, tokens, usdc float64) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if tokens == 0 && usdc == 0 {
+		return
+	}
+	inv.record(event{Kind: evCorrect, ConditionID: conditionID, UpTokenID: upTokenID, DownTokenID: downTokenID,
+		Side: side, OrderSide: orderSide.String(), Tokens: tokens, USDC: usdc})
+	e := inv.state[conditionID]
+	log.Printf("[inventory] [%s...] %s %s corrected by %+.2f tokens / %+.2f USDC | UP=%.2f DOWN=%.2f",
+		conditionID[:8], orderSide, side, tokens, usdc, e.UpBalance, e.DownBalance)
+}
+
 // ── Reconcile from API ────────────────────────────────────────────────────
 
-// ReconcileFromAPI rebuilds inventory from CLOB trade history.
+// ReconcileFromAPI syncs the local trade store with the CLOB and rebuilds
+// inventory from the complete history.
 // Rate-limited to once per reconcileInterval unless force=true.
-func (inv *Inventory) ReconcileFromAPI(client *clob.Client, force bool) (int, error) {
+func (inv *tracker) ReconcileFromAPI(ctx context.Context, client *clob.Client, force bool) (int, error) {
 	inv.mu.Lock()
 	if !force && time.Since(inv.lastReconcile) < reconcileInterval {
 		inv.mu.Unlock()
@@ -150,109 +335,210 @@ func (inv *Inventory) ReconcileFromAPI(client *clob.Client, force bool) (int, er
 	inv.lastReconcile = time.Now()
 	inv.mu.Unlock()
 
-	trades, err := client.GetTrades("")
-	if err != nil {
+	if _, err := inv.trades.Sync(ctx, client); err != nil {
 		return 0, err
 	}
-	if len(trades) == 0 {
+	history := inv.trades.All()
+	if len(history) == 0 {
 		log.Println("[inventory] reconcile: no trades — inventory unchanged")
 		return 0, nil
 	}
 
-	// Rebuild per-condition balances from confirmed/matched BUY trades
+	apiKey, maker := client.Identity()
+	inv.mu.Lock()
+	newState := rebuild(history, inv.state, apiKey, maker)
+	inv.record(event{Kind: evReconcile, Markets: newState})
+	inv.mu.Unlock()
+
+	log.Printf("[inventory] reconciled: %d markets, %d trades", len(newState), len(history))
+	for cid, e := range newState {
+		log.Printf("  [%s...] UP=%.2f DOWN=%.2f pairs=%.2f",
+			cid[:8], e.UpBalance, e.DownBalance, math.Min(e.UpBalance, e.DownBalance))
+	}
+	return len(newState), nil
+}
+
+// rebuild derives per-condition inventory from the trade history (our
+// legs of confirmed/matched BUY and SELL trades, identified by apiKey and
+// maker). What trade history cannot show is carried over from current:
+// split pairs, merges and redemptions. Tokens left are valued at the
+// average buy price of each side.
+func rebuild(history []clob.Trade, current map[string]*Entry, apiKey, maker string) map[string]*Entry {
 	newState := make(map[string]*Entry)
-	for _, t := range trades {
-		if t.Market == "" {
-			continue
+	bought := make(map[string]*[2]float64) // "cid/side" → {tokens, usdc} bought
+	addBought := func(cid, side string, tokens, usdc float64) {
+		key := cid + "/" + side
+		if bought[key] == nil {
+			bought[key] = &[2]float64{}
 		}
-		if t.Status != "CONFIRMED" && t.Status != "MATCHED" {
+		bought[key][0] += tokens
+		bought[key][1] += usdc
+	}
+
+	// Splits never appear in trade history: carry the pairs minted over,
+	// at $0.50 per token on each side
+	for cid, existing := range current {
+		if existing.TotalSplit <= 0 {
 			continue
 		}
-		if t.Side != "BUY" {
-			continue
+		newState[cid] = &Entry{
+			UpTokenID:     existing.UpTokenID,
+			DownTokenID:   existing.DownTokenID,
+			UpBalance:     existing.TotalSplit,
+			DownBalance:   existing.TotalSplit,
+			TotalInvested: existing.TotalSplit,
+			TotalSplit:    existing.TotalSplit,
 		}
-		size := parseFloatStr(t.Size)
-		if size == 0 {
+		addBought(cid, "UP", existing.TotalSplit, existing.TotalSplit/2)
+		addBought(cid, "DOWN", existing.TotalSplit, existing.TotalSplit/2)
+	}
+
+	for _, t := range history {
+		if t.Market == "" {
 			continue
 		}
-		if _, ok := newState[t.Market]; !ok {
-			newState[t.Market] = &Entry{}
-		}
-		e := newState[t.Market]
-		price := parseFloatStr(t.Price)
-		if price == 0 {
-			price = 1.0
+		if t.Status != "CONFIRMED" && t.Status != "MATCHED" {
+			continue
 		}
-		e.TotalInvested += size * price
-
-		switch t.Outcome {
-		case "UP", "YES":
-			e.UpBalance += size
-			e.UpTokenID = t.AssetID
-		case "DOWN", "NO":
-			e.DownBalance += size
-			e.DownTokenID = t.AssetID
+		for _, leg := range t.Legs(apiKey, maker) {
+			if leg.Side != "BUY" && leg.Side != "SELL" {
+				continue
+			}
+			size := parseFloatStr(leg.Size)
+			if size == 0 {
+				continue
+			}
+			if _, ok := newState[t.Market]; !ok {
+				newState[t.Market] = &Entry{}
+			}
+			e := newState[t.Market]
+			price := parseFloatStr(leg.Price)
+			if price == 0 {
+				price = 1.0
+			}
+			// CLOB fee: rate × min(price, 1-price) × size
+			if bps := parseFloatStr(leg.FeeRateBps); bps > 0 {
+				e.Fees += bps / 1e4 * math.Min(price, 1-price) * size
+			}
+
+			side := ""
+			switch leg.Outcome {
+			case "UP", "YES":
+				side = "UP"
+				e.UpTokenID = leg.AssetID
+			case "DOWN", "NO":
+				side = "DOWN"
+				e.DownTokenID = leg.AssetID
+			}
+
+			if leg.Side == "SELL" {
+				e.TotalSold += size * price
+				size = -size
+			} else {
+				e.TotalInvested += size * price
+				addBought(t.Market, side, size, size*price)
+			}
+
+			switch side {
+			case "UP":
+				e.UpBalance += size
+			case "DOWN":
+				e.DownBalance += size
+			}
 		}
 	}
 
-	// Subtract already-merged amounts from existing state
-	inv.mu.Lock()
+	// Subtract already-merged (and redeemed) amounts from existing state,
+	// then value what is left at the average buy price of each side
 	for cid, entry := range newState {
-		if existing, ok := inv.state[cid]; ok {
+		if existing, ok := current[cid]; ok {
 			entry.TotalMerged = existing.TotalMerged
-			entry.UpBalance = math.Max(0, entry.UpBalance-entry.TotalMerged)
-			entry.DownBalance = math.Max(0, entry.DownBalance-entry.TotalMerged)
+			entry.TotalRedeemed = existing.TotalRedeemed
+			entry.Redeemed = existing.Redeemed
+		}
+		entry.UpBalance = math.Max(0, entry.UpBalance-entry.TotalMerged)
+		entry.DownBalance = math.Max(0, entry.DownBalance-entry.TotalMerged)
+		if entry.Redeemed || entry.TotalRedeemed > 0 {
+			entry.UpBalance, entry.DownBalance = 0, 0 // redeeming burns every token
+		}
+		if b := bought[cid+"/UP"]; b != nil && b[0] > 0 {
+			entry.UpCost = entry.UpBalance * b[1] / b[0]
+		}
+		if b := bought[cid+"/DOWN"]; b != nil && b[0] > 0 {
+			entry.DownCost = entry.DownBalance * b[1] / b[0]
 		}
 	}
-	inv.state = newState
-	inv.save()
-	inv.mu.Unlock()
-
-	log.Printf("[inventory] reconciled: %d markets, %d trades", len(newState), len(trades))
-	for cid, e := range newState {
-		log.Printf("  [%s...] UP=%.2f DOWN=%.2f pairs=%.2f",
-			cid[:8], e.UpBalance, e.DownBalance, math.Min(e.UpBalance, e.DownBalance))
-	}
-	return len(newState), nil
+	return newState
 }
 
-// ── Persistence ───────────────────────────────────────────────────────────
+// ── Reconcile from chain ──────────────────────────────────────────────────
 
-func (inv *Inventory) ensure(conditionID, upTokenID, downTokenID string) {
-	if _, ok := inv.state[conditionID]; !ok {
-		inv.state[conditionID] = &Entry{
-			UpTokenID:   upTokenID,
-			DownTokenID: downTokenID,
+// onChainTolerance is the balance difference (tokens) below which the
+// on-chain and tracked views are considered equal.
+const onChainTolerance = 0.0001
+
+// ReconcileOnChain overwrites the tracked UP/DOWN balances with the Safe's
+// on-chain ERC-1155 balances (conditionID → {UP, DOWN}), logging every
+// difference from the trade-derived view. Tokens that disappeared take
+// their share of the cost basis with them; tokens that appeared keep the
+// side's average cost (zero cost if the side was empty). Conditions
+// missing from balances are left alone. Returns the number of
+// discrepancies corrected.
+func (inv *tracker) ReconcileOnChain(balances map[string][2]float64) int {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	fixed := 0
+	for cid, onChain := range balances {
+		e, ok := inv.state[cid]
+		if !ok {
+			continue
 		}
+		for i, side := range []string{"UP", "DOWN"} {
+			bal := e.UpBalance
+			if side == "DOWN" {
+				bal = e.DownBalance
+			}
+			diff := onChain[i] - bal
+			if math.Abs(diff) < onChainTolerance {
+				continue
+			}
+			log.Printf("[inventory] on-chain [%s...] %s: tracked %.4f, on-chain %.4f (%+.4f)",
+				cid[:8], side, bal, onChain[i], diff)
+			fixed++
+		}
+	}
+	if fixed > 0 {
+		inv.record(event{Kind: evOnChain, Balances: balances})
 	}
+	return fixed
 }
 
-func (inv *Inventory) load() {
-	if _, err := os.Stat(inv.filepath); os.IsNotExist(err) {
-		return
-	}
-	data, err := os.ReadFile(inv.filepath)
-	if err != nil {
-		log.Printf("[inventory] load error: %v — starting fresh", err)
+// ── Persistence ───────────────────────────────────────────────────────────
+
+// record applies ev and persists it. Caller holds inv.mu.
+func (inv *tracker) record(ev event) {
+	ev.Seq = inv.seq + 1
+	ev.TS = time.Now().UTC()
+	inv.state = apply(inv.state, ev)
+	inv.seq = ev.Seq
+	if inv.backend == nil {
 		return
 	}
-	if err := json.Unmarshal(data, &inv.state); err != nil {
-		log.Printf("[inventory] parse error: %v — starting fresh", err)
-		inv.state = make(map[string]*Entry)
-		return
+	if err := inv.backend.write(ev, inv.state); err != nil {
+		log.Printf("[inventory] save error: %v", err)
 	}
-	log.Printf("[inventory] loaded: %d markets tracked", len(inv.state))
 }
 
-func (inv *Inventory) save() {
-	data, err := json.MarshalIndent(inv.state, "", "  ")
-	if err != nil {
-		log.Printf("[inventory] marshal error: %v", err)
-		return
-	}
-	if err := os.WriteFile(inv.filepath, data, 0600); err != nil {
-		log.Printf("[inventory] save error: %v", err)
+// Flush persists the whole state in one piece (a JSON snapshot, emptying
+// the journal). Called on shutdown so the next start loads one file
+// instead of replaying events.
+func (inv *tracker) Flush() error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if inv.backend == nil {
+		return nil
 	}
+	return inv.backend.flush(inv.seq, inv.state)
 }
 
 // ── Helpers ───────────────────────────────────────────────────────────────
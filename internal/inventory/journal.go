@@ -0,0 +1,257 @@
+package inventory
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/fileutil"
+)
+
+// The JSON backend is a snapshot plus a journal:
+//
+//   - every event is appended (and fsynced) to the journal before the
+//     call returns;
+//   - every compactEvery events, and on Flush, the state is written as a
+//     snapshot (temp file + rename, so a crash leaves the old or the new
+//     snapshot, never half of one) and the journal starts over;
+//   - the snapshot replaced is kept as *.prev and the journal it started
+//     as *.journal.prev, so an unreadable snapshot is rolled forward from
+//     the previous one instead of losing the events compacted into it;
+//   - on startup the snapshot (or *.prev) is loaded and the journal events
+//     after its sequence number are replayed. They must follow it without a
+//     gap: if neither snapshot and the journals rebuild the state, loading
+//     fails rather than starting from a partial inventory.
+//
+// A crash mid-append can only cut the last journal line short; that line
+// is skipped on replay.
+
+// compactEvery is how many journal events trigger a new snapshot.
+const compactEvery = 500
+
+// snapshot is the on-disk form of the state at journal position Seq.
+type snapshot struct {
+	Seq     uint64            `json:"seq"`
+	Markets map[string]*Entry `json:"markets"`
+}
+
+// jsonStore persists inventory to a JSON snapshot and journal.
+type jsonStore struct {
+	filepath    string // snapshot
+	journalFile string // journal ("" = filepath + ".journal")
+	journaled   int    // events in the journal since the snapshot
+}
+
+// load restores the snapshot, or the previous one if it is unreadable, and
+// replays the journal events after it.
+func (j *jsonStore) load() (map[string]*Entry, uint64, error) {
+	state, seq, err := j.loadSnapshot(j.filepath)
+	lost, recovered := false, false
+	if err != nil {
+		if lost = !errors.Is(err, os.ErrNotExist); lost {
+			bad := fmt.Sprintf("%s.corrupt-%d", j.filepath, time.Now().Unix())
+			os.Rename(j.filepath, bad)
+			log.Printf("[inventory] ⚠ snapshot unreadable (%v) — moved to %s, recovering from %s",
+				err, bad, j.prevPath())
+		}
+		// Also reached after a crash between keeping *.prev and writing
+		// the new snapshot
+		state, seq, err = j.loadSnapshot(j.prevPath())
+		switch {
+		case err == nil:
+			recovered = true
+		case errors.Is(err, os.ErrNotExist):
+			state, seq = make(map[string]*Entry), 0 // first start, or the journal holds every event
+		default:
+			return nil, 0, fmt.Errorf("snapshot and %s unreadable: %w", j.prevPath(), err)
+		}
+	}
+	state, seq, replayed, err := j.replayJournal(state, seq)
+	if err != nil {
+		return nil, 0, err
+	}
+	if lost && !recovered && replayed == 0 {
+		return nil, 0, fmt.Errorf("snapshot unreadable and no %s or journal to rebuild it from", j.prevPath())
+	}
+	log.Printf("[inventory] loaded: %d markets tracked (seq %d, %d journal events replayed)",
+		len(state), seq, replayed)
+	// Start from a fresh snapshot: the journal may end in a torn line that
+	// the next append would run into
+	if j.journaled > 0 || recovered {
+		if err := j.flush(seq, state); err != nil {
+			log.Printf("[inventory] compaction error: %v", err)
+		}
+	}
+	return state, seq, nil
+}
+
+// write journals ev and compacts when due.
+func (j *jsonStore) write(ev event, state map[string]*Entry) error {
+	err := j.appendJournal(ev)
+	if err != nil {
+		err = fmt.Errorf("journal: %w — writing a snapshot instead", err)
+		j.journaled = compactEvery
+	} else {
+		j.journaled++
+	}
+	if j.journaled >= compactEvery {
+		if cerr := j.flush(ev.Seq, state); cerr != nil {
+			return fmt.Errorf("compaction: %w", cerr)
+		}
+	}
+	return err
+}
+
+// flush writes a snapshot and, once it is in place, starts a new journal.
+// The snapshot it replaces becomes *.prev and the journal *.journal.prev:
+// together they rebuild the new snapshot if it cannot be read back.
+func (j *jsonStore) flush(seq uint64, state map[string]*Entry) error {
+	data, err := json.MarshalIndent(snapshot{Seq: seq, Markets: state}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal snapshot: %w", err)
+	}
+	// Without a snapshot to keep (first flush, or one set aside as corrupt)
+	// the new one is its own previous snapshot
+	err = os.Rename(j.filepath, j.prevPath())
+	if os.IsNotExist(err) {
+		err = fileutil.WriteAtomic(j.prevPath(), data, 0600)
+	}
+	if err != nil {
+		return fmt.Errorf("keep previous snapshot: %w", err)
+	}
+	if err := fileutil.WriteAtomic(j.filepath, data, 0600); err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	// Events up to seq are in the snapshot; a crash before this rename
+	// just skips them when replaying the old journal
+	if err := os.Rename(j.journalPath(), j.journalPath()+".prev"); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("rotate journal: %w", err)
+	}
+	j.journaled = 0
+	return nil
+}
+
+// ── Files ─────────────────────────────────────────────────────────────────
+
+// loadSnapshot reads a snapshot file. A missing file returns an error
+// matching os.ErrNotExist.
+func (j *jsonStore) loadSnapshot(path string) (map[string]*Entry, uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil || snap.Markets == nil {
+		// Files written before the journal are a bare conditionID → Entry map
+		legacy := make(map[string]*Entry)
+		if err2 := json.Unmarshal(data, &legacy); err2 != nil {
+			return nil, 0, err2
+		}
+		snap = snapshot{Markets: legacy}
+		migrateCostBasis(legacy)
+	}
+	return snap.Markets, snap.Seq, nil
+}
+
+// replayJournal applies the events newer than seq from the previous
+// journal, then the current one. Returns the new state and sequence number
+// and how many events were applied, or an error if an event is missing
+// between seq and the journal.
+func (j *jsonStore) replayJournal(state map[string]*Entry, seq uint64) (map[string]*Entry, uint64, int, error) {
+	replayed := 0
+	for _, path := range []string{j.journalPath() + ".prev", j.journalPath()} {
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("journal: %w", err)
+		}
+		current := path == j.journalPath()
+
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 64*1024), 16*1024*1024) // reconcile events carry every market
+		bad := 0
+		for sc.Scan() {
+			var ev event
+			if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+				bad++ // a line cut short by a crash
+				continue
+			}
+			if current {
+				j.journaled++
+			}
+			if ev.Seq <= seq {
+				continue // already in the snapshot
+			}
+			if ev.Seq != seq+1 {
+				f.Close()
+				return nil, 0, 0, fmt.Errorf("%s: events after seq %d missing (next is %d)", path, seq, ev.Seq)
+			}
+			state = apply(state, ev)
+			seq = ev.Seq
+			replayed++
+		}
+		err = sc.Err()
+		f.Close()
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("%s: %w", path, err)
+		}
+		if bad > 0 {
+			log.Printf("[inventory] skipped %d unreadable lines in %s", bad, path)
+		}
+	}
+	return state, seq, replayed, nil
+}
+
+// appendJournal writes ev to the end of the journal and syncs it.
+func (j *jsonStore) appendJournal(ev event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	f, err := os.OpenFile(j.journalPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (j *jsonStore) prevPath() string {
+	return j.filepath + ".prev"
+}
+
+func (j *jsonStore) journalPath() string {
+	if j.journalFile != "" {
+		return j.journalFile
+	}
+	return j.filepath + ".journal"
+}
+
+// ── Helpers ───────────────────────────────────────────────────────────────
+
+// migrateCostBasis fills in the cost basis of entries saved before it was
+// tracked, spreading what is still invested over the tokens held.
+func migrateCostBasis(state map[string]*Entry) {
+	for _, e := range state {
+		held := e.UpBalance + e.DownBalance
+		if e.UpCost == 0 && e.DownCost == 0 && held > 0 {
+			open := math.Max(0, e.TotalInvested-e.TotalMerged-e.TotalSold)
+			e.UpCost = open * e.UpBalance / held
+			e.DownCost = open * e.DownBalance / held
+		}
+	}
+}
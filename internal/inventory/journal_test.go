@@ -0,0 +1,109 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// journaled opens a JSON inventory in dir.
+func journaled(dir string) (*tracker, error) {
+	return newTracker(&jsonStore{filepath: filepath.Join(dir, "inventory.json")})
+}
+
+// history writes three buys to a JSON inventory in dir: the first two
+// each followed by a compaction, the last one only journaled.
+func history(t *testing.T, dir string) {
+	t.Helper()
+	inv, err := journaled(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tokens := range []float64{10, 20, 30} {
+		inv.RecordBuy(testCID, "up", "down", "UP", tokens, tokens/2)
+		if i < 2 {
+			if err := inv.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestJournalRecovery(t *testing.T) {
+	tests := []struct {
+		name    string
+		damage  func(snap string) // paths derive from the snapshot's
+		wantErr string            // "" = all three buys recovered
+	}{
+		{
+			name:   "snapshot intact",
+			damage: func(string) {},
+		},
+		{
+			name:   "corrupt snapshot rolled forward from .prev",
+			damage: func(snap string) { os.WriteFile(snap, []byte(`{"seq": 2, "mark`), 0600) },
+		},
+		{
+			name:   "crash before the new snapshot was written",
+			damage: func(snap string) { os.Remove(snap) },
+		},
+		{
+			name: "corrupt snapshot and .prev",
+			damage: func(snap string) {
+				os.WriteFile(snap, []byte(`{`), 0600)
+				os.WriteFile(snap+".prev", []byte(`{`), 0600)
+			},
+			wantErr: "unreadable",
+		},
+		{
+			name: "corrupt snapshot, events since .prev lost",
+			damage: func(snap string) {
+				os.WriteFile(snap, []byte(`{`), 0600)
+				os.Remove(snap + ".journal.prev")
+			},
+			wantErr: "after seq 1 missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			history(t, dir)
+			tt.damage(filepath.Join(dir, "inventory.json"))
+
+			inv, err := journaled(dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("load error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if e, _ := inv.Get(testCID); e.UpBalance != 60 || e.TotalInvested != 30 || inv.seq != 3 {
+				t.Fatalf("recovered UP %.0f, invested $%.0f at seq %d; want 60, $30 at seq 3",
+					e.UpBalance, e.TotalInvested, inv.seq)
+			}
+
+			// The recovered state is compacted again and loads by itself
+			inv, err = journaled(dir)
+			if err != nil {
+				t.Fatalf("reload: %v", err)
+			}
+			if e, _ := inv.Get(testCID); e.UpBalance != 60 {
+				t.Errorf("reloaded UP %.0f, want 60", e.UpBalance)
+			}
+		})
+	}
+}
+
+func TestJournalFirstStart(t *testing.T) {
+	inv, err := journaled(t.TempDir())
+	if err != nil {
+		t.Fatalf("load of an empty dir: %v", err)
+	}
+	if len(inv.Snapshot()) != 0 {
+		t.Errorf("first start tracks %d markets, want none", len(inv.Snapshot()))
+	}
+}
@@ -0,0 +1,43 @@
+package inventory
+
+import "testing"
+
+func TestReconcileOnChain(t *testing.T) {
+	dir := t.TempDir()
+	inv, err := journaled(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv.RecordBuy(testCID, "up", "down", "UP", 10, 4)
+	inv.RecordBuy(testCID, "up", "down", "DOWN", 6, 3)
+
+	// Chain agrees: nothing corrected, nothing journaled
+	seq := inv.seq
+	if n := inv.ReconcileOnChain(map[string][2]float64{testCID: {10, 6.00001}}); n != 0 || inv.seq != seq {
+		t.Errorf("matching balances: %d corrected, seq %d → %d; want none", n, seq, inv.seq)
+	}
+
+	// A sell missed on UP, a transfer in on DOWN, and a market never traded
+	balances := map[string][2]float64{
+		testCID:    {5, 12},
+		"0xother0": {3, 3},
+	}
+	if n := inv.ReconcileOnChain(balances); n != 2 {
+		t.Errorf("%d balances corrected, want 2", n)
+	}
+	if _, ok := inv.Get("0xother0"); ok {
+		t.Error("untracked condition added from chain")
+	}
+
+	// The correction survives a restart
+	inv, err = journaled(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, _ := inv.Get(testCID)
+	check(t, "UpBalance", e.UpBalance, 5)
+	check(t, "UpCost", e.UpCost, 2)
+	check(t, "DownBalance", e.DownBalance, 12)
+	check(t, "DownCost", e.DownCost, 6)
+	check(t, "TotalInvested", e.TotalInvested, 7)
+}
@@ -0,0 +1,116 @@
+package inventory
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clob"
+)
+
+const (
+	testKey   = "our-api-key"
+	testMaker = "0x00000000000000000000000000000000000000aa"
+	testCID   = "0xcondition0000000000000000000000000000000000000000000000000000000"
+)
+
+func TestRebuild(t *testing.T) {
+	tests := []struct {
+		name      string
+		history   []clob.Trade
+		current   map[string]*Entry
+		up, down  float64 // balances
+		upCost    float64
+		invested  float64
+		sold      float64
+		wantEntry bool
+	}{
+		{
+			name: "taker buy",
+			history: []clob.Trade{
+				{Market: testCID, Side: "BUY", Outcome: "UP", AssetID: "up", Size: "10", Price: "0.40", Status: "CONFIRMED", TraderSide: "TAKER"},
+			},
+			up: 10, upCost: 4, invested: 4, wantEntry: true,
+		},
+		{
+			name: "maker buy filled by a taker sell",
+			history: []clob.Trade{{
+				Market: testCID, Side: "SELL", Outcome: "UP", AssetID: "up", Size: "20", Price: "0.45",
+				Status: "CONFIRMED", TraderSide: "MAKER",
+				MakerOrders: []clob.MakerOrder{
+					{Owner: "someone-else", AssetID: "up", MatchedAmount: "12", Price: "0.45", Outcome: "UP"},
+					{Owner: testKey, AssetID: "up", MatchedAmount: "8", Price: "0.44", Outcome: "UP", Side: "BUY"},
+				},
+			}},
+			up: 8, upCost: 3.52, invested: 3.52, wantEntry: true,
+		},
+		{
+			name: "maker side inferred from the taker",
+			history: []clob.Trade{{
+				Market: testCID, Side: "SELL", Outcome: "UP", AssetID: "up", Size: "5", Price: "0.50",
+				Status: "MATCHED", TraderSide: "MAKER",
+				MakerOrders: []clob.MakerOrder{
+					{MakerAddress: "0x00000000000000000000000000000000000000AA", AssetID: "up", MatchedAmount: "5", Price: "0.50", Outcome: "UP"},
+				},
+			}},
+			up: 5, upCost: 2.5, invested: 2.5, wantEntry: true,
+		},
+		{
+			name: "maker buy matched against a buy of the other token (mint)",
+			history: []clob.Trade{{
+				Market: testCID, Side: "BUY", Outcome: "UP", AssetID: "up", Size: "5", Price: "0.60",
+				Status: "CONFIRMED", TraderSide: "MAKER",
+				MakerOrders: []clob.MakerOrder{
+					{Owner: testKey, AssetID: "down", MatchedAmount: "5", Price: "0.40", Outcome: "DOWN"},
+				},
+			}},
+			down: 5, invested: 2, wantEntry: true,
+		},
+		{
+			name: "failed trades are ignored",
+			history: []clob.Trade{
+				{Market: testCID, Side: "BUY", Outcome: "UP", AssetID: "up", Size: "10", Price: "0.40", Status: "FAILED", TraderSide: "TAKER"},
+			},
+		},
+		{
+			name: "split pairs carried over, legs sold",
+			history: []clob.Trade{
+				{Market: testCID, Side: "SELL", Outcome: "UP", AssetID: "up", Size: "4", Price: "0.55", Status: "CONFIRMED", TraderSide: "TAKER"},
+			},
+			current: map[string]*Entry{testCID: {UpTokenID: "up", DownTokenID: "down", TotalSplit: 10}},
+			up:      6, down: 10, upCost: 3, invested: 10, sold: 2.2, wantEntry: true,
+		},
+		{
+			name: "merges carried over",
+			history: []clob.Trade{
+				{Market: testCID, Side: "BUY", Outcome: "UP", AssetID: "up", Size: "10", Price: "0.40", Status: "CONFIRMED", TraderSide: "TAKER"},
+				{Market: testCID, Side: "BUY", Outcome: "DOWN", AssetID: "down", Size: "10", Price: "0.50", Status: "CONFIRMED", TraderSide: "TAKER"},
+			},
+			current: map[string]*Entry{testCID: {TotalMerged: 6}},
+			up:      4, down: 4, upCost: 1.6, invested: 9, wantEntry: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := rebuild(tt.history, tt.current, testKey, testMaker)
+			e, ok := state[testCID]
+			if ok != tt.wantEntry {
+				t.Fatalf("entry present = %v, want %v", ok, tt.wantEntry)
+			}
+			if !ok {
+				return
+			}
+			check(t, "UpBalance", e.UpBalance, tt.up)
+			check(t, "DownBalance", e.DownBalance, tt.down)
+			check(t, "UpCost", e.UpCost, tt.upCost)
+			check(t, "TotalInvested", e.TotalInvested, tt.invested)
+			check(t, "TotalSold", e.TotalSold, tt.sold)
+		})
+	}
+}
+
+func check(t *testing.T, field string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("%s = %.6f, want %.6f", field, got, want)
+	}
+}
@@ -0,0 +1,132 @@
+package inventory
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/gipsh/polymarket-bot-go/internal/store"
+)
+
+// The SQLite backend keeps the current state in the inventory table and
+// every event in inventory_events (schema in store/migrate.go). Each event
+// and the rows it changed are written in one transaction, so the table is
+// always the state as of the last event and nothing is replayed on load.
+
+// sqliteStore persists inventory to SQLite.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// load reads the inventory table.
+func (s *sqliteStore) load() (map[string]*Entry, uint64, error) {
+	state := make(map[string]*Entry)
+	var seq uint64
+	if err := s.db.QueryRow(`SELECT COALESCE(MAX(seq), 0) FROM inventory_events`).Scan(&seq); err != nil {
+		log.Printf("[inventory] load error: %v — starting empty", err)
+		return state, 0, nil
+	}
+	rows, err := s.db.Query(`
+		SELECT condition_id, up_token_id, down_token_id, up_balance, down_balance,
+		       up_cost_usdc, down_cost_usdc, total_invested_usdc, total_merged_usdc,
+		       total_split_usdc, total_sold_usdc, total_redeemed_usdc, fees_usdc, redeemed
+		FROM inventory`)
+	if err != nil {
+		log.Printf("[inventory] load error: %v — starting empty", err)
+		return state, 0, nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cid string
+		e := &Entry{}
+		if err := rows.Scan(&cid, &e.UpTokenID, &e.DownTokenID, &e.UpBalance, &e.DownBalance,
+			&e.UpCost, &e.DownCost, &e.TotalInvested, &e.TotalMerged,
+			&e.TotalSplit, &e.TotalSold, &e.TotalRedeemed, &e.Fees, &e.Redeemed); err != nil {
+			log.Printf("[inventory] load error: %v — starting empty", err)
+			return make(map[string]*Entry), 0, nil
+		}
+		state[cid] = e
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("[inventory] load error: %v — starting empty", err)
+		return make(map[string]*Entry), 0, nil
+	}
+	log.Printf("[inventory] loaded: %d markets tracked (seq %d, SQLite)", len(state), seq)
+	return state, seq, nil
+}
+
+// write stores ev and the entries it changed.
+func (s *sqliteStore) write(ev event, state map[string]*Entry) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op after Commit
+
+	if _, err := tx.Exec(`
+		INSERT INTO inventory_events (seq, ts, kind, condition_id, side, tokens, usdc, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		ev.Seq, ev.TS.Format(store.TimeFormat), ev.Kind, nullable(ev.ConditionID), nullable(ev.Side),
+		ev.Tokens, ev.USDC, string(data)); err != nil {
+		return fmt.Errorf("insert event: %w", err)
+	}
+	if ev.Kind == evReconcile {
+		// The rebuilt state replaces every row
+		if _, err := tx.Exec(`DELETE FROM inventory`); err != nil {
+			return err
+		}
+	}
+	for _, cid := range ev.affected() {
+		if e, ok := state[cid]; ok {
+			if err := upsertEntry(tx, cid, e, ev); err != nil {
+				return fmt.Errorf("upsert %s...: %w", cid[:min(8, len(cid))], err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// flush is a no-op: every event is committed as it happens.
+func (s *sqliteStore) flush(uint64, map[string]*Entry) error {
+	return nil
+}
+
+func upsertEntry(tx *sql.Tx, cid string, e *Entry, ev event) error {
+	_, err := tx.Exec(`
+		INSERT INTO inventory (condition_id, up_token_id, down_token_id, up_balance, down_balance,
+		                       up_cost_usdc, down_cost_usdc, total_invested_usdc, total_merged_usdc,
+		                       total_split_usdc, total_sold_usdc, total_redeemed_usdc, fees_usdc, redeemed, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (condition_id) DO UPDATE SET
+			up_token_id = excluded.up_token_id,
+			down_token_id = excluded.down_token_id,
+			up_balance = excluded.up_balance,
+			down_balance = excluded.down_balance,
+			up_cost_usdc = excluded.up_cost_usdc,
+			down_cost_usdc = excluded.down_cost_usdc,
+			total_invested_usdc = excluded.total_invested_usdc,
+			total_merged_usdc = excluded.total_merged_usdc,
+			total_split_usdc = excluded.total_split_usdc,
+			total_sold_usdc = excluded.total_sold_usdc,
+			total_redeemed_usdc = excluded.total_redeemed_usdc,
+			fees_usdc = excluded.fees_usdc,
+			redeemed = excluded.redeemed,
+			updated_at = excluded.updated_at`,
+		cid, e.UpTokenID, e.DownTokenID, e.UpBalance, e.DownBalance,
+		e.UpCost, e.DownCost, e.TotalInvested, e.TotalMerged,
+		e.TotalSplit, e.TotalSold, e.TotalRedeemed, e.Fees, e.Redeemed, ev.TS.Format(store.TimeFormat))
+	return err
+}
+
+// nullable stores empty strings as NULL.
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
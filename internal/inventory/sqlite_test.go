@@ -0,0 +1,49 @@
+package inventory
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gipsh/polymarket-bot-go/internal/store"
+)
+
+func TestSQLiteRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bot.db")
+	const other = "0xother0000000000000000000000000000000000000000000000000000000000"
+
+	db, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv := NewSQLite(db).(*tracker)
+	inv.RecordBuy(testCID, "up", "down", "UP", 10, 4)
+	inv.RecordBuy(other, "up2", "down2", "DOWN", 5, 2)
+	inv.RecordSplit(testCID, "up", "down", 6)
+	// A rebuild from trade history that no longer has the other market
+	rebuilt := cloneState(inv.state)
+	delete(rebuilt, other)
+	inv.record(event{Kind: evReconcile, Markets: rebuilt})
+	db.Close()
+
+	db, err = store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	inv = NewSQLite(db).(*tracker)
+	if inv.seq != 4 {
+		t.Errorf("seq = %d after reopening, want 4", inv.seq)
+	}
+	if _, ok := inv.Get(other); ok {
+		t.Error("market dropped by the rebuild still stored")
+	}
+	e, ok := inv.Get(testCID)
+	if !ok {
+		t.Fatal("market lost on reopening")
+	}
+	check(t, "UpBalance", e.UpBalance, 16)
+	check(t, "UpCost", e.UpCost, 7)
+	check(t, "DownBalance", e.DownBalance, 6)
+	check(t, "TotalInvested", e.TotalInvested, 10)
+	check(t, "TotalSplit", e.TotalSplit, 6)
+}
@@ -1,169 +1,64 @@
-// Package merger executes on-chain MERGE (mergePositions) via the Gnosis Safe.
+// Package merger executes on-chain MERGE (mergePositions), SPLIT
+// (splitPosition) and REDEEM (redeemPositions) via the Gnosis Safe.
 // Mirror of Python merger.py.
 //
 // Architecture:
 //   MetaMask EOA (MERGE_PRIVATE_KEY) → signs execTransaction on Gnosis Safe
 //   Gnosis Safe (FUNDER_ADDRESS) → calls ConditionalTokens.mergePositions()
 //   ConditionalTokens → burns UP+DOWN tokens → returns USDC to Safe
+//
+// After resolution, redeemPositions burns both outcome tokens and pays
+// out each at its payout fraction (winner $1, loser $0).
+//
+// The contract calls live in internal/onchain; this package configures
+// them from the environment, degrades to a no-op when unconfigured and
+// records metrics.
 package merger
 
 import (
 	"context"
-	"crypto/ecdsa"
-	"encoding/hex"
 	"fmt"
 	"log"
-	"math/big"
-	"strings"
-	"time"
-
-	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 
-	"github.com/gipsh/polymarket-bot-go/internal/clob"
 	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/metrics"
+	"github.com/gipsh/polymarket-bot-go/internal/onchain"
 )
 
-// ── Contract addresses (Polygon mainnet) ────────────────────────────────
-
-var (
-	conditionalTokensAddr = common.HexToAddress("0x4D97DCd97eC945f40cF65F87097ACe5EA0476045")
-	usdcAddr              = common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174")
-	gnosisSafeMasterCopy  = "1.3.0"
-)
-
-// ── ABIs ─────────────────────────────────────────────────────────────────
-
-const conditionalTokensABI = `[{
-	"name":"mergePositions",
-	"type":"function",
-	"inputs":[
-		{"name":"collateralToken","type":"address"},
-		{"name":"parentCollectionId","type":"bytes32"},
-		{"name":"conditionId","type":"bytes32"},
-		{"name":"partition","type":"uint256[]"},
-		{"name":"amount","type":"uint256"}
-	],
-	"outputs":[]
-},{
-	"name":"balanceOf",
-	"type":"function",
-	"inputs":[
-		{"name":"owner","type":"address"},
-		{"name":"id","type":"uint256"}
-	],
-	"outputs":[{"name":"","type":"uint256"}]
-},{
-	"name":"getConditionId",
-	"type":"function",
-	"inputs":[
-		{"name":"oracle","type":"address"},
-		{"name":"questionId","type":"bytes32"},
-		{"name":"outcomeSlotCount","type":"uint256"}
-	],
-	"outputs":[{"name":"","type":"bytes32"}]
-}]`
-
-const gnosisSafeABI = `[{
-	"name":"execTransaction",
-	"type":"function",
-	"inputs":[
-		{"name":"to","type":"address"},
-		{"name":"value","type":"uint256"},
-		{"name":"data","type":"bytes"},
-		{"name":"operation","type":"uint8"},
-		{"name":"safeTxGas","type":"uint256"},
-		{"name":"baseGas","type":"uint256"},
-		{"name":"gasPrice","type":"uint256"},
-		{"name":"gasToken","type":"address"},
-		{"name":"refundReceiver","type":"address"},
-		{"name":"signatures","type":"bytes"}
-	],
-	"outputs":[{"name":"","type":"bool"}]
-},{
-	"name":"getTransactionHash",
-	"type":"function",
-	"inputs":[
-		{"name":"to","type":"address"},
-		{"name":"value","type":"uint256"},
-		{"name":"data","type":"bytes"},
-		{"name":"operation","type":"uint8"},
-		{"name":"safeTxGas","type":"uint256"},
-		{"name":"baseGas","type":"uint256"},
-		{"name":"gasPrice","type":"uint256"},
-		{"name":"gasToken","type":"address"},
-		{"name":"refundReceiver","type":"address"},
-		{"name":"_nonce","type":"uint256"}
-	],
-	"outputs":[{"name":"","type":"bytes32"}]
-},{
-	"name":"nonce",
-	"type":"function",
-	"inputs":[],
-	"outputs":[{"name":"","type":"uint256"}]
-}]`
-
-// Merger handles on-chain mergePositions via the Gnosis Safe.
+// Merger handles on-chain mergePositions, splitPosition and redeemPositions
+// via the Gnosis Safe.
 type Merger struct {
-	ready    bool
-	key      *ecdsa.PrivateKey
-	safeAddr common.Address
-	ethCli   *ethclient.Client
-	ctfABI   abi.ABI
-	safeABI  abi.ABI
+	ready bool
+	ctf   *onchain.Merger
 }
 
-// New creates a Merger, initialising the Ethereum client and ABIs.
+// New creates a Merger, connecting the Safe wrapper to Polygon.
 func New() *Merger {
 	m := &Merger{}
 
-	// Parse merge private key
 	if config.MergePrivateKey == "" {
 		log.Println("[merger] MERGE_PRIVATE_KEY not set — on-chain MERGE disabled")
 		return m
 	}
-	key, err := clob.ParsePrivateKey(config.MergePrivateKey)
-	if err != nil {
-		log.Printf("[merger] invalid MERGE_PRIVATE_KEY: %v", err)
-		return m
-	}
-	m.key = key
-
 	if config.FunderAddress == "" {
 		log.Println("[merger] FUNDER_ADDRESS not set — on-chain MERGE disabled")
 		return m
 	}
-	m.safeAddr = common.HexToAddress(config.FunderAddress)
-
-	// Connect to Polygon
-	cli, err := ethclient.Dial(config.PolygonRPC)
+	safe, err := onchain.NewSafe(config.PolygonRPC, config.FunderAddress, config.MergePrivateKey)
 	if err != nil {
-		log.Printf("[merger] failed to connect to %s: %v", config.PolygonRPC, err)
+		log.Printf("[merger] Safe setup failed: %v — on-chain MERGE disabled", err)
 		return m
 	}
-	m.ethCli = cli
-
-	// Parse ABIs
-	ctfABI, err := abi.JSON(strings.NewReader(conditionalTokensABI))
+	ctf, err := onchain.NewMerger(safe)
 	if err != nil {
-		log.Printf("[merger] ABI parse error: %v", err)
+		log.Printf("[merger] %v — on-chain MERGE disabled", err)
 		return m
 	}
-	safeABI, err := abi.JSON(strings.NewReader(gnosisSafeABI))
-	if err != nil {
-		log.Printf("[merger] Safe ABI parse error: %v", err)
-		return m
-	}
-	m.ctfABI = ctfABI
-	m.safeABI = safeABI
+	m.ctf = ctf
 	m.ready = true
 
 	log.Printf("[merger] ready | Safe=%s... | signer=%s...",
-		m.safeAddr.Hex()[:10], clob.AddressFromKey(key).Hex()[:10])
+		safe.Address().Hex()[:10], safe.Owner().Hex()[:10])
 	return m
 }
 
@@ -173,263 +68,92 @@ func (m *Merger) Ready() bool {
 }
 
 // Merge calls mergePositions on the ConditionalTokens contract via the Gnosis Safe.
-// Returns the number of USDC units merged (≈ pairs count).
-func (m *Merger) Merge(conditionID string, pairs float64) float64 {
+// Returns the number of USDC units merged (≈ pairs count) and the tx hash
+// (set even for a reverted tx).
+// Waits up to TX_TIMEOUT_SEC for the receipt, or until ctx is done.
+func (m *Merger) Merge(ctx context.Context, conditionID string, pairs float64) (float64, string) {
 	if !m.ready {
-		return 0
+		return 0, ""
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
-
-	// Convert conditionID hex → bytes32
-	condBytes, err := hexToBytes32(conditionID)
+	merged, txHash, err := m.ctf.Merge(ctx, conditionID, pairs)
 	if err != nil {
-		log.Printf("[merger] invalid conditionID %q: %v", conditionID, err)
-		return 0
-	}
-
-	// Cap to on-chain balance
-	onChainPairs := m.getOnChainPairs(ctx, condBytes)
-	if onChainPairs < pairs {
-		log.Printf("[merger] on-chain pairs (%.4f) < inventory (%.4f) — using on-chain", onChainPairs, pairs)
-		pairs = onChainPairs
-	}
-	if pairs < 0.001 {
-		log.Printf("[merger] on-chain balance too low to merge")
-		return 0
-	}
-
-	amount := new(big.Int).SetInt64(int64(pairs * 1e6)) // 6 decimals
-
-	// Build mergePositions calldata
-	calldata, err := m.ctfABI.Pack("mergePositions",
-		usdcAddr,
-		[32]byte{}, // parentCollectionId = 0x0
-		condBytes,
-		[]*big.Int{big.NewInt(1), big.NewInt(2)}, // partition [UP, DOWN]
-		amount,
-	)
-	if err != nil {
-		log.Printf("[merger] pack mergePositions: %v", err)
-		return 0
-	}
-
-	// Execute via Safe
-	if err := m.execViaSafe(ctx, conditionalTokensAddr, calldata); err != nil {
-		log.Printf("[merger] execViaSafe failed: %v", err)
-		return 0
+		log.Printf("[merger] MERGE failed for %s...: %v", conditionID[:8], err)
+		metrics.Merge(false)
+		return 0, txHash
 	}
+	metrics.Merge(true)
 
-	log.Printf("[merger] ✅ MERGE %.4f pairs → +$%.4f USDC | condition: %s...",
-		pairs, pairs, conditionID[:8])
-	return pairs
+	log.Printf("[merger] ✅ MERGE %.4f pairs → +$%.4f USDC | condition: %s... | tx: %s",
+		merged, merged, conditionID[:8], txHash)
+	return merged, txHash
 }
 
-// ── Gnosis Safe execution ─────────────────────────────────────────────────
-
-func (m *Merger) execViaSafe(ctx context.Context, to common.Address, data []byte) error {
-	// Get Safe nonce
-	nonceCalldata, _ := m.safeABI.Pack("nonce")
-	result, err := m.ethCli.CallContract(ctx, ethereum.CallMsg{
-		To:   &m.safeAddr,
-		Data: nonceCalldata,
-	}, nil)
-	if err != nil {
-		return fmt.Errorf("get safe nonce: %w", err)
-	}
-	var nonce *big.Int
-	if len(result) >= 32 {
-		nonce = new(big.Int).SetBytes(result[:32])
-	} else {
-		nonce = big.NewInt(0)
-	}
-
-	// Get tx hash from Safe
-	zero := big.NewInt(0)
-	zeroAddr := common.Address{}
-	hashCalldata, err := m.safeABI.Pack("getTransactionHash",
-		to, zero, data,
-		uint8(0), // operation: CALL
-		zero, zero, zero, zeroAddr, zeroAddr,
-		nonce,
-	)
-	if err != nil {
-		return fmt.Errorf("pack getTransactionHash: %w", err)
-	}
-
-	hashResult, err := m.ethCli.CallContract(ctx, ethereum.CallMsg{
-		To:   &m.safeAddr,
-		Data: hashCalldata,
-	}, nil)
-	if err != nil {
-		return fmt.Errorf("getTransactionHash call: %w", err)
-	}
-	if len(hashResult) < 32 {
-		return fmt.Errorf("unexpected hash result length: %d", len(hashResult))
-	}
-	var txHashBytes [32]byte
-	copy(txHashBytes[:], hashResult[:32])
-
-	// Sign the hash with the EOA key
-	sig, err := crypto.Sign(txHashBytes[:], m.key)
-	if err != nil {
-		return fmt.Errorf("sign safe tx: %w", err)
-	}
-	// Safe expects v = 27/28, not 0/1
-	sig[64] += 27
-
-	// Build execTransaction calldata
-	execCalldata, err := m.safeABI.Pack("execTransaction",
-		to, zero, data,
-		uint8(0), // CALL
-		zero, zero, zero, zeroAddr, zeroAddr,
-		sig,
-	)
-	if err != nil {
-		return fmt.Errorf("pack execTransaction: %w", err)
+// Split calls splitPosition on the ConditionalTokens contract via the
+// Gnosis Safe, minting one UP+DOWN pair per USDC. Returns the pairs minted
+// (0 on failure) and the tx hash (set even for a reverted tx).
+// Waits up to TX_TIMEOUT_SEC for the receipt, or until ctx is done.
+func (m *Merger) Split(ctx context.Context, conditionID string, usdc float64) (float64, string) {
+	if !m.ready {
+		return 0, ""
 	}
-
-	// Get signer address and nonce
-	signerAddr := clob.AddressFromKey(m.key)
-	signerNonce, err := m.ethCli.PendingNonceAt(ctx, signerAddr)
+	pairs, txHash, err := m.ctf.Split(ctx, conditionID, usdc)
 	if err != nil {
-		return fmt.Errorf("get signer nonce: %w", err)
+		log.Printf("[merger] SPLIT failed for %s...: %v", conditionID[:8], err)
+		metrics.Split(false)
+		return 0, txHash
 	}
+	metrics.Split(true)
 
-	// Gas estimation
-	gasLimit, err := m.ethCli.EstimateGas(ctx, ethereum.CallMsg{
-		From: signerAddr,
-		To:   &m.safeAddr,
-		Data: execCalldata,
-	})
-	if err != nil {
-		log.Printf("[merger] gas estimate failed, using 500000: %v", err)
-		gasLimit = 500000
-	}
-	gasLimit = gasLimit * 12 / 10 // +20% buffer
+	log.Printf("[merger] ✅ SPLIT $%.4f USDC → %.4f pairs | condition: %s... | tx: %s",
+		usdc, pairs, conditionID[:8], txHash)
+	return pairs, txHash
+}
 
-	// Gas price
-	gasPrice, err := m.ethCli.SuggestGasPrice(ctx)
-	if err != nil {
-		return fmt.Errorf("gas price: %w", err)
+// Redeem calls redeemPositions on the ConditionalTokens contract via the
+// Gnosis Safe, burning the Safe's UP and DOWN tokens of a resolved
+// condition. upTokenID/downTokenID are the CLOB token IDs (empty = derive
+// them from the condition). Returns the USDC paid out and the tx hash; 0
+// and no hash if nothing was redeemed.
+func (m *Merger) Redeem(ctx context.Context, conditionID, upTokenID, downTokenID string) (float64, string, error) {
+	if !m.ready {
+		return 0, "", fmt.Errorf("merger not configured")
 	}
-
-	// Build and sign the Ethereum transaction
-	chainID := big.NewInt(137) // Polygon
-	tx := types.NewTransaction(signerNonce, m.safeAddr, zero, gasLimit, gasPrice, execCalldata)
-	signer := types.NewEIP155Signer(chainID)
-	signedTx, err := types.SignTx(tx, signer, m.key)
+	usdc, txHash, err := m.ctf.Redeem(ctx, conditionID, upTokenID, downTokenID)
 	if err != nil {
-		return fmt.Errorf("sign tx: %w", err)
+		metrics.Redeem(false)
+		return 0, txHash, err
 	}
-
-	// Broadcast
-	if err := m.ethCli.SendTransaction(ctx, signedTx); err != nil {
-		return fmt.Errorf("send tx: %w", err)
+	if usdc > 0 {
+		metrics.Redeem(true)
+		log.Printf("[merger] ✅ REDEEM → +$%.4f USDC | condition: %s... | tx: %s", usdc, conditionID[:8], txHash)
 	}
-	log.Printf("[merger] tx broadcast: %s", signedTx.Hash().Hex())
-
-	// Wait for receipt
-	return m.waitForReceipt(ctx, signedTx.Hash())
+	return usdc, txHash, nil
 }
 
-func (m *Merger) waitForReceipt(ctx context.Context, txHash common.Hash) error {
-	for {
-		receipt, err := m.ethCli.TransactionReceipt(ctx, txHash)
-		if err == nil {
-			if receipt.Status == 1 {
-				log.Printf("[merger] tx confirmed in block %d", receipt.BlockNumber)
-				return nil
-			}
-			return fmt.Errorf("tx reverted in block %d", receipt.BlockNumber)
-		}
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(3 * time.Second):
-		}
-	}
-}
-
-// ── On-chain balance check ────────────────────────────────────────────────
-
-// getOnChainPairs returns the minimum of UP and DOWN token balances
-// held by the Safe, capped to the inventory estimate.
-func (m *Merger) getOnChainPairs(ctx context.Context, condBytes [32]byte) float64 {
-	// Token IDs are computed as keccak256(conditionId + outcomeIndex)
-	// UP = index 0, DOWN = index 1 (for binary markets)
-	upTokenID := positionID(condBytes, 0)
-	downTokenID := positionID(condBytes, 1)
-
-	upBal := m.tokenBalance(ctx, upTokenID)
-	downBal := m.tokenBalance(ctx, downTokenID)
-
-	up := float64(upBal.Int64()) / 1e6
-	down := float64(downBal.Int64()) / 1e6
-
-	if up < down {
-		return up
+// Balances returns the Safe's on-chain UP and DOWN balances per condition,
+// read in batches through Multicall3.
+func (m *Merger) Balances(ctx context.Context, positions []onchain.Position) (map[string][2]float64, error) {
+	if !m.ready {
+		return nil, fmt.Errorf("merger not configured")
 	}
-	return down
+	return m.ctf.Balances(ctx, positions)
 }
 
-func (m *Merger) tokenBalance(ctx context.Context, tokenID *big.Int) *big.Int {
-	calldata, _ := m.ctfABI.Pack("balanceOf", m.safeAddr, tokenID)
-	result, err := m.ethCli.CallContract(ctx, ethereum.CallMsg{
-		To:   &conditionalTokensAddr,
-		Data: calldata,
-	}, nil)
-	if err != nil || len(result) < 32 {
-		return big.NewInt(0)
+// Resolution returns the payout fraction of UP and DOWN (e.g. [1, 0] when
+// UP won) and whether the condition has been resolved on-chain.
+func (m *Merger) Resolution(ctx context.Context, conditionID string) ([2]float64, bool, error) {
+	if !m.ready {
+		return [2]float64{}, false, fmt.Errorf("merger not configured")
 	}
-	return new(big.Int).SetBytes(result[:32])
-}
-
-// positionID computes the ERC-1155 token ID for a given condition + outcome index.
-// positionId = keccak256(keccak256(parentCollectionId | conditionId | indexSet))
-// For binary markets: UP=indexSet=1 (binary 01), DOWN=indexSet=2 (binary 10)
-func positionID(conditionID [32]byte, outcomeIndex int) *big.Int {
-	// indexSet: UP = 0b01 = 1, DOWN = 0b10 = 2
-	indexSet := big.NewInt(int64(1 << outcomeIndex))
-
-	// collectionId = keccak256(abi.encodePacked(parentCollectionId, conditionId, indexSet))
-	parentColl := [32]byte{} // zero bytes
-	indexSetBytes := make([]byte, 32)
-	indexSet.FillBytes(indexSetBytes)
-
-	collectionIDInput := append(parentColl[:], conditionID[:]...)
-	collectionIDInput = append(collectionIDInput, indexSetBytes...)
-	collectionID := crypto.Keccak256(collectionIDInput)
-
-	// positionId = keccak256(abi.encodePacked(collateralToken, collectionId))
-	tokenAddrBytes := usdcAddr.Bytes()
-	posInput := append(tokenAddrBytes, collectionID...)
-	posIDBytes := crypto.Keccak256(posInput)
-
-	return new(big.Int).SetBytes(posIDBytes)
+	return m.ctf.Resolution(ctx, conditionID)
 }
 
-// ── Helpers ───────────────────────────────────────────────────────────────
-
-func hexToBytes32(hexStr string) ([32]byte, error) {
-	hexStr = strings.TrimPrefix(hexStr, "0x")
-	b, err := hex.DecodeString(hexStr)
+// IsResolved checks if a condition has been resolved on-chain, i.e. its
+// payoutDenominator has been set by the oracle.
+func (m *Merger) IsResolved(ctx context.Context, conditionID string) bool {
+	_, resolved, err := m.Resolution(ctx, conditionID)
 	if err != nil {
-		return [32]byte{}, err
-	}
-	var out [32]byte
-	if len(b) > 32 {
-		return [32]byte{}, fmt.Errorf("hex too long: %d bytes", len(b))
+		log.Printf("[merger] resolution check for %s...: %v", conditionID[:min(8, len(conditionID))], err)
 	}
-	copy(out[32-len(b):], b)
-	return out, nil
-}
-
-// IsResolved checks if a condition has been resolved on-chain.
-// (Placeholder — full implementation checks PayoutDenominator > 0)
-func (m *Merger) IsResolved(conditionID string) bool {
-	// TODO: call ConditionalTokens.payoutDenominator(conditionId)
-	// For now return false (assume unresolved)
-	return false
+	return resolved
 }
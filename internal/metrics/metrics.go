@@ -0,0 +1,284 @@
+// Package metrics exposes Prometheus metrics for the trading loop.
+//
+// Collectors live on the default registry, so instrumented packages just
+// call the helpers below; Serve exposes them (plus Go runtime and process
+// metrics) on /metrics. All names are prefixed "polybot_".
+package metrics
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "polybot"
+
+// Feed names used as the "feed" label.
+const (
+	FeedMarket = "market"
+	FeedUser   = "user"
+)
+
+// Price sources used as the "source" label of price_fetches_total.
+const (
+	SourceWS        = "ws"
+	SourceREST      = "rest"
+	SourceRESTError = "rest_error"
+)
+
+var (
+	// ── Trading loop ──────────────────────────────────────────────────────
+	actions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "actions_total",
+		Help:      "FSM actions emitted, by kind.",
+	}, []string{"kind"})
+
+	states = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fsm_states_total",
+		Help:      "FSM steps, by resulting state.",
+	}, []string{"state"})
+
+	priceFetches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "price_fetches_total",
+		Help:      "Prices used by the loop, by source (ws, rest, rest_error). rest/(ws+rest) is the REST fallback rate.",
+	}, []string{"source"})
+
+	// ── Orders ────────────────────────────────────────────────────────────
+	orderLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "order_latency_seconds",
+		Help:      "Round-trip time of market (FOK) order submission, by side.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10},
+	}, []string{"side"})
+
+	orders = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_total",
+		Help:      "Market (FOK) orders submitted, by side and result (filled, failed).",
+	}, []string{"side", "result"})
+
+	fills = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fills_total",
+		Help:      "Fill events received on the user WebSocket, by role (taker, maker).",
+	}, []string{"role"})
+
+	orderLifecycle = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "order_lifecycle_total",
+		Help:      "Market (FOK) orders reaching a lifecycle status (matched, mined, confirmed, failed, unconfirmed).",
+	}, []string{"status"})
+
+	fillCorrections = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fill_corrections_total",
+		Help:      "Inventory corrections made because a market order filled differently than booked.",
+	})
+
+	// ── WebSocket feeds ───────────────────────────────────────────────────
+	wsReconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ws_reconnects_total",
+		Help:      "WebSocket disconnects followed by a reconnect attempt, by feed.",
+	}, []string{"feed"})
+
+	// ── Inventory ─────────────────────────────────────────────────────────
+	balances = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inventory_tokens",
+		Help:      "Token balance per active market and side (UP, DOWN).",
+	}, []string{"market", "side"})
+
+	pairs = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inventory_pairs",
+		Help:      "Mergeable UP+DOWN pairs per active market.",
+	}, []string{"market"})
+
+	// ── Merges ────────────────────────────────────────────────────────────
+	merges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "merges_total",
+		Help:      "On-chain mergePositions attempts, by result (ok, failed).",
+	}, []string{"result"})
+
+	mergeGas = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "merge_gas_spent_pol_total",
+		Help:      "Gas paid for merge, split and redeem transactions, in POL.",
+	})
+
+	splits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "splits_total",
+		Help:      "On-chain splitPosition attempts, by result (ok, failed).",
+	}, []string{"result"})
+
+	redeems = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redeems_total",
+		Help:      "On-chain redeemPositions attempts, by result (ok, failed).",
+	}, []string{"result"})
+
+	txRebroadcasts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tx_rebroadcasts_total",
+		Help:      "Pending transactions re-sent or given up, by reason (bump, dropped, replaced).",
+	}, []string{"reason"})
+
+	mergeConfirm = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "merge_confirm_seconds",
+		Help:      "Time from merge broadcast to receipt.",
+		Buckets:   []float64{2, 5, 10, 20, 30, 45, 60, 120, 300},
+	})
+
+	// ── Risk ──────────────────────────────────────────────────────────────
+	riskKilled = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "risk_kill_switch",
+		Help:      "1 while the risk kill switch is latched.",
+	})
+)
+
+// ── Recorders ─────────────────────────────────────────────────────────────
+
+// Step records one FSM step: the resulting state and the action emitted.
+func Step(state, action string) {
+	states.WithLabelValues(state).Inc()
+	actions.WithLabelValues(action).Inc()
+}
+
+// PriceFetch records where the loop got a market's prices from.
+func PriceFetch(source string) {
+	priceFetches.WithLabelValues(source).Inc()
+}
+
+// Order records a market order submission and how long it took.
+func Order(side string, d time.Duration, err error) {
+	orderLatency.WithLabelValues(side).Observe(d.Seconds())
+	result := "filled"
+	if err != nil {
+		result = "failed"
+	}
+	orders.WithLabelValues(side, result).Inc()
+}
+
+// Fill records a fill event from the user feed.
+func Fill(role string) {
+	fills.WithLabelValues(role).Inc()
+}
+
+// OrderLifecycle records a market order reaching a lifecycle status.
+func OrderLifecycle(status string) {
+	orderLifecycle.WithLabelValues(status).Inc()
+}
+
+// FillCorrection records an inventory correction to an order's actual fill.
+func FillCorrection() {
+	fillCorrections.Inc()
+}
+
+// Reconnect records a WebSocket reconnect.
+func Reconnect(feed string) {
+	wsReconnects.WithLabelValues(feed).Inc()
+}
+
+// FeedAge exports age() as ws_message_age_seconds{feed=feed}, evaluated
+// at scrape time. Alert on it to catch a stale feed.
+func FeedAge(feed string, age func() time.Duration) {
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "ws_message_age_seconds",
+		Help:        "Seconds since the last message on a WebSocket feed.",
+		ConstLabels: prometheus.Labels{"feed": feed},
+	}, func() float64 { return age().Seconds() }))
+}
+
+// Inventory sets the balances of an active market.
+func Inventory(market string, up, down, mergeable float64) {
+	balances.WithLabelValues(market, "UP").Set(up)
+	balances.WithLabelValues(market, "DOWN").Set(down)
+	pairs.WithLabelValues(market).Set(mergeable)
+}
+
+// ResetInventory drops all market balances, so markets that are no longer
+// traded stop being exported.
+func ResetInventory() {
+	balances.Reset()
+	pairs.Reset()
+}
+
+// Merge records the outcome of a merge attempt.
+func Merge(ok bool) {
+	result := "ok"
+	if !ok {
+		result = "failed"
+	}
+	merges.WithLabelValues(result).Inc()
+}
+
+// Split records the outcome of a split attempt.
+func Split(ok bool) {
+	result := "ok"
+	if !ok {
+		result = "failed"
+	}
+	splits.WithLabelValues(result).Inc()
+}
+
+// Redeem records the outcome of a redeem attempt.
+func Redeem(ok bool) {
+	result := "ok"
+	if !ok {
+		result = "failed"
+	}
+	redeems.WithLabelValues(result).Inc()
+}
+
+// MergeTx records a mined merge (or split, redeem) transaction: the gas it paid (in POL) and
+// how long it took from broadcast to receipt.
+func MergeTx(gasPOL float64, confirm time.Duration) {
+	mergeGas.Add(gasPOL)
+	mergeConfirm.Observe(confirm.Seconds())
+}
+
+// TxRebroadcast records a fee bump or re-broadcast of a pending transaction,
+// or one replaced by a transaction the bot did not send.
+func TxRebroadcast(reason string) {
+	txRebroadcasts.WithLabelValues(reason).Inc()
+}
+
+// RiskKilled exports the state of the risk kill switch.
+func RiskKilled(killed bool) {
+	v := 0.0
+	if killed {
+		v = 1
+	}
+	riskKilled.Set(v)
+}
+
+// ── Server ────────────────────────────────────────────────────────────────
+
+// Serve exposes /metrics on addr in the background. The returned server
+// is shut down by the caller.
+func Serve(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		log.Printf("[metrics] serving /metrics on %s", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[metrics] server error: %v", err)
+		}
+	}()
+	return srv
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// scrape returns the /metrics page as served by Serve.
+func scrape(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestExported(t *testing.T) {
+	Step("ARB", "buy_arb_pair")
+	Step("ARB", "buy_arb_pair")
+	PriceFetch(SourceREST)
+	Order("BUY", 200*time.Millisecond, nil)
+	Order("SELL", time.Second, errors.New("killed"))
+	Fill("maker")
+	Reconnect(FeedUser)
+	Inventory("btc-updown", 12, 10, 10)
+	Merge(false)
+	RiskKilled(true)
+	FeedAge(FeedMarket, func() time.Duration { return 3 * time.Second })
+
+	page := scrape(t)
+	for _, want := range []string{
+		`polybot_actions_total{kind="buy_arb_pair"} 2`,
+		`polybot_fsm_states_total{state="ARB"} 2`,
+		`polybot_price_fetches_total{source="rest"} 1`,
+		`polybot_orders_total{result="filled",side="BUY"} 1`,
+		`polybot_orders_total{result="failed",side="SELL"} 1`,
+		`polybot_order_latency_seconds_bucket{side="BUY",le="0.25"} 1`,
+		`polybot_fills_total{role="maker"} 1`,
+		`polybot_ws_reconnects_total{feed="user"} 1`,
+		`polybot_inventory_tokens{market="btc-updown",side="UP"} 12`,
+		`polybot_inventory_pairs{market="btc-updown"} 10`,
+		`polybot_merges_total{result="failed"} 1`,
+		`polybot_risk_kill_switch 1`,
+		`polybot_ws_message_age_seconds{feed="market"} 3`,
+	} {
+		if !strings.Contains(page, want+"\n") {
+			t.Errorf("missing %s", want)
+		}
+	}
+
+	// Markets no longer traded stop being exported
+	ResetInventory()
+	if page := scrape(t); strings.Contains(page, "polybot_inventory_tokens{") {
+		t.Error("inventory still exported after ResetInventory")
+	}
+}
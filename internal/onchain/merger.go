@@ -1,72 +1,405 @@
-// Package onchain handles on-chain operations via go-ethereum.
-//
-// All calls go through the Gnosis Safe (FUNDER_ADDRESS) via execTransaction,
-// because the Safe holds the conditional tokens and USDC — not the EOA directly.
-//
-// Python reference: merger.py, setup_safe_allowances.py
 package onchain
 
-import "github.com/ethereum/go-ethereum/ethclient"
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
 
-const (
-	// ConditionalTokens contract on Polygon mainnet
-	ConditionalTokensAddress = "0x4D97DCd97eC945f40cF65F87097ACe5EA0476045"
-
-	// USDC.e collateral on Polygon
-	USDCAddress = "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
-
-	// Polymarket exchange contracts (need USDC.approve from Safe)
-	CTFExchangeAddress          = "0x4bFb41d5B3570DeFd03C39a9A4D8dE6Bd8B8982E"
-	NegRiskCTFExchangeAddress   = "0xC5d563A36AE78145C45a50134d48A1215220f80a"
-	NegRiskAdapterAddress       = "0xd91E80cF2E7be2e162c6513ceD06f1dD0dA35296"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
-// Safe wraps a Gnosis Safe 1.3.0 and provides execTransaction signing.
-type Safe struct {
-	// TODO: go-ethereum client, safe address, owner private key
-}
-
-// NewSafe creates a Safe wrapper.
-//   rpcURL       — Polygon RPC endpoint
-//   safeAddress  — Gnosis Safe contract address (FUNDER_ADDRESS)
-//   ownerKey     — MetaMask EOA private key (hex, controls the Safe)
-func NewSafe(rpcURL, safeAddress, ownerKey string) (*Safe, error) {
-	panic("not implemented")
-}
+const conditionalTokensABI = `[{
+	"name":"splitPosition",
+	"type":"function",
+	"inputs":[
+		{"name":"collateralToken","type":"address"},
+		{"name":"parentCollectionId","type":"bytes32"},
+		{"name":"conditionId","type":"bytes32"},
+		{"name":"partition","type":"uint256[]"},
+		{"name":"amount","type":"uint256"}
+	],
+	"outputs":[]
+},{
+	"name":"mergePositions",
+	"type":"function",
+	"inputs":[
+		{"name":"collateralToken","type":"address"},
+		{"name":"parentCollectionId","type":"bytes32"},
+		{"name":"conditionId","type":"bytes32"},
+		{"name":"partition","type":"uint256[]"},
+		{"name":"amount","type":"uint256"}
+	],
+	"outputs":[]
+},{
+	"name":"redeemPositions",
+	"type":"function",
+	"inputs":[
+		{"name":"collateralToken","type":"address"},
+		{"name":"parentCollectionId","type":"bytes32"},
+		{"name":"conditionId","type":"bytes32"},
+		{"name":"indexSets","type":"uint256[]"}
+	],
+	"outputs":[]
+},{
+	"name":"payoutDenominator",
+	"type":"function",
+	"inputs":[{"name":"","type":"bytes32"}],
+	"outputs":[{"name":"","type":"uint256"}]
+},{
+	"name":"payoutNumerators",
+	"type":"function",
+	"inputs":[
+		{"name":"","type":"bytes32"},
+		{"name":"","type":"uint256"}
+	],
+	"outputs":[{"name":"","type":"uint256"}]
+},{
+	"name":"balanceOf",
+	"type":"function",
+	"inputs":[
+		{"name":"owner","type":"address"},
+		{"name":"id","type":"uint256"}
+	],
+	"outputs":[{"name":"","type":"uint256"}]
+},{
+	"name":"isApprovedForAll",
+	"type":"function",
+	"inputs":[
+		{"name":"owner","type":"address"},
+		{"name":"operator","type":"address"}
+	],
+	"outputs":[{"name":"","type":"bool"}]
+},{
+	"name":"setApprovalForAll",
+	"type":"function",
+	"inputs":[
+		{"name":"operator","type":"address"},
+		{"name":"approved","type":"bool"}
+	],
+	"outputs":[]
+}]`
 
-// ExecTransaction signs and submits a call through the Safe.
-// Returns the tx hash.
-func (s *Safe) ExecTransaction(to string, data []byte, label string) (string, error) {
-	panic("not implemented")
-}
+// binaryIndexSets are the UP (0b01) and DOWN (0b10) outcome slots.
+var binaryIndexSets = []*big.Int{big.NewInt(1), big.NewInt(2)}
 
-// Merger executes on-chain MERGE via ConditionalTokens.mergePositions()
-// through the Gnosis Safe's execTransaction.
+// Merger executes on-chain MERGE via ConditionalTokens.mergePositions(),
+// SPLIT via splitPosition() and REDEEM via redeemPositions(), through the
+// Gnosis Safe's execTransaction.
 type Merger struct {
-	safe   *Safe
-	client *ethclient.Client
+	safe *Safe
+	ctf  abi.ABI
 }
 
 // NewMerger creates a Merger.
 func NewMerger(safe *Safe) (*Merger, error) {
-	panic("not implemented")
+	ctf, err := abi.JSON(strings.NewReader(conditionalTokensABI))
+	if err != nil {
+		return nil, fmt.Errorf("ConditionalTokens ABI: %w", err)
+	}
+	return &Merger{safe: safe, ctf: ctf}, nil
 }
 
 // GetOnChainPairs returns the actual mergeable pairs available on-chain.
 // Reads from ConditionalTokens.balanceOf() — source of truth.
-func (m *Merger) GetOnChainPairs(conditionID string) (float64, error) {
-	panic("not implemented")
+func (m *Merger) GetOnChainPairs(ctx context.Context, conditionID string) (float64, error) {
+	condBytes, err := hexToBytes32(conditionID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid conditionID %q: %w", conditionID, err)
+	}
+	up, err := m.TokenBalance(ctx, positionID(condBytes, 0))
+	if err != nil {
+		return 0, err
+	}
+	down, err := m.TokenBalance(ctx, positionID(condBytes, 1))
+	if err != nil {
+		return 0, err
+	}
+	return min(up, down), nil
+}
+
+// TokenBalance returns the Safe's balance of an outcome token, in tokens.
+func (m *Merger) TokenBalance(ctx context.Context, tokenID *big.Int) (float64, error) {
+	raw, err := m.callUint(ctx, "balanceOf", m.safe.Address(), tokenID)
+	if err != nil {
+		return 0, fmt.Errorf("balanceOf: %w", err)
+	}
+	return tokenAmount(raw), nil
+}
+
+// Position identifies the two outcome tokens of a condition. Empty token
+// IDs are derived from the condition.
+type Position struct {
+	ConditionID string
+	UpTokenID   string
+	DownTokenID string
+}
+
+// Balances reads the Safe's UP and DOWN balances of every position with
+// Multicall3, a few hundred balanceOf calls per eth_call. Conditions whose
+// balance could not be read are left out of the result.
+func (m *Merger) Balances(ctx context.Context, positions []Position) (map[string][2]float64, error) {
+	ctfAddr := common.HexToAddress(ConditionalTokensAddress)
+	calls := make([]Call3, 0, 2*len(positions))
+	valid := make([]Position, 0, len(positions))
+	for _, p := range positions {
+		condBytes, err := hexToBytes32(p.ConditionID)
+		if err != nil {
+			log.Printf("[onchain] skipping invalid conditionID %q: %v", p.ConditionID, err)
+			continue
+		}
+		for _, id := range []*big.Int{tokenID(p.UpTokenID, condBytes, 0), tokenID(p.DownTokenID, condBytes, 1)} {
+			calldata, err := m.ctf.Pack("balanceOf", m.safe.Address(), id)
+			if err != nil {
+				return nil, fmt.Errorf("pack balanceOf: %w", err)
+			}
+			calls = append(calls, Call3{Target: ctfAddr, AllowFailure: true, CallData: calldata})
+		}
+		valid = append(valid, p)
+	}
+
+	results, err := m.safe.Multicall(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string][2]float64, len(valid))
+	for i, p := range valid {
+		up, down := results[2*i], results[2*i+1]
+		if !up.Success || !down.Success || len(up.ReturnData) < 32 || len(down.ReturnData) < 32 {
+			log.Printf("[onchain] balanceOf failed for %s...", p.ConditionID[:8])
+			continue
+		}
+		out[p.ConditionID] = [2]float64{
+			tokenAmount(new(big.Int).SetBytes(up.ReturnData[:32])),
+			tokenAmount(new(big.Int).SetBytes(down.ReturnData[:32])),
+		}
+	}
+	return out, nil
+}
+
+// Resolution returns the payout fraction of UP and DOWN (e.g. [1, 0] when
+// UP won) and whether the condition has been resolved on-chain.
+func (m *Merger) Resolution(ctx context.Context, conditionID string) ([2]float64, bool, error) {
+	var out [2]float64
+	condBytes, err := hexToBytes32(conditionID)
+	if err != nil {
+		return out, false, fmt.Errorf("invalid conditionID %q: %w", conditionID, err)
+	}
+	den, err := m.callUint(ctx, "payoutDenominator", condBytes)
+	if err != nil {
+		return out, false, fmt.Errorf("payoutDenominator: %w", err)
+	}
+	if den.Sign() == 0 {
+		return out, false, nil
+	}
+	for i := range out {
+		num, err := m.callUint(ctx, "payoutNumerators", condBytes, big.NewInt(int64(i)))
+		if err != nil {
+			return out, false, fmt.Errorf("payoutNumerators(%d): %w", i, err)
+		}
+		out[i], _ = new(big.Rat).SetFrac(num, den).Float64()
+	}
+	return out, true, nil
 }
 
 // IsResolved returns true if the condition has been resolved on-chain.
 // (payoutDenominator > 0)
-func (m *Merger) IsResolved(conditionID string) (bool, error) {
-	panic("not implemented")
+func (m *Merger) IsResolved(ctx context.Context, conditionID string) (bool, error) {
+	_, resolved, err := m.Resolution(ctx, conditionID)
+	return resolved, err
 }
 
 // Merge calls mergePositions via the Safe for the given condition.
 // Caps pairs to the actual on-chain balance to prevent reverts.
-// Returns USDC recovered (= pairs merged).
-func (m *Merger) Merge(conditionID string, pairs float64) (float64, error) {
-	panic("not implemented")
+// Returns USDC recovered (= pairs merged) and the tx hash.
+func (m *Merger) Merge(ctx context.Context, conditionID string, pairs float64) (float64, string, error) {
+	condBytes, err := hexToBytes32(conditionID)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid conditionID %q: %w", conditionID, err)
+	}
+
+	onChainPairs, err := m.GetOnChainPairs(ctx, conditionID)
+	if err != nil {
+		return 0, "", err
+	}
+	if onChainPairs < pairs {
+		log.Printf("[onchain] on-chain pairs (%.4f) < inventory (%.4f) — using on-chain", onChainPairs, pairs)
+		pairs = onChainPairs
+	}
+	if pairs < 0.001 {
+		return 0, "", fmt.Errorf("on-chain balance too low to merge")
+	}
+
+	amount := new(big.Int).SetInt64(int64(pairs * 1e6)) // 6 decimals
+	calldata, err := m.ctf.Pack("mergePositions",
+		common.HexToAddress(USDCAddress),
+		[32]byte{}, // parentCollectionId = 0x0
+		condBytes,
+		binaryIndexSets, // partition [UP, DOWN]
+		amount,
+	)
+	if err != nil {
+		return 0, "", fmt.Errorf("pack mergePositions: %w", err)
+	}
+
+	txHash, err := m.safe.ExecTransaction(ctx, ConditionalTokensAddress, calldata, "mergePositions")
+	if err != nil {
+		return 0, txHash, err
+	}
+	return pairs, txHash, nil
+}
+
+// Split calls splitPosition via the Safe, turning usdc of the Safe's USDC
+// into as many UP+DOWN pairs. Needs a USDC allowance for ConditionalTokens
+// (see SetupAllowances). Returns the pairs minted and the tx hash.
+func (m *Merger) Split(ctx context.Context, conditionID string, usdc float64) (float64, string, error) {
+	condBytes, err := hexToBytes32(conditionID)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid conditionID %q: %w", conditionID, err)
+	}
+	amount := new(big.Int).SetInt64(int64(usdc * 1e6)) // 6 decimals
+	if amount.Sign() <= 0 {
+		return 0, "", fmt.Errorf("nothing to split")
+	}
+
+	calldata, err := m.ctf.Pack("splitPosition",
+		common.HexToAddress(USDCAddress),
+		[32]byte{}, // parentCollectionId = 0x0
+		condBytes,
+		binaryIndexSets, // partition [UP, DOWN]
+		amount,
+	)
+	if err != nil {
+		return 0, "", fmt.Errorf("pack splitPosition: %w", err)
+	}
+
+	txHash, err := m.safe.ExecTransaction(ctx, ConditionalTokensAddress, calldata, "splitPosition")
+	if err != nil {
+		return 0, txHash, err
+	}
+	return tokenAmount(amount), txHash, nil
+}
+
+// Redeem calls redeemPositions via the Safe, burning the Safe's UP and
+// DOWN tokens of a resolved condition. upTokenID/downTokenID are the CLOB
+// token IDs (empty = derive them from the condition). Returns the USDC
+// paid out, computed from the pre-redeem balances and payouts, and the tx
+// hash; 0 (and no tx) if the Safe holds none of the condition's tokens.
+func (m *Merger) Redeem(ctx context.Context, conditionID, upTokenID, downTokenID string) (float64, string, error) {
+	condBytes, err := hexToBytes32(conditionID)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid conditionID %q: %w", conditionID, err)
+	}
+
+	payouts, resolved, err := m.Resolution(ctx, conditionID)
+	if err != nil {
+		return 0, "", err
+	}
+	if !resolved {
+		return 0, "", fmt.Errorf("condition %s... not resolved", conditionID[:8])
+	}
+
+	up, err := m.TokenBalance(ctx, tokenID(upTokenID, condBytes, 0))
+	if err != nil {
+		return 0, "", err
+	}
+	down, err := m.TokenBalance(ctx, tokenID(downTokenID, condBytes, 1))
+	if err != nil {
+		return 0, "", err
+	}
+	if up < 0.000001 && down < 0.000001 {
+		return 0, "", nil
+	}
+
+	calldata, err := m.ctf.Pack("redeemPositions",
+		common.HexToAddress(USDCAddress),
+		[32]byte{}, // parentCollectionId = 0x0
+		condBytes,
+		binaryIndexSets, // index sets [UP, DOWN]
+	)
+	if err != nil {
+		return 0, "", fmt.Errorf("pack redeemPositions: %w", err)
+	}
+
+	txHash, err := m.safe.ExecTransaction(ctx, ConditionalTokensAddress, calldata, "redeemPositions")
+	if err != nil {
+		return 0, txHash, err
+	}
+	return up*payouts[0] + down*payouts[1], txHash, nil
+}
+
+// callUint calls a ConditionalTokens view returning a single uint256.
+func (m *Merger) callUint(ctx context.Context, method string, args ...interface{}) (*big.Int, error) {
+	calldata, err := m.ctf.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := m.safe.Call(ctx, common.HexToAddress(ConditionalTokensAddress), calldata)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) < 32 {
+		return nil, fmt.Errorf("unexpected result length: %d", len(result))
+	}
+	return new(big.Int).SetBytes(result[:32]), nil
+}
+
+// ── Token IDs ─────────────────────────────────────────────────────────────
+
+// positionID computes the ERC-1155 token ID for a given condition + outcome index.
+// positionId = keccak256(keccak256(parentCollectionId | conditionId | indexSet))
+// For binary markets: UP=indexSet=1 (binary 01), DOWN=indexSet=2 (binary 10)
+func positionID(conditionID [32]byte, outcomeIndex int) *big.Int {
+	// indexSet: UP = 0b01 = 1, DOWN = 0b10 = 2
+	indexSet := big.NewInt(int64(1 << outcomeIndex))
+
+	// collectionId = keccak256(abi.encodePacked(parentCollectionId, conditionId, indexSet))
+	parentColl := [32]byte{} // zero bytes
+	indexSetBytes := make([]byte, 32)
+	indexSet.FillBytes(indexSetBytes)
+
+	collectionIDInput := append(parentColl[:], conditionID[:]...)
+	collectionIDInput = append(collectionIDInput, indexSetBytes...)
+	collectionID := crypto.Keccak256(collectionIDInput)
+
+	// positionId = keccak256(abi.encodePacked(collateralToken, collectionId))
+	tokenAddrBytes := common.HexToAddress(USDCAddress).Bytes()
+	posInput := append(tokenAddrBytes, collectionID...)
+	posIDBytes := crypto.Keccak256(posInput)
+
+	return new(big.Int).SetBytes(posIDBytes)
+}
+
+// tokenID parses a CLOB token ID, falling back to the derived position ID.
+func tokenID(clobID string, condBytes [32]byte, outcomeIndex int) *big.Int {
+	if id, ok := new(big.Int).SetString(clobID, 10); ok {
+		return id
+	}
+	return positionID(condBytes, outcomeIndex)
+}
+
+// ── Helpers ───────────────────────────────────────────────────────────────
+
+// tokenAmount converts a raw 6-decimal amount (tokens or USDC) to units.
+func tokenAmount(raw *big.Int) float64 {
+	f, _ := new(big.Rat).SetFrac(raw, big.NewInt(1e6)).Float64()
+	return f
+}
+
+func hexToBytes32(hexStr string) ([32]byte, error) {
+	hexStr = strings.TrimPrefix(hexStr, "0x")
+	b, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	var out [32]byte
+	if len(b) > 32 {
+		return [32]byte{}, fmt.Errorf("hex too long: %d bytes", len(b))
+	}
+	copy(out[32-len(b):], b)
+	return out, nil
 }
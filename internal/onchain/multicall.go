@@ -0,0 +1,85 @@
+package onchain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Multicall3Address is the Multicall3 deployment (same address on every chain).
+const Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+const multicall3ABI = `[{
+	"name":"aggregate3",
+	"type":"function",
+	"stateMutability":"payable",
+	"inputs":[{
+		"name":"calls",
+		"type":"tuple[]",
+		"components":[
+			{"name":"target","type":"address"},
+			{"name":"allowFailure","type":"bool"},
+			{"name":"callData","type":"bytes"}
+		]
+	}],
+	"outputs":[{
+		"name":"returnData",
+		"type":"tuple[]",
+		"components":[
+			{"name":"success","type":"bool"},
+			{"name":"returnData","type":"bytes"}
+		]
+	}]
+}]`
+
+// multicallBatch bounds the calls packed into one aggregate3, keeping each
+// eth_call well under RPC gas and payload limits.
+const multicallBatch = 300
+
+// Call3 is one call of a Multicall3 batch.
+type Call3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// Result3 is the outcome of one Call3.
+type Result3 struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// Multicall runs calls as read-only aggregate3 batches and returns their
+// results in order. Calls with AllowFailure report failure in Success
+// instead of failing the batch.
+func (s *Safe) Multicall(ctx context.Context, calls []Call3) ([]Result3, error) {
+	multicall3, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		return nil, fmt.Errorf("Multicall3 ABI: %w", err)
+	}
+	out := make([]Result3, 0, len(calls))
+	for start := 0; start < len(calls); start += multicallBatch {
+		batch := calls[start:min(start+multicallBatch, len(calls))]
+		calldata, err := multicall3.Pack("aggregate3", batch)
+		if err != nil {
+			return nil, fmt.Errorf("pack aggregate3: %w", err)
+		}
+		raw, err := s.Call(ctx, common.HexToAddress(Multicall3Address), calldata)
+		if err != nil {
+			return nil, fmt.Errorf("aggregate3: %w", err)
+		}
+		unpacked, err := multicall3.Unpack("aggregate3", raw)
+		if err != nil || len(unpacked) == 0 {
+			return nil, fmt.Errorf("unpack aggregate3: %v", err)
+		}
+		results := *abi.ConvertType(unpacked[0], new([]Result3)).(*[]Result3)
+		if len(results) != len(batch) {
+			return nil, fmt.Errorf("aggregate3: %d results for %d calls", len(results), len(batch))
+		}
+		out = append(out, results...)
+	}
+	return out, nil
+}
@@ -0,0 +1,87 @@
+package onchain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	cidA = "0x00000000000000000000000000000000000000000000000000000000000000aa"
+	cidB = "0x00000000000000000000000000000000000000000000000000000000000000bb"
+)
+
+var testSafe = common.HexToAddress("0x00000000000000000000000000000000000005af")
+
+// ctfBalances answers aggregate3 batches of ConditionalTokens balanceOf
+// calls for the Safe from held (token ID → raw amount). Unknown tokens fail.
+func ctfBalances(t *testing.T, held map[string]int64) func(common.Address, []byte) ([]byte, error) {
+	t.Helper()
+	multicall3, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctf, err := abi.JSON(strings.NewReader(conditionalTokensABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aggregate3, balanceOf := multicall3.Methods["aggregate3"], ctf.Methods["balanceOf"]
+
+	return func(to common.Address, data []byte) ([]byte, error) {
+		if to != common.HexToAddress(Multicall3Address) {
+			return nil, errors.New("not a Multicall3 call")
+		}
+		args, err := aggregate3.Inputs.Unpack(data[4:])
+		if err != nil {
+			return nil, err
+		}
+		calls := *abi.ConvertType(args[0], new([]Call3)).(*[]Call3)
+		results := make([]Result3, len(calls))
+		for i, c := range calls {
+			if c.Target != common.HexToAddress(ConditionalTokensAddress) {
+				continue
+			}
+			in, err := balanceOf.Inputs.Unpack(c.CallData[4:])
+			if err != nil || in[0].(common.Address) != testSafe {
+				continue
+			}
+			raw, ok := held[in[1].(*big.Int).String()]
+			if !ok {
+				continue
+			}
+			out, _ := balanceOf.Outputs.Pack(big.NewInt(raw))
+			results[i] = Result3{Success: true, ReturnData: out}
+		}
+		return aggregate3.Outputs.Pack(results)
+	}
+}
+
+func TestBalances(t *testing.T) {
+	n := &node{ethCall: ctfBalances(t, map[string]int64{
+		"1001": 12500000, // 12.5 tokens
+		"1002": 0,
+		"2001": 3000000,
+		// 2002 unreadable
+	})}
+	m, err := NewMerger(&Safe{client: newNode(t, n), addr: testSafe})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := m.Balances(context.Background(), []Position{
+		{ConditionID: cidA, UpTokenID: "1001", DownTokenID: "1002"},
+		{ConditionID: "0xnot-hex", UpTokenID: "3001", DownTokenID: "3002"},
+		{ConditionID: cidB, UpTokenID: "2001", DownTokenID: "2002"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[cidA] != [2]float64{12.5, 0} {
+		t.Errorf("balances = %v, want only %s... at {12.5 0}", got, cidA[:8])
+	}
+}
@@ -0,0 +1,21 @@
+// Package onchain handles on-chain operations via go-ethereum.
+//
+// All calls go through the Gnosis Safe (FUNDER_ADDRESS) via execTransaction,
+// because the Safe holds the conditional tokens and USDC — not the EOA directly.
+//
+// Python reference: merger.py, setup_safe_allowances.py
+package onchain
+
+const (
+	// ConditionalTokens contract on Polygon mainnet
+	ConditionalTokensAddress = "0x4D97DCd97eC945f40cF65F87097ACe5EA0476045"
+
+	// USDC.e collateral on Polygon
+	USDCAddress = "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
+
+	// Polymarket exchange contracts (need USDC.approve and CTF
+	// setApprovalForAll from the Safe)
+	CTFExchangeAddress        = "0x4bFb41d5B3570DeFd03C39a9A4D8dE6Bd8B8982E"
+	NegRiskCTFExchangeAddress = "0xC5d563A36AE78145C45a50134d48A1215220f80a"
+	NegRiskAdapterAddress     = "0xd91E80cF2E7be2e162c6513ceD06f1dD0dA35296"
+)
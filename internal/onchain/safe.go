@@ -0,0 +1,210 @@
+package onchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clob"
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+)
+
+const gnosisSafeABI = `[{
+	"name":"execTransaction",
+	"type":"function",
+	"inputs":[
+		{"name":"to","type":"address"},
+		{"name":"value","type":"uint256"},
+		{"name":"data","type":"bytes"},
+		{"name":"operation","type":"uint8"},
+		{"name":"safeTxGas","type":"uint256"},
+		{"name":"baseGas","type":"uint256"},
+		{"name":"gasPrice","type":"uint256"},
+		{"name":"gasToken","type":"address"},
+		{"name":"refundReceiver","type":"address"},
+		{"name":"signatures","type":"bytes"}
+	],
+	"outputs":[{"name":"","type":"bool"}]
+},{
+	"name":"getTransactionHash",
+	"type":"function",
+	"inputs":[
+		{"name":"to","type":"address"},
+		{"name":"value","type":"uint256"},
+		{"name":"data","type":"bytes"},
+		{"name":"operation","type":"uint8"},
+		{"name":"safeTxGas","type":"uint256"},
+		{"name":"baseGas","type":"uint256"},
+		{"name":"gasPrice","type":"uint256"},
+		{"name":"gasToken","type":"address"},
+		{"name":"refundReceiver","type":"address"},
+		{"name":"_nonce","type":"uint256"}
+	],
+	"outputs":[{"name":"","type":"bytes32"}]
+},{
+	"name":"nonce",
+	"type":"function",
+	"inputs":[],
+	"outputs":[{"name":"","type":"uint256"}]
+}]`
+
+// Safe wraps a Gnosis Safe 1.3.0 and provides execTransaction signing.
+// The owner key must be the Safe's only required signer (threshold 1).
+// The outer transactions from the owner go through a TxManager.
+type Safe struct {
+	client *ethclient.Client
+	addr   common.Address
+	key    *ecdsa.PrivateKey
+	owner  common.Address
+	abi    abi.ABI
+	txm    *TxManager
+
+	// One Safe tx at a time: its Safe nonce only advances once mined
+	execMu sync.Mutex
+}
+
+// NewSafe creates a Safe wrapper.
+//   rpcURL       — Polygon RPC endpoint
+//   safeAddress  — Gnosis Safe contract address (FUNDER_ADDRESS)
+//   ownerKey     — MetaMask EOA private key (hex, controls the Safe)
+func NewSafe(rpcURL, safeAddress, ownerKey string) (*Safe, error) {
+	if !common.IsHexAddress(safeAddress) {
+		return nil, fmt.Errorf("invalid Safe address %q", safeAddress)
+	}
+	key, err := clob.ParsePrivateKey(ownerKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner key: %w", err)
+	}
+	parsed, err := abi.JSON(strings.NewReader(gnosisSafeABI))
+	if err != nil {
+		return nil, fmt.Errorf("Safe ABI: %w", err)
+	}
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("connect %s: %w", rpcURL, err)
+	}
+	return &Safe{
+		client: client,
+		addr:   common.HexToAddress(safeAddress),
+		key:    key,
+		owner:  clob.AddressFromKey(key),
+		abi:    parsed,
+		txm:    NewTxManager(client, key, big.NewInt(config.ChainID), config.TxStateFile),
+	}, nil
+}
+
+// Address returns the Safe's address.
+func (s *Safe) Address() common.Address { return s.addr }
+
+// Owner returns the address of the signing EOA.
+func (s *Safe) Owner() common.Address { return s.owner }
+
+// Client returns the underlying RPC client.
+func (s *Safe) Client() *ethclient.Client { return s.client }
+
+// Call runs a read-only call against to and returns the raw result.
+func (s *Safe) Call(ctx context.Context, to common.Address, data []byte) ([]byte, error) {
+	return s.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+}
+
+// Nonce returns the Safe's current transaction nonce.
+func (s *Safe) Nonce(ctx context.Context) (*big.Int, error) {
+	calldata, _ := s.abi.Pack("nonce")
+	result, err := s.Call(ctx, s.addr, calldata)
+	if err != nil {
+		return nil, fmt.Errorf("get safe nonce: %w", err)
+	}
+	if len(result) < 32 {
+		return big.NewInt(0), nil
+	}
+	return new(big.Int).SetBytes(result[:32]), nil
+}
+
+// ExecTransaction signs a CALL to `to` with the owner key, submits it
+// through execTransaction and waits for the receipt (up to TX_TIMEOUT_SEC,
+// or until ctx is done). label names the call in logs. Returns the tx hash.
+func (s *Safe) ExecTransaction(ctx context.Context, to string, data []byte, label string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.TxTimeoutSec)*time.Second)
+	defer cancel()
+
+	s.execMu.Lock()
+	defer s.execMu.Unlock()
+
+	// A Safe tx still pending from earlier holds the Safe nonce we would read
+	if err := s.txm.WaitPending(ctx); err != nil {
+		return "", err
+	}
+
+	target := common.HexToAddress(to)
+	nonce, err := s.Nonce(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Ask the Safe for the hash of the Safe tx, then sign it
+	zero := big.NewInt(0)
+	zeroAddr := common.Address{}
+	hashCalldata, err := s.abi.Pack("getTransactionHash",
+		target, zero, data,
+		uint8(0), // operation: CALL
+		zero, zero, zero, zeroAddr, zeroAddr,
+		nonce,
+	)
+	if err != nil {
+		return "", fmt.Errorf("pack getTransactionHash: %w", err)
+	}
+	hashResult, err := s.Call(ctx, s.addr, hashCalldata)
+	if err != nil {
+		return "", fmt.Errorf("getTransactionHash call: %w", err)
+	}
+	if len(hashResult) < 32 {
+		return "", fmt.Errorf("unexpected hash result length: %d", len(hashResult))
+	}
+	sig, err := crypto.Sign(hashResult[:32], s.key)
+	if err != nil {
+		return "", fmt.Errorf("sign safe tx: %w", err)
+	}
+	// Safe expects v = 27/28, not 0/1
+	sig[64] += 27
+
+	execCalldata, err := s.abi.Pack("execTransaction",
+		target, zero, data,
+		uint8(0), // CALL
+		zero, zero, zero, zeroAddr, zeroAddr,
+		sig,
+	)
+	if err != nil {
+		return "", fmt.Errorf("pack execTransaction: %w", err)
+	}
+
+	// Outer transaction from the owner EOA to the Safe
+	receipt, err := s.txm.Send(ctx, s.addr, execCalldata, label)
+	if err != nil {
+		return "", err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt.TxHash.Hex(), fmt.Errorf("tx reverted in block %d", receipt.BlockNumber)
+	}
+	return receipt.TxHash.Hex(), nil
+}
+
+// gasPaid returns the fee of a mined tx in POL.
+func gasPaid(r *types.Receipt) float64 {
+	if r.EffectiveGasPrice == nil {
+		return 0
+	}
+	wei := new(big.Int).Mul(new(big.Int).SetUint64(r.GasUsed), r.EffectiveGasPrice)
+	pol, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18)).Float64()
+	return pol
+}
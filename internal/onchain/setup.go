@@ -1,13 +1,175 @@
 package onchain
 
-// SetupAllowances sets USDC.approve(MAX_INT) for all three Polymarket
-// exchange contracts via Safe execTransaction.
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+const erc20ABI = `[{
+	"name":"allowance",
+	"type":"function",
+	"inputs":[
+		{"name":"owner","type":"address"},
+		{"name":"spender","type":"address"}
+	],
+	"outputs":[{"name":"","type":"uint256"}]
+},{
+	"name":"approve",
+	"type":"function",
+	"inputs":[
+		{"name":"spender","type":"address"},
+		{"name":"amount","type":"uint256"}
+	],
+	"outputs":[{"name":"","type":"bool"}]
+}]`
+
+// minAllowance is the USDC allowance (raw, 6 decimals) below which an
+// approval is renewed: anything under 1e12 USDC was not a MAX approval.
+var minAllowance = new(big.Int).Mul(big.NewInt(1e12), big.NewInt(1e6))
+
+// Spender is a contract the Safe must approve before trading.
+type Spender struct {
+	Name     string
+	Address  string
+	Operator bool // also needs ConditionalTokens.setApprovalForAll
+}
+
+// Spenders are the three Polymarket exchange contracts, plus
+// ConditionalTokens itself, which pulls USDC in splitPosition.
+var Spenders = []Spender{
+	{"CTF Exchange", CTFExchangeAddress, true},
+	{"Neg Risk CTF Exchange", NegRiskCTFExchangeAddress, true},
+	{"Neg Risk Adapter", NegRiskAdapterAddress, true},
+	{"ConditionalTokens", ConditionalTokensAddress, false},
+}
+
+// Allowance is the approval state of one spender.
+type Allowance struct {
+	Spender
+	USDC        float64 // USDC.approve allowance
+	CTFApproved bool    // ConditionalTokens.setApprovalForAll (true when not needed)
+}
+
+// CheckAllowances reads the Safe's USDC allowance and CTF operator approval
+// for every spender.
+func CheckAllowances(ctx context.Context, safe *Safe) ([]Allowance, error) {
+	erc20, ctf, err := setupABIs()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Allowance, 0, len(Spenders))
+	for _, sp := range Spenders {
+		spender := common.HexToAddress(sp.Address)
+
+		calldata, _ := erc20.Pack("allowance", safe.Address(), spender)
+		res, err := safe.Call(ctx, common.HexToAddress(USDCAddress), calldata)
+		if err != nil || len(res) < 32 {
+			return nil, fmt.Errorf("USDC allowance for %s: %v", sp.Name, err)
+		}
+		a := Allowance{Spender: sp, USDC: tokenAmount(new(big.Int).SetBytes(res[:32])), CTFApproved: true}
+
+		if sp.Operator {
+			calldata, _ = ctf.Pack("isApprovedForAll", safe.Address(), spender)
+			res, err = safe.Call(ctx, common.HexToAddress(ConditionalTokensAddress), calldata)
+			if err != nil || len(res) < 32 {
+				return nil, fmt.Errorf("CTF isApprovedForAll for %s: %v", sp.Name, err)
+			}
+			a.CTFApproved = new(big.Int).SetBytes(res[:32]).Sign() != 0
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// SetupAllowances sets USDC.approve(MAX_INT) and
+// ConditionalTokens.setApprovalForAll(true) for all three Polymarket
+// exchange contracts, and USDC.approve(MAX_INT) for ConditionalTokens
+// (splitPosition), via Safe execTransaction. Approvals already in place
+// are skipped, so it is safe to re-run. Returns the number of approvals
+// that were missing (and, unless checkOnly, have now been sent).
 //
 // One-time setup required for each new Gnosis Safe wallet.
 // Python reference: setup_safe_allowances.py
 //
-//   safe    — initialized Safe wrapper
-//   check   — if true, only print current state without sending txs
-func SetupAllowances(safe *Safe, checkOnly bool) error {
-	panic("not implemented")
+//   safe       — initialized Safe wrapper
+//   checkOnly  — if true, only print current state without sending txs
+func SetupAllowances(ctx context.Context, safe *Safe, checkOnly bool) (int, error) {
+	erc20, ctf, err := setupABIs()
+	if err != nil {
+		return 0, err
+	}
+	state, err := CheckAllowances(ctx, safe)
+	if err != nil {
+		return 0, err
+	}
+
+	missing := 0
+	log.Printf("[onchain] Safe %s | owner %s", safe.Address().Hex(), safe.Owner().Hex())
+	for _, a := range state {
+		ctfState := fmt.Sprint(a.CTFApproved)
+		if !a.Operator {
+			ctfState = "n/a"
+		}
+		log.Printf("[onchain] %-22s USDC allowance=%s | CTF approved=%s",
+			a.Name, formatAllowance(a.USDC), ctfState)
+		if a.USDC < tokenAmount(minAllowance) {
+			missing++
+		}
+		if !a.CTFApproved {
+			missing++
+		}
+	}
+	if checkOnly || missing == 0 {
+		return missing, nil
+	}
+
+	for _, a := range state {
+		spender := common.HexToAddress(a.Address)
+		if a.USDC < tokenAmount(minAllowance) {
+			calldata, err := erc20.Pack("approve", spender, math.MaxBig256)
+			if err != nil {
+				return missing, fmt.Errorf("pack approve: %w", err)
+			}
+			if _, err := safe.ExecTransaction(ctx, USDCAddress, calldata, "USDC.approve "+a.Name); err != nil {
+				return missing, fmt.Errorf("USDC.approve %s: %w", a.Name, err)
+			}
+		}
+		if !a.CTFApproved {
+			calldata, err := ctf.Pack("setApprovalForAll", spender, true)
+			if err != nil {
+				return missing, fmt.Errorf("pack setApprovalForAll: %w", err)
+			}
+			if _, err := safe.ExecTransaction(ctx, ConditionalTokensAddress, calldata, "CTF.setApprovalForAll "+a.Name); err != nil {
+				return missing, fmt.Errorf("CTF.setApprovalForAll %s: %w", a.Name, err)
+			}
+		}
+	}
+	log.Printf("[onchain] ✅ %d approvals set", missing)
+	return missing, nil
+}
+
+func setupABIs() (abi.ABI, abi.ABI, error) {
+	erc20, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return abi.ABI{}, abi.ABI{}, fmt.Errorf("ERC-20 ABI: %w", err)
+	}
+	ctf, err := abi.JSON(strings.NewReader(conditionalTokensABI))
+	if err != nil {
+		return abi.ABI{}, abi.ABI{}, fmt.Errorf("ConditionalTokens ABI: %w", err)
+	}
+	return erc20, ctf, nil
+}
+
+func formatAllowance(usdc float64) string {
+	if usdc >= tokenAmount(minAllowance) {
+		return "MAX"
+	}
+	return fmt.Sprintf("$%.2f", usdc)
 }
@@ -0,0 +1,452 @@
+package onchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clob"
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/fileutil"
+	"github.com/gipsh/polymarket-bot-go/internal/metrics"
+)
+
+// ErrTxReplaced is returned when a transaction's nonce was consumed by a
+// transaction the manager did not send (e.g. one sent by hand).
+var ErrTxReplaced = errors.New("nonce consumed by another transaction")
+
+const (
+	txPollInterval = 3 * time.Second
+	txBumpPercent  = 20 // fee increase per replacement (nodes require ≥10%)
+	txGasFallback  = 500000
+)
+
+// TxManager sends EIP-1559 transactions from one EOA and sees them mined.
+//
+// Nonces are tracked locally (never below the node's pending nonce), so
+// back-to-back sends do not collide. While a transaction waits it is
+// re-broadcast with fees bumped by 20% every TX_BUMP_AFTER_SEC, capped at
+// TX_MAX_FEE_GWEI; one dropped from the mempool is re-broadcast as is; one
+// whose nonce was used by someone else is reported as ErrTxReplaced.
+//
+// Pending transactions are saved to TX_STATE_FILE, so after a restart (or
+// a Send that gave up waiting) the next Send first sees them through
+// instead of reusing their nonce.
+type TxManager struct {
+	client   *ethclient.Client
+	key      *ecdsa.PrivateKey
+	from     common.Address
+	signer   types.Signer
+	filepath string
+
+	mu        sync.Mutex // held for a whole Send: one transaction at a time
+	nextNonce uint64
+	pending   map[uint64]*pendingTx
+}
+
+// pendingTx is a sent transaction not yet mined. Every attempt with its
+// nonce is kept, since any of them may be the one that gets mined.
+type pendingTx struct {
+	Nonce    uint64        `json:"nonce"`
+	Label    string        `json:"label"`
+	Raw      hexutil.Bytes `json:"raw"`       // latest signed attempt
+	Hashes   []common.Hash `json:"hashes"`    // every attempt, oldest first
+	SentAt   time.Time     `json:"sent_at"`   // first broadcast
+	BumpedAt time.Time     `json:"bumped_at"` // latest broadcast
+}
+
+func (p *pendingTx) tx() (*types.Transaction, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(p.Raw); err != nil {
+		return nil, fmt.Errorf("decode pending tx %d: %w", p.Nonce, err)
+	}
+	return tx, nil
+}
+
+// NewTxManager creates a TxManager for the EOA of key, reloading the
+// pending transactions saved in filepath (empty = not persisted).
+func NewTxManager(client *ethclient.Client, key *ecdsa.PrivateKey, chainID *big.Int, filepath string) *TxManager {
+	m := &TxManager{
+		client:   client,
+		key:      key,
+		from:     clob.AddressFromKey(key),
+		signer:   types.LatestSignerForChainID(chainID),
+		filepath: filepath,
+		pending:  make(map[uint64]*pendingTx),
+	}
+	m.load()
+	if len(m.pending) > 0 {
+		log.Printf("[onchain] %d pending txs restored from %s", len(m.pending), filepath)
+	}
+	return m
+}
+
+// Send signs and broadcasts a transaction calling to with data, then waits
+// until it (or one of its replacements) is mined or ctx is done. label
+// names the call in logs. A reverted transaction is returned with its
+// receipt and no error; the caller checks receipt.Status.
+//
+// If ctx expires first the transaction stays pending and the next Send
+// waits for it before using a new nonce.
+func (m *TxManager) Send(ctx context.Context, to common.Address, data []byte, label string) (*types.Receipt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.drain(ctx); err != nil {
+		return nil, err
+	}
+
+	gas, err := m.client.EstimateGas(ctx, ethereum.CallMsg{From: m.from, To: &to, Data: data})
+	if err != nil {
+		log.Printf("[onchain] %s: gas estimate failed, using %d: %v", label, txGasFallback, err)
+		gas = txGasFallback
+	}
+	gas = gas * 12 / 10 // +20% buffer
+
+	tip, feeCap, err := m.fees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var p *pendingTx
+	for attempt := 0; ; attempt++ {
+		nonce, err := m.nonce(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := types.SignNewTx(m.key, m.signer, &types.DynamicFeeTx{
+			Nonce:     nonce,
+			GasTipCap: tip,
+			GasFeeCap: feeCap,
+			Gas:       gas,
+			To:        &to,
+			Data:      data,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("sign tx: %w", err)
+		}
+		err = m.client.SendTransaction(ctx, tx)
+		if err != nil && attempt == 0 && isNonceTooLow(err) {
+			// Someone else used the nonce: resync from the node and retry once
+			log.Printf("[onchain] %s: nonce %d already used, resyncing", label, nonce)
+			m.nextNonce = 0
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("send tx: %w", err)
+		}
+
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("encode tx: %w", err)
+		}
+		now := time.Now()
+		p = &pendingTx{
+			Nonce:    nonce,
+			Label:    label,
+			Raw:      raw,
+			Hashes:   []common.Hash{tx.Hash()},
+			SentAt:   now,
+			BumpedAt: now,
+		}
+		m.nextNonce = nonce + 1
+		m.pending[nonce] = p
+		m.save()
+		log.Printf("[onchain] %s tx broadcast: %s (nonce %d, tip %s gwei, max fee %s gwei)",
+			label, tx.Hash().Hex(), nonce, gwei(tip), gwei(feeCap))
+		break
+	}
+
+	return m.wait(ctx, p)
+}
+
+// WaitPending waits until no transaction sent earlier is still pending.
+func (m *TxManager) WaitPending(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.drain(ctx)
+}
+
+// drain waits for every transaction left pending by an earlier Send or a
+// previous run, oldest nonce first. Caller holds m.mu.
+func (m *TxManager) drain(ctx context.Context) error {
+	nonces := make([]uint64, 0, len(m.pending))
+	for n := range m.pending {
+		nonces = append(nonces, n)
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+
+	for _, n := range nonces {
+		p := m.pending[n]
+		log.Printf("[onchain] waiting for earlier %s tx (nonce %d) before sending", p.Label, p.Nonce)
+		if _, err := m.wait(ctx, p); err != nil && !errors.Is(err, ErrTxReplaced) {
+			return fmt.Errorf("earlier %s tx (nonce %d) still pending: %w", p.Label, p.Nonce, err)
+		}
+	}
+	return nil
+}
+
+// nonce returns the next nonce to use: the local counter, or the node's
+// pending nonce if that is ahead. Caller holds m.mu.
+func (m *TxManager) nonce(ctx context.Context) (uint64, error) {
+	chain, err := m.client.PendingNonceAt(ctx, m.from)
+	if err != nil {
+		return 0, fmt.Errorf("get signer nonce: %w", err)
+	}
+	if chain > m.nextNonce {
+		m.nextNonce = chain
+	}
+	return m.nextNonce, nil
+}
+
+// fees returns the suggested priority fee and a fee cap of twice the
+// current base fee plus that tip, capped at TX_MAX_FEE_GWEI.
+func (m *TxManager) fees(ctx context.Context) (tip, feeCap *big.Int, err error) {
+	tip, err = m.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gas tip: %w", err)
+	}
+	head, err := m.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("latest header: %w", err)
+	}
+	feeCap = new(big.Int).Set(tip)
+	if head.BaseFee != nil {
+		feeCap.Add(feeCap, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+	}
+	limit := maxFee()
+	if feeCap.Cmp(limit) > 0 {
+		feeCap = limit
+	}
+	if tip.Cmp(feeCap) > 0 {
+		tip = new(big.Int).Set(feeCap)
+	}
+	return tip, feeCap, nil
+}
+
+// wait polls until one of p's attempts is mined. Meanwhile it re-broadcasts
+// p if the node dropped it and bumps its fees every TX_BUMP_AFTER_SEC.
+// Caller holds m.mu.
+func (m *TxManager) wait(ctx context.Context, p *pendingTx) (*types.Receipt, error) {
+	bumpAfter := time.Duration(config.TxBumpAfterSec) * time.Second
+	consumed := false
+	for {
+		// Any attempt may have been mined, not just the latest
+		for _, h := range p.Hashes {
+			receipt, err := m.client.TransactionReceipt(ctx, h)
+			if err != nil {
+				continue
+			}
+			m.done(p)
+			// Gas is paid whether or not the tx reverted
+			metrics.MergeTx(gasPaid(receipt), time.Since(p.SentAt))
+			log.Printf("[onchain] %s tx %s mined in block %d (status %d)",
+				p.Label, h.Hex(), receipt.BlockNumber, receipt.Status)
+			return receipt, nil
+		}
+
+		mined, err := m.client.NonceAt(ctx, m.from, nil)
+		switch {
+		case err != nil:
+			log.Printf("[onchain] %s: nonce check failed: %v", p.Label, err)
+		case mined > p.Nonce && consumed:
+			// Nonce used, but by none of our attempts (checked twice, in
+			// case the receipt was not indexed yet)
+			m.done(p)
+			metrics.TxRebroadcast("replaced")
+			log.Printf("[onchain] ⚠ %s tx (nonce %d) replaced by an unknown transaction", p.Label, p.Nonce)
+			return nil, ErrTxReplaced
+		case mined > p.Nonce:
+			consumed = true
+		case bumpAfter > 0 && time.Since(p.BumpedAt) >= bumpAfter:
+			if err := m.bump(ctx, p); err != nil {
+				log.Printf("[onchain] %s: fee bump failed: %v", p.Label, err)
+			}
+		default:
+			latest := p.Hashes[len(p.Hashes)-1]
+			if _, _, err := m.client.TransactionByHash(ctx, latest); errors.Is(err, ethereum.NotFound) {
+				m.rebroadcast(ctx, p)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("[onchain] %s tx (nonce %d) not mined yet, still tracked: %s",
+				p.Label, p.Nonce, p.Hashes[len(p.Hashes)-1].Hex())
+			return nil, ctx.Err()
+		case <-time.After(txPollInterval):
+		}
+	}
+}
+
+// bump replaces p with the same transaction at higher fees: the current
+// suggestion, but at least 20% over the last attempt. At TX_MAX_FEE_GWEI
+// the last attempt is re-broadcast unchanged instead.
+func (m *TxManager) bump(ctx context.Context, p *pendingTx) error {
+	old, err := p.tx()
+	if err != nil {
+		return err
+	}
+	tip, feeCap, err := m.fees(ctx)
+	if err != nil {
+		return err
+	}
+	minTip, minCap := bumped(old.GasTipCap()), bumped(old.GasFeeCap())
+	if minCap.Cmp(maxFee()) > 0 {
+		log.Printf("[onchain] %s tx (nonce %d) at max fee %s gwei — re-broadcasting",
+			p.Label, p.Nonce, gwei(old.GasFeeCap()))
+		m.rebroadcast(ctx, p)
+		return nil
+	}
+	if feeCap.Cmp(minCap) < 0 {
+		feeCap = minCap
+	}
+	if tip.Cmp(minTip) < 0 {
+		tip = minTip
+	}
+	if tip.Cmp(feeCap) > 0 {
+		tip = new(big.Int).Set(feeCap)
+	}
+
+	tx, err := types.SignNewTx(m.key, m.signer, &types.DynamicFeeTx{
+		Nonce:     old.Nonce(),
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       old.Gas(),
+		To:        old.To(),
+		Data:      old.Data(),
+	})
+	if err != nil {
+		return fmt.Errorf("sign replacement: %w", err)
+	}
+	p.BumpedAt = time.Now()
+	if err := m.client.SendTransaction(ctx, tx); err != nil {
+		m.save()
+		return fmt.Errorf("send replacement: %w", err)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("encode replacement: %w", err)
+	}
+	p.Raw = raw
+	p.Hashes = append(p.Hashes, tx.Hash())
+	m.save()
+	metrics.TxRebroadcast("bump")
+	log.Printf("[onchain] %s tx (nonce %d) bumped: %s (tip %s gwei, max fee %s gwei)",
+		p.Label, p.Nonce, tx.Hash().Hex(), gwei(tip), gwei(feeCap))
+	return nil
+}
+
+// rebroadcast re-sends p's latest attempt as is, e.g. after the node
+// dropped it from its mempool.
+func (m *TxManager) rebroadcast(ctx context.Context, p *pendingTx) {
+	tx, err := p.tx()
+	if err != nil {
+		log.Printf("[onchain] %s: %v", p.Label, err)
+		return
+	}
+	p.BumpedAt = time.Now()
+	m.save()
+	if err := m.client.SendTransaction(ctx, tx); err != nil {
+		if !strings.Contains(err.Error(), "already known") {
+			log.Printf("[onchain] %s: re-broadcast failed: %v", p.Label, err)
+		}
+		return
+	}
+	metrics.TxRebroadcast("dropped")
+	log.Printf("[onchain] %s tx (nonce %d) re-broadcast: %s", p.Label, p.Nonce, tx.Hash().Hex())
+}
+
+// done forgets a mined (or replaced) transaction.
+func (m *TxManager) done(p *pendingTx) {
+	delete(m.pending, p.Nonce)
+	m.save()
+}
+
+// ── Persistence ───────────────────────────────────────────────────────────
+
+func (m *TxManager) load() {
+	if m.filepath == "" {
+		return
+	}
+	if _, err := os.Stat(m.filepath); os.IsNotExist(err) {
+		return
+	}
+	data, err := os.ReadFile(m.filepath)
+	if err != nil {
+		log.Printf("[onchain] tx state load error: %v — starting fresh", err)
+		return
+	}
+	var saved []*pendingTx
+	if err := json.Unmarshal(data, &saved); err != nil {
+		bad := fmt.Sprintf("%s.corrupt-%d", m.filepath, time.Now().Unix())
+		os.Rename(m.filepath, bad)
+		log.Printf("[onchain] ⚠ tx state parse error: %v — moved to %s, starting fresh (pending txs it listed are no longer tracked)", err, bad)
+		return
+	}
+	for _, p := range saved {
+		if len(p.Hashes) == 0 {
+			continue
+		}
+		m.pending[p.Nonce] = p
+		if p.Nonce >= m.nextNonce {
+			m.nextNonce = p.Nonce + 1
+		}
+	}
+}
+
+func (m *TxManager) save() {
+	if m.filepath == "" {
+		return
+	}
+	saved := make([]*pendingTx, 0, len(m.pending))
+	for _, p := range m.pending {
+		saved = append(saved, p)
+	}
+	sort.Slice(saved, func(i, j int) bool { return saved[i].Nonce < saved[j].Nonce })
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err == nil {
+		err = fileutil.WriteAtomic(m.filepath, data, 0600)
+	}
+	if err != nil {
+		log.Printf("[onchain] tx state save error: %v", err)
+	}
+}
+
+// ── Helpers ───────────────────────────────────────────────────────────────
+
+func isNonceTooLow(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+// maxFee is TX_MAX_FEE_GWEI in wei.
+func maxFee() *big.Int {
+	wei, _ := new(big.Float).Mul(big.NewFloat(config.TxMaxFeeGwei), big.NewFloat(1e9)).Int(nil)
+	return wei
+}
+
+// bumped returns v raised by txBumpPercent, rounded up.
+func bumped(v *big.Int) *big.Int {
+	out := new(big.Int).Mul(v, big.NewInt(100+txBumpPercent))
+	out.Add(out, big.NewInt(99))
+	return out.Div(out, big.NewInt(100))
+}
+
+func gwei(wei *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e9)).Text('f', 2)
+}
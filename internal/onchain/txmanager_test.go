@@ -0,0 +1,314 @@
+package onchain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+)
+
+var (
+	testChainID = big.NewInt(137)
+	testTo      = common.HexToAddress("0x4D97DCd97eC945f40cF65F87097ACe5EA0476045")
+)
+
+func gweiInt(g int64) *big.Int { return new(big.Int).Mul(big.NewInt(g), big.NewInt(1e9)) }
+
+// node is a JSON-RPC endpoint answering the calls TxManager makes, and
+// eth_call through ethCall.
+type node struct {
+	mu       sync.Mutex
+	baseFee  *big.Int
+	tip      *big.Int
+	pending  uint64 // reported pending nonce
+	latest   uint64 // reported mined nonce
+	mineFrom int    // the n-th sent tx and later ones are mined (0 = none)
+	sent     []*types.Transaction
+	mined    map[common.Hash]bool
+
+	// ethCall answers eth_call (nil = unsupported)
+	ethCall func(to common.Address, data []byte) ([]byte, error)
+}
+
+func newNode(t *testing.T, n *node) *ethclient.Client {
+	t.Helper()
+	n.mined = make(map[common.Hash]bool)
+	srv := httptest.NewServer(n)
+	t.Cleanup(srv.Close)
+	client, err := ethclient.Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func (n *node) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := n.call(req.Method, req.Params)
+	resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+	if err != nil {
+		resp["error"] = map[string]any{"code": -32000, "message": err.Error()}
+	} else {
+		resp["result"] = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (n *node) call(method string, params []json.RawMessage) (any, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var arg string
+	if len(params) > 0 {
+		json.Unmarshal(params[0], &arg)
+	}
+	switch method {
+	case "eth_estimateGas":
+		return hexutil.Uint64(100000), nil
+	case "eth_maxPriorityFeePerGas":
+		return (*hexutil.Big)(n.tip), nil
+	case "eth_getBlockByNumber":
+		return &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0), BaseFee: n.baseFee, Extra: []byte{}}, nil
+	case "eth_getTransactionCount":
+		var block string
+		json.Unmarshal(params[1], &block)
+		if block == "pending" {
+			return hexutil.Uint64(n.pending), nil
+		}
+		return hexutil.Uint64(n.latest), nil
+	case "eth_sendRawTransaction":
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(common.FromHex(arg)); err != nil {
+			return nil, err
+		}
+		for _, s := range n.sent {
+			if s.Hash() == tx.Hash() {
+				return nil, errors.New("already known")
+			}
+		}
+		n.sent = append(n.sent, tx)
+		if n.mineFrom > 0 && len(n.sent) >= n.mineFrom {
+			n.mine(tx)
+		}
+		return tx.Hash(), nil
+	case "eth_getTransactionReceipt":
+		h := common.HexToHash(arg)
+		if !n.mined[h] {
+			return nil, nil
+		}
+		return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: h, GasUsed: 100000,
+			BlockNumber: big.NewInt(2), EffectiveGasPrice: n.baseFee, Logs: []*types.Log{}}, nil
+	case "eth_call":
+		if n.ethCall == nil {
+			break
+		}
+		var msg struct {
+			To    common.Address `json:"to"`
+			Input hexutil.Bytes  `json:"input"`
+			Data  hexutil.Bytes  `json:"data"`
+		}
+		if err := json.Unmarshal(params[0], &msg); err != nil {
+			return nil, err
+		}
+		if msg.Input == nil {
+			msg.Input = msg.Data
+		}
+		out, err := n.ethCall(msg.To, msg.Input)
+		return hexutil.Bytes(out), err
+	case "eth_getTransactionByHash":
+		for _, s := range n.sent {
+			if s.Hash() == common.HexToHash(arg) {
+				return s, nil
+			}
+		}
+		return nil, nil
+	}
+	return nil, errors.New("unsupported method " + method)
+}
+
+func (n *node) mine(tx *types.Transaction) {
+	n.mined[tx.Hash()] = true
+	if tx.Nonce() >= n.latest {
+		n.latest = tx.Nonce() + 1
+	}
+}
+
+func (n *node) txs() []*types.Transaction {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]*types.Transaction(nil), n.sent...)
+}
+
+// txConfig sets the fee settings for one test.
+func txConfig(t *testing.T, maxFeeGwei float64, bumpAfterSec int) {
+	t.Helper()
+	maxFee, bumpAfter := config.TxMaxFeeGwei, config.TxBumpAfterSec
+	t.Cleanup(func() { config.TxMaxFeeGwei, config.TxBumpAfterSec = maxFee, bumpAfter })
+	config.TxMaxFeeGwei, config.TxBumpAfterSec = maxFeeGwei, bumpAfterSec
+}
+
+func newManager(t *testing.T, client *ethclient.Client, path string) *TxManager {
+	t.Helper()
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewTxManager(client, key, testChainID, path)
+}
+
+// savedPending returns how many pending txs the state file lists.
+func savedPending(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved []*pendingTx
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	return len(saved)
+}
+
+func TestSendTracksNonceAndCapsFee(t *testing.T) {
+	txConfig(t, 200, 0)
+	// A lagging node keeps reporting nonce 5 as pending
+	n := &node{baseFee: gweiInt(100), tip: gweiInt(30), pending: 5, latest: 5, mineFrom: 1}
+	path := filepath.Join(t.TempDir(), "tx_state.json")
+	m := newManager(t, newNode(t, n), path)
+
+	for i := 0; i < 2; i++ {
+		receipt, err := m.Send(context.Background(), testTo, []byte{0x01}, "merge")
+		if err != nil || receipt.Status != types.ReceiptStatusSuccessful {
+			t.Fatalf("send %d: receipt %v, err %v", i, receipt, err)
+		}
+	}
+
+	sent := n.txs()
+	if len(sent) != 2 {
+		t.Fatalf("%d txs broadcast, want 2", len(sent))
+	}
+	for i, tx := range sent {
+		if tx.Nonce() != uint64(5+i) {
+			t.Errorf("tx %d nonce = %d, want %d", i, tx.Nonce(), 5+i)
+		}
+		if tx.Type() != types.DynamicFeeTxType {
+			t.Errorf("tx %d type = %d, want EIP-1559", i, tx.Type())
+		}
+		// 2 × 100 gwei base fee + 30 gwei tip, capped at 200 gwei
+		if tx.GasFeeCap().Cmp(gweiInt(200)) != 0 || tx.GasTipCap().Cmp(gweiInt(30)) != 0 {
+			t.Errorf("tx %d fees = max %s / tip %s gwei, want 200 / 30", i, gwei(tx.GasFeeCap()), gwei(tx.GasTipCap()))
+		}
+	}
+	if k := savedPending(t, path); k != 0 {
+		t.Errorf("%d txs still saved as pending after both were mined", k)
+	}
+}
+
+func TestSendBumpsStuckTx(t *testing.T) {
+	txConfig(t, 1000, 1)
+	// Only the replacement gets mined
+	n := &node{baseFee: gweiInt(100), tip: gweiInt(30), mineFrom: 2}
+	m := newManager(t, newNode(t, n), "")
+
+	receipt, err := m.Send(context.Background(), testTo, []byte{0x01}, "merge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := n.txs()
+	if len(sent) != 2 || receipt.TxHash != sent[1].Hash() {
+		t.Fatalf("%d txs broadcast, mined %s; want the replacement of the first mined", len(sent), receipt.TxHash.Hex())
+	}
+	first, second := sent[0], sent[1]
+	if second.Nonce() != first.Nonce() {
+		t.Errorf("replacement nonce = %d, want %d", second.Nonce(), first.Nonce())
+	}
+	if second.GasFeeCap().Cmp(bumped(first.GasFeeCap())) < 0 || second.GasTipCap().Cmp(bumped(first.GasTipCap())) < 0 {
+		t.Errorf("replacement fees max %s / tip %s gwei, want at least 20%% over %s / %s",
+			gwei(second.GasFeeCap()), gwei(second.GasTipCap()), gwei(first.GasFeeCap()), gwei(first.GasTipCap()))
+	}
+}
+
+func TestSendReplaced(t *testing.T) {
+	txConfig(t, 1000, 0)
+	n := &node{baseFee: gweiInt(100), tip: gweiInt(30)}
+	m := newManager(t, newNode(t, n), "")
+	// The node never mines our tx but its nonce gets used anyway
+	n.latest = 1
+
+	if _, err := m.Send(context.Background(), testTo, []byte{0x01}, "merge"); !errors.Is(err, ErrTxReplaced) {
+		t.Errorf("err = %v, want ErrTxReplaced", err)
+	}
+	if len(m.pending) != 0 {
+		t.Errorf("%d txs still pending", len(m.pending))
+	}
+}
+
+func TestPendingRestoredAfterRestart(t *testing.T) {
+	txConfig(t, 1000, 0)
+	n := &node{baseFee: gweiInt(100), tip: gweiInt(30)}
+	client := newNode(t, n)
+	path := filepath.Join(t.TempDir(), "tx_state.json")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := newManager(t, client, path).Send(ctx, testTo, []byte{0x01}, "merge"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the deadline", err)
+	}
+	if k := savedPending(t, path); k != 1 {
+		t.Fatalf("%d txs saved as pending, want 1", k)
+	}
+
+	// Restarted, the tx is seen through before anything else is sent
+	m := newManager(t, client, path)
+	if len(m.pending) != 1 {
+		t.Fatalf("%d pending txs restored, want 1", len(m.pending))
+	}
+	n.mu.Lock()
+	n.mine(n.sent[0])
+	n.mu.Unlock()
+	if err := m.WaitPending(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if k := savedPending(t, path); k != 0 {
+		t.Errorf("%d txs still saved as pending after it was mined", k)
+	}
+}
+
+func TestCorruptTxStateSetAside(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx_state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m := newManager(t, nil, path)
+	if len(m.pending) != 0 {
+		t.Errorf("%d pending txs loaded from a corrupt file", len(m.pending))
+	}
+	if bad, _ := filepath.Glob(path + ".corrupt-*"); len(bad) != 1 {
+		t.Errorf("corrupt state not set aside: %v", bad)
+	}
+}
@@ -0,0 +1,475 @@
+// Package orders manages resting (GTC/GTD) limit orders.
+//
+// The Manager tracks every open order it posts together with its
+// expiration, cancels and replaces quotes when the target price moves,
+// cancels everything on shutdown, and books fills into inventory from the
+// user WebSocket (not from the POST /order response, which for a resting
+// order says nothing about later fills).
+package orders
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clob"
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/store"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+const (
+	repriceTick = 0.01             // replace a quote once its target moves a full tick
+	gtdSafety   = 60 * time.Second // CLOB rejects GTD orders expiring within a minute
+	minSize     = 0.01             // below this an order counts as fully filled
+	closedKeep  = time.Hour        // how long late fills of closed orders are still booked
+)
+
+// Order is one resting limit order.
+type Order struct {
+	ID          string
+	ConditionID string
+	UpTokenID   string
+	DownTokenID string
+	Side        string // "UP" or "DOWN"
+	OrderSide   types.OrderSide
+	Price       float64
+	Size        float64   // tokens ordered
+	Filled      float64   // tokens filled so far
+	Expiration  time.Time // zero = GTC
+	Placed      time.Time
+}
+
+// Remaining returns the unfilled size.
+func (o *Order) Remaining() float64 {
+	return o.Size - o.Filled
+}
+
+// TokenID returns the token the order trades.
+func (o *Order) TokenID() string {
+	if o.Side == "DOWN" {
+		return o.DownTokenID
+	}
+	return o.UpTokenID
+}
+
+// OnFillFunc is called after a fill is booked into inventory, and with
+// negative tokens and usdc when the fill of a failed trade is reversed.
+type OnFillFunc func(o *Order, tokens, usdc float64)
+
+// bookedFill is one (trade, order) fill seen on the user WebSocket.
+type bookedFill struct {
+	order  *Order
+	tokens float64 // booked into inventory
+	usdc   float64
+	at     time.Time
+	failed bool // the trade failed: reversed, or never booked
+}
+
+// Manager tracks open limit orders.
+type Manager struct {
+	mu     sync.Mutex
+	client *clob.Client
+	inv    inventory.Inventory
+	db     *store.DB // order history (nil = not recorded)
+	clock  clock.Clock
+	dryRun bool
+	onFill OnFillFunc
+	open   map[string]*Order      // orderID → order
+	closed map[string]*Order      // cancelled/expired/filled orders (fills can still trail in)
+	booked map[string]*bookedFill // tradeID/orderID → fill applied
+	seq    int                    // dry-run order IDs
+}
+
+// New creates a Manager. If dryRun=true, orders are only simulated and
+// fill when the quoted price crosses them (see SimulateFills).
+func New(inv inventory.Inventory, client *clob.Client, dryRun bool) *Manager {
+	return &Manager{
+		client: client,
+		inv:    inv,
+		clock:  clock.Default(),
+		dryRun: dryRun || config.DryRun,
+		open:   make(map[string]*Order),
+		closed: make(map[string]*Order),
+		booked: make(map[string]*bookedFill),
+	}
+}
+
+// SetOnFill registers a callback for booked fills (e.g. FSM spend caps).
+func (m *Manager) SetOnFill(fn OnFillFunc) {
+	m.mu.Lock()
+	m.onFill = fn
+	m.mu.Unlock()
+}
+
+// SetStore enables recording posted orders and their outcome in db.
+func (m *Manager) SetStore(db *store.DB) {
+	m.db = db
+}
+
+// Open returns a snapshot of the tracked open orders.
+func (m *Manager) Open() []Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Order, 0, len(m.open))
+	for _, o := range m.open {
+		out = append(out, *o)
+	}
+	return out
+}
+
+// ── Quoting ───────────────────────────────────────────────────────────────
+
+// QuoteArb keeps one resting BUY per side of mkt at the prices and sizes of
+// a quote_arb action, replacing orders whose price moved or whose size no
+// longer fits. A side with size 0 is cancelled.
+func (m *Manager) QuoteArb(ctx context.Context, mkt *types.Market, action types.Action) {
+	var ttl time.Duration
+	if config.ARBMakerTTLSec > 0 {
+		ttl = time.Duration(config.ARBMakerTTLSec) * time.Second
+	}
+	m.Expire()
+	m.quote(ctx, mkt, "UP", action.UpPrice, action.UpSize, ttl)
+	m.quote(ctx, mkt, "DOWN", action.DownPrice, action.DownSize, ttl)
+}
+
+func (m *Manager) quote(ctx context.Context, mkt *types.Market, side string, price, size float64, ttl time.Duration) {
+	existing := m.find(mkt.ConditionID, side, types.SideBuy)
+	if existing != nil {
+		samePrice := math.Abs(existing.Price-price) < repriceTick/2
+		fits := existing.Remaining() <= size+minSize
+		if samePrice && fits && size > 0 {
+			return
+		}
+		if err := m.Cancel(ctx, existing.ID); err != nil {
+			return // keep the old quote rather than risk two
+		}
+	}
+	if size <= 0 {
+		return
+	}
+	if _, err := m.Place(ctx, mkt, side, types.SideBuy, price, size, ttl); err != nil {
+		log.Printf("[orders] quote %s %.2f×%.2f failed: %v", side, price, size, err)
+	}
+}
+
+// find returns the open order for (condition, side, orderSide), if any.
+func (m *Manager) find(conditionID, side string, orderSide types.OrderSide) *Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, o := range m.open {
+		if o.ConditionID == conditionID && o.Side == side && o.OrderSide == orderSide {
+			return o
+		}
+	}
+	return nil
+}
+
+// ── Placement / cancellation ─────────────────────────────────────────────
+
+// Place posts a limit order for size tokens at price. ttl > 0 makes it a
+// GTD order that the CLOB expires after ttl; otherwise it rests until
+// cancelled (GTC).
+func (m *Manager) Place(
+	ctx context.Context,
+	mkt *types.Market, side string, orderSide types.OrderSide,
+	price, size float64, ttl time.Duration,
+) (*Order, error) {
+	now := m.clock.Now()
+	o := &Order{
+		ConditionID: mkt.ConditionID,
+		UpTokenID:   mkt.UpTokenID,
+		DownTokenID: mkt.DownTokenID,
+		Side:        side,
+		OrderSide:   orderSide,
+		Price:       price,
+		Size:        size,
+		Placed:      now,
+	}
+	orderType := "GTC"
+	if ttl > 0 {
+		orderType = "GTD"
+		o.Expiration = now.Add(gtdSafety + ttl)
+	}
+
+	if m.dryRun {
+		m.mu.Lock()
+		m.seq++
+		o.ID = fmt.Sprintf("dry-run-%d", m.seq)
+		m.open[o.ID] = o
+		m.mu.Unlock()
+		log.Printf("[orders] [DRY_RUN] Would post %s %s %s | %.2f tokens @ %.3f | %s",
+			orderType, orderSide, side, size, price, o.ID)
+		m.record(o, orderType, store.OrderDryRun, nil)
+		return o, nil
+	}
+
+	var exp int64
+	if !o.Expiration.IsZero() {
+		exp = o.Expiration.Unix()
+	}
+	resp, err := m.client.PlaceLimitOrder(ctx, clob.LimitOrderRequest{
+		TokenID:    o.TokenID(),
+		OrderSide:  orderSide,
+		Price:      price,
+		Size:       size,
+		OrderType:  orderType,
+		Expiration: exp,
+	})
+	if err != nil {
+		m.record(o, orderType, store.OrderRejected, err)
+		return nil, err
+	}
+	id, _ := resp["orderID"].(string)
+	if id == "" {
+		err := fmt.Errorf("no orderID in response: %v", resp)
+		m.record(o, orderType, store.OrderRejected, err)
+		return nil, err
+	}
+	o.ID = id
+	m.record(o, orderType, store.OrderPlaced, nil)
+
+	m.mu.Lock()
+	m.open[id] = o
+	m.mu.Unlock()
+	log.Printf("[orders] posted %s %s %s | %.2f tokens @ %.3f | order: %s",
+		orderType, orderSide, side, size, price, id)
+	return o, nil
+}
+
+// Cancel cancels one tracked order and stops tracking it.
+func (m *Manager) Cancel(ctx context.Context, orderID string) error {
+	if !m.dryRun {
+		if _, err := m.client.CancelOrder(ctx, orderID); err != nil {
+			log.Printf("[orders] cancel %s failed: %v", orderID, err)
+			return err
+		}
+	}
+	m.mu.Lock()
+	o, ok := m.open[orderID]
+	m.close(orderID)
+	m.mu.Unlock()
+	m.db.OrderStatus(orderID, store.OrderCancelled)
+	if ok {
+		log.Printf("[orders] cancelled %s %s %.2f @ %.3f (filled %.2f) | %s",
+			o.OrderSide, o.Side, o.Size, o.Price, o.Filled, orderID)
+	}
+	return nil
+}
+
+// CancelMarket cancels every tracked order of one condition.
+func (m *Manager) CancelMarket(ctx context.Context, conditionID string) {
+	for _, id := range m.ids(func(o *Order) bool { return o.ConditionID == conditionID }) {
+		_ = m.Cancel(ctx, id)
+	}
+}
+
+// CancelAll cancels every tracked order. Called on shutdown.
+func (m *Manager) CancelAll(ctx context.Context) {
+	ids := m.ids(func(*Order) bool { return true })
+	if len(ids) > 0 {
+		log.Printf("[orders] cancelling %d open orders", len(ids))
+	}
+	for _, id := range ids {
+		_ = m.Cancel(ctx, id)
+	}
+}
+
+// Expire stops tracking GTD orders past their expiration (the CLOB has
+// already expired them) and forgets closed orders and booked fills older
+// than closedKeep.
+func (m *Manager) Expire() {
+	now := m.clock.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, o := range m.open {
+		if !o.Expiration.IsZero() && now.After(o.Expiration) {
+			log.Printf("[orders] expired %s %s %.2f @ %.3f (filled %.2f) | %s",
+				o.OrderSide, o.Side, o.Size, o.Price, o.Filled, id)
+			m.close(id)
+			m.db.OrderStatus(id, store.OrderExpired)
+		}
+	}
+	for id, o := range m.closed {
+		if now.Sub(o.Placed) > closedKeep {
+			delete(m.closed, id)
+		}
+	}
+	for key, b := range m.booked {
+		if now.Sub(b.at) > closedKeep {
+			delete(m.booked, key)
+		}
+	}
+}
+
+// close moves an open order to closed. Caller holds m.mu.
+func (m *Manager) close(orderID string) {
+	if o, ok := m.open[orderID]; ok {
+		m.closed[orderID] = o
+		delete(m.open, orderID)
+	}
+}
+
+func (m *Manager) ids(match func(*Order) bool) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ids []string
+	for id, o := range m.open {
+		if match(o) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// record stores a posted (or rejected) order in the history database.
+func (m *Manager) record(o *Order, orderType, status string, err error) {
+	rec := store.Order{
+		OrderID:     o.ID,
+		ConditionID: o.ConditionID,
+		TokenID:     o.TokenID(),
+		Outcome:     o.Side,
+		Side:        o.OrderSide,
+		Type:        orderType,
+		Price:       o.Price,
+		Size:        o.Size,
+		USDC:        o.Size * o.Price,
+		Status:      status,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	m.db.RecordOrder(rec)
+}
+
+// ── Fills ─────────────────────────────────────────────────────────────────
+
+// HandleFill books a user-WS fill of a tracked (or recently closed) order
+// into inventory. Each (trade, order) pair is applied once, when first
+// seen, and reversed if the trade later fails. Returns false if the fill
+// is not for one of our orders.
+func (m *Manager) HandleFill(fill types.FillEvent) bool {
+	key := fill.TradeID + "/" + fill.OrderID
+	m.mu.Lock()
+	if b, ok := m.booked[key]; ok {
+		reverse := fill.Status == "FAILED" && !b.failed
+		b.failed = b.failed || fill.Status == "FAILED"
+		m.mu.Unlock()
+		if reverse {
+			m.unbook(b)
+		}
+		return true
+	}
+	o, ok := m.open[fill.OrderID]
+	if !ok {
+		o, ok = m.closed[fill.OrderID]
+	}
+	if !ok || fill.Size <= 0 {
+		m.mu.Unlock()
+		return ok
+	}
+	b := &bookedFill{order: o, at: m.clock.Now(), failed: fill.Status == "FAILED"}
+	m.booked[key] = b
+	m.mu.Unlock()
+	if b.failed {
+		return true
+	}
+
+	price := fill.Price
+	if price <= 0 {
+		price = o.Price
+	}
+	tokens, usdc := m.book(o, fill.Size, price)
+	m.mu.Lock()
+	b.tokens, b.usdc = tokens, usdc
+	m.mu.Unlock()
+	return true
+}
+
+// unbook reverses the fill of a trade that failed after it was booked.
+func (m *Manager) unbook(b *bookedFill) {
+	m.mu.Lock()
+	o, tokens, usdc := b.order, b.tokens, b.usdc
+	b.tokens, b.usdc = 0, 0
+	if tokens <= 0 {
+		m.mu.Unlock()
+		return
+	}
+	o.Filled = math.Max(0, o.Filled-tokens)
+	onFill := m.onFill
+	m.mu.Unlock()
+
+	m.inv.RecordCorrection(o.ConditionID, o.UpTokenID, o.DownTokenID, o.Side, o.OrderSide, -tokens, -usdc)
+	log.Printf("[orders] ⚠ trade failed — fill reversed %s %s %.2f ($%.2f) | %.2f/%.2f | order: %s",
+		o.OrderSide, o.Side, tokens, usdc, o.Filled, o.Size, o.ID)
+	if onFill != nil {
+		onFill(o, -tokens, -usdc)
+	}
+}
+
+// SimulateFills fills dry-run orders of one condition that the current
+// prices have crossed (a BUY at or above the ask, a SELL at or below it).
+func (m *Manager) SimulateFills(conditionID string, prices *types.Prices) {
+	if !m.dryRun {
+		return
+	}
+	m.mu.Lock()
+	var crossed []*Order
+	for _, o := range m.open {
+		if o.ConditionID != conditionID {
+			continue
+		}
+		mkt := prices.Up
+		if o.Side == "DOWN" {
+			mkt = prices.Down
+		}
+		if (o.OrderSide == types.SideBuy && mkt <= o.Price) ||
+			(o.OrderSide == types.SideSell && mkt >= o.Price) {
+			crossed = append(crossed, o)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, o := range crossed {
+		m.book(o, o.Remaining(), o.Price)
+	}
+}
+
+// book applies a fill to the order and inventory, and stops tracking the
+// order once it is fully filled. Returns the tokens and USDC booked.
+func (m *Manager) book(o *Order, tokens, price float64) (float64, float64) {
+	m.mu.Lock()
+	tokens = math.Min(tokens, o.Remaining())
+	if tokens <= 0 {
+		m.mu.Unlock()
+		return 0, 0
+	}
+	o.Filled += tokens
+	filled := o.Remaining() < minSize
+	if filled {
+		m.close(o.ID) // late status updates of its trades still arrive
+	}
+	onFill := m.onFill
+	m.mu.Unlock()
+	if filled {
+		m.db.OrderStatus(o.ID, store.OrderFilled)
+	}
+
+	usdc := tokens * price
+	if o.OrderSide == types.SideSell {
+		m.inv.RecordSell(o.ConditionID, o.Side, tokens, usdc)
+	} else {
+		m.inv.RecordBuy(o.ConditionID, o.UpTokenID, o.DownTokenID, o.Side, tokens, usdc)
+	}
+	log.Printf("[orders] fill %s %s %.2f @ %.3f ($%.2f) | %.2f/%.2f | order: %s",
+		o.OrderSide, o.Side, tokens, price, usdc, o.Filled, o.Size, o.ID)
+	if onFill != nil {
+		onFill(o, tokens, usdc)
+	}
+	return tokens, usdc
+}
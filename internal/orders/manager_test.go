@@ -0,0 +1,71 @@
+package orders
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+func TestHandleFillReversesFailedTrades(t *testing.T) {
+	inv := inventory.NewMemory()
+	m := New(inv, nil, true)
+	var spent float64
+	m.SetOnFill(func(_ *Order, _, usdc float64) { spent += usdc })
+
+	mkt := &types.Market{ConditionID: "0xcondition", UpTokenID: "up", DownTokenID: "down"}
+	o, err := m.Place(context.Background(), mkt, "UP", types.SideBuy, 0.40, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		fill      types.FillEvent
+		wantUp    float64
+		wantSpent float64
+	}{
+		{types.FillEvent{TradeID: "t1", OrderID: o.ID, Size: 6, Price: 0.40, Status: "MATCHED"}, 6, 2.4},
+		{types.FillEvent{TradeID: "t1", OrderID: o.ID, Size: 6, Price: 0.40, Status: "MINED"}, 6, 2.4},
+		{types.FillEvent{TradeID: "t2", OrderID: o.ID, Size: 4, Price: 0.40, Status: "MATCHED"}, 10, 4},
+		{types.FillEvent{TradeID: "t1", OrderID: o.ID, Size: 6, Price: 0.40, Status: "FAILED"}, 4, 1.6},
+		{types.FillEvent{TradeID: "t1", OrderID: o.ID, Size: 6, Price: 0.40, Status: "FAILED"}, 4, 1.6},
+		{types.FillEvent{TradeID: "t3", OrderID: o.ID, Size: 2, Price: 0.40, Status: "FAILED"}, 4, 1.6},
+		{types.FillEvent{TradeID: "t3", OrderID: o.ID, Size: 2, Price: 0.40, Status: "MATCHED"}, 4, 1.6},
+	}
+	for i, s := range steps {
+		if !m.HandleFill(s.fill) {
+			t.Fatalf("step %d: fill not recognized", i)
+		}
+		if got := inv.GetBalance(mkt.ConditionID, "UP"); abs(got-s.wantUp) > 1e-9 {
+			t.Errorf("step %d (%s %s): UP balance = %.4f, want %.4f", i, s.fill.TradeID, s.fill.Status, got, s.wantUp)
+		}
+		if abs(spent-s.wantSpent) > 1e-9 {
+			t.Errorf("step %d (%s %s): spent = %.4f, want %.4f", i, s.fill.TradeID, s.fill.Status, spent, s.wantSpent)
+		}
+	}
+	if m.HandleFill(types.FillEvent{TradeID: "t9", OrderID: "someone-else", Size: 1, Status: "MATCHED"}) {
+		t.Error("fill of an unknown order recognized")
+	}
+}
+
+func TestExpirePrunesBookedFills(t *testing.T) {
+	m := New(inventory.NewMemory(), nil, true)
+	m.booked["old"] = &bookedFill{at: time.Now().Add(-2 * closedKeep)}
+	m.booked["new"] = &bookedFill{at: time.Now()}
+	m.Expire()
+	if _, ok := m.booked["old"]; ok {
+		t.Error("old booked fill not pruned")
+	}
+	if _, ok := m.booked["new"]; !ok {
+		t.Error("recent booked fill pruned")
+	}
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
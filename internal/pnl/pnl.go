@@ -0,0 +1,256 @@
+// Package pnl turns inventory entries into realized and unrealized P&L.
+//
+// Realized P&L comes from the inventory's cost basis: merge, sell and
+// redemption proceeds minus the average cost of the tokens given up, minus
+// fees. Open positions are marked to the current quoted prices. When a
+// market closes, the Ledger settles it against the winning side (a winning
+// token is worth $1, a losing one nothing), adds it to the day's totals and
+// logs a per-market and daily summary.
+package pnl
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/fileutil"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+const keepClosed = 30 * 24 * time.Hour // closed markets remembered for dedupe
+
+// Market is the P&L of one market.
+type Market struct {
+	ConditionID string  `json:"condition_id"`
+	Slug        string  `json:"slug,omitempty"`
+	Winner      string  `json:"winner,omitempty"` // set once settled
+	UpTokens    float64 `json:"up_tokens"`
+	DownTokens  float64 `json:"down_tokens"`
+	UpCost      float64 `json:"up_cost_usdc"`   // cost basis of UpTokens
+	DownCost    float64 `json:"down_cost_usdc"` // cost basis of DownTokens
+	Invested    float64 `json:"invested_usdc"`
+	Merged      float64 `json:"merge_proceeds_usdc"`
+	Sold        float64 `json:"sell_proceeds_usdc"`
+	Redeemed    float64 `json:"redemption_usdc"`
+	Fees        float64 `json:"fees_usdc"`
+	Realized    float64 `json:"realized_usdc"`
+	Unrealized  float64 `json:"unrealized_usdc"`
+	Total       float64 `json:"total_usdc"`
+}
+
+// Mark values a market's open tokens at the given prices (nil = at cost).
+func Mark(conditionID string, e inventory.Entry, prices *types.Prices) Market {
+	m := fromEntry(conditionID, e)
+	if prices != nil {
+		m.Unrealized = e.UpBalance*prices.Up + e.DownBalance*prices.Down - e.UpCost - e.DownCost
+	}
+	m.Total = m.Realized + m.Unrealized
+	return m
+}
+
+// Settle values a closed market: winning tokens at $1, losing ones at 0.
+// Everything is realized at that point.
+func Settle(conditionID string, e inventory.Entry, winner string) Market {
+	m := fromEntry(conditionID, e)
+	m.Winner = winner
+	payout := e.DownBalance
+	if winner == "UP" {
+		payout = e.UpBalance
+	}
+	m.Realized += payout - e.UpCost - e.DownCost
+	m.Total = m.Realized
+	return m
+}
+
+func fromEntry(conditionID string, e inventory.Entry) Market {
+	return Market{
+		ConditionID: conditionID,
+		UpTokens:    e.UpBalance,
+		DownTokens:  e.DownBalance,
+		UpCost:      e.UpCost,
+		DownCost:    e.DownCost,
+		Invested:    e.TotalInvested,
+		Merged:      e.TotalMerged,
+		Sold:        e.TotalSold,
+		Redeemed:    e.TotalRedeemed,
+		Fees:        e.Fees,
+		Realized:    e.Realized(),
+	}
+}
+
+// ── Ledger ────────────────────────────────────────────────────────────────
+
+// Day sums the markets that closed on one UTC day.
+type Day struct {
+	Date     string  `json:"date"`
+	Markets  int     `json:"markets"`
+	Winners  int     `json:"winners"` // markets with Total > 0
+	Invested float64 `json:"invested_usdc"`
+	Fees     float64 `json:"fees_usdc"`
+	PnL      float64 `json:"pnl_usdc"`
+}
+
+// closedMarket is a settled market and when it was settled.
+type closedMarket struct {
+	Market
+	ClosedAt time.Time `json:"closed_at"`
+}
+
+// Ledger records settled markets and daily totals, persisted to JSON.
+type Ledger struct {
+	mu       sync.Mutex
+	inv      inventory.Inventory
+	filepath string
+	Days     map[string]*Day          `json:"days"`   // date → totals
+	Closed   map[string]*closedMarket `json:"closed"` // conditionID → settlement
+}
+
+// NewLedger creates a ledger over inv, backed by the configured file.
+func NewLedger(inv inventory.Inventory) *Ledger {
+	l := NewMemoryLedger(inv)
+	l.filepath = config.PnLFile
+	l.load()
+	return l
+}
+
+// NewMemoryLedger creates a ledger that is never persisted to disk.
+func NewMemoryLedger(inv inventory.Inventory) *Ledger {
+	return &Ledger{
+		inv:    inv,
+		Days:   make(map[string]*Day),
+		Closed: make(map[string]*closedMarket),
+	}
+}
+
+// Mark values a tracked market at the given prices.
+func (l *Ledger) Mark(conditionID string, prices *types.Prices) Market {
+	e, _ := l.inv.Get(conditionID)
+	return Mark(conditionID, e, prices)
+}
+
+// Close settles a closed market against winner, adds it to the day and
+// logs the market and daily summaries. Markets that were never traded and
+// repeated calls are ignored.
+func (l *Ledger) Close(m *types.Market, winner string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, done := l.Closed[m.ConditionID]; done {
+		return
+	}
+	e, ok := l.inv.Get(m.ConditionID)
+	if !ok {
+		return
+	}
+	now := clock.Now()
+	res := Settle(m.ConditionID, e, winner)
+	res.Slug = m.Slug
+	l.Closed[m.ConditionID] = &closedMarket{Market: res, ClosedAt: now}
+
+	date := now.UTC().Format("2006-01-02")
+	d, ok := l.Days[date]
+	if !ok {
+		d = &Day{Date: date}
+		l.Days[date] = d
+	}
+	d.Markets++
+	if res.Total > 0 {
+		d.Winners++
+	}
+	d.Invested += res.Invested
+	d.Fees += res.Fees
+	d.PnL += res.Total
+
+	for cid, c := range l.Closed {
+		if now.Sub(c.ClosedAt) > keepClosed {
+			delete(l.Closed, cid)
+		}
+	}
+	l.save()
+
+	log.Printf("[pnl] %s closed (%s won) | %s", m.Slug, winner, res)
+	log.Printf("[pnl] %s", d)
+}
+
+// Today returns the current UTC day's totals.
+func (l *Ledger) Today() Day {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	date := clock.Now().UTC().Format("2006-01-02")
+	if d, ok := l.Days[date]; ok {
+		return *d
+	}
+	return Day{Date: date}
+}
+
+// History returns every recorded day, newest first.
+func (l *Ledger) History() []Day {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]Day, 0, len(l.Days))
+	for _, d := range l.Days {
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Date > out[j].Date })
+	return out
+}
+
+// String summarises a market's P&L on one line.
+func (m Market) String() string {
+	return fmt.Sprintf("P&L $%+.2f (realized $%+.2f, unrealized $%+.2f) | invested $%.2f | merged $%.2f sold $%.2f redeemed $%.2f | fees $%.2f",
+		m.Total, m.Realized, m.Unrealized, m.Invested, m.Merged, m.Sold, m.Redeemed, m.Fees)
+}
+
+// String summarises a day on one line.
+func (d *Day) String() string {
+	return fmt.Sprintf("day %s: %d markets closed (%d winners) | P&L $%+.2f | invested $%.2f | fees $%.2f",
+		d.Date, d.Markets, d.Winners, d.PnL, d.Invested, d.Fees)
+}
+
+// ── Persistence ───────────────────────────────────────────────────────────
+
+func (l *Ledger) load() {
+	if _, err := os.Stat(l.filepath); os.IsNotExist(err) {
+		return
+	}
+	data, err := os.ReadFile(l.filepath)
+	if err != nil {
+		log.Printf("[pnl] load error: %v — starting fresh", err)
+		return
+	}
+	if err := json.Unmarshal(data, l); err != nil {
+		bad := fmt.Sprintf("%s.corrupt-%d", l.filepath, time.Now().Unix())
+		os.Rename(l.filepath, bad)
+		log.Printf("[pnl] ⚠ parse error: %v — moved to %s, starting fresh", err, bad)
+		l.Days = make(map[string]*Day)
+		l.Closed = make(map[string]*closedMarket)
+		return
+	}
+	if l.Days == nil {
+		l.Days = make(map[string]*Day)
+	}
+	if l.Closed == nil {
+		l.Closed = make(map[string]*closedMarket)
+	}
+	log.Printf("[pnl] loaded: %d days, %d closed markets", len(l.Days), len(l.Closed))
+}
+
+// save persists the ledger. Caller holds l.mu.
+func (l *Ledger) save() {
+	if l.filepath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err == nil {
+		err = fileutil.WriteAtomic(l.filepath, data, 0600)
+	}
+	if err != nil {
+		log.Printf("[pnl] save error: %v", err)
+	}
+}
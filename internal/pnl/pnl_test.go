@@ -0,0 +1,81 @@
+package pnl
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+const testCID = "0xcondition0000000000000000000000000000000000000000000000000000000"
+
+// traded returns an inventory that bought 10 UP for $4 and 8 DOWN for
+// $4.40, then merged 8 pairs: $0.40 realized, 2 UP left at $0.80.
+func traded() inventory.Inventory {
+	inv := inventory.NewMemory()
+	inv.RecordBuy(testCID, "up", "down", "UP", 10, 4)
+	inv.RecordBuy(testCID, "up", "down", "DOWN", 8, 4.4)
+	inv.RecordMerge(testCID, 8)
+	return inv
+}
+
+func TestSettle(t *testing.T) {
+	tests := []struct {
+		winner       string
+		wantRealized float64
+	}{
+		{winner: "UP", wantRealized: 0.4 + 2 - 0.8},
+		{winner: "DOWN", wantRealized: 0.4 - 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.winner, func(t *testing.T) {
+			e, _ := traded().Get(testCID)
+			m := Settle(testCID, e, tt.winner)
+			if math.Abs(m.Realized-tt.wantRealized) > 1e-9 || m.Total != m.Realized || m.Unrealized != 0 {
+				t.Errorf("settled %s, want realized = total = $%+.2f", m, tt.wantRealized)
+			}
+		})
+	}
+}
+
+func TestMark(t *testing.T) {
+	e, _ := traded().Get(testCID)
+	m := Mark(testCID, e, &types.Prices{Up: 0.7, Down: 0.3})
+	if want := 2*0.7 - 0.8; math.Abs(m.Unrealized-want) > 1e-9 || math.Abs(m.Total-(0.4+want)) > 1e-9 {
+		t.Errorf("marked %s, want unrealized $%+.2f", m, want)
+	}
+}
+
+func TestLedgerClosesOnce(t *testing.T) {
+	config.PnLFile = filepath.Join(t.TempDir(), "pnl.json")
+	inv := traded()
+	m := &types.Market{ConditionID: testCID, Slug: "btc"}
+
+	l := NewLedger(inv)
+	l.Close(m, "UP")
+	l.Close(m, "UP")
+	l.Close(m, "DOWN") // a later, different winner is ignored too
+
+	// Nor does a restart settle it again
+	NewLedger(inv).Close(m, "UP")
+	l = NewLedger(inv)
+
+	d := l.Today()
+	if d.Markets != 1 || d.Winners != 1 || math.Abs(d.PnL-1.6) > 1e-9 || d.Invested != 8.4 {
+		t.Errorf("day = %s, want 1 winning market, P&L $+1.60, invested $8.40", &d)
+	}
+	if c := l.Closed[testCID]; c == nil || c.Winner != "UP" {
+		t.Errorf("closed = %+v, want settled UP", c)
+	}
+}
+
+func TestLedgerIgnoresUntraded(t *testing.T) {
+	l := NewMemoryLedger(inventory.NewMemory())
+	l.Close(&types.Market{ConditionID: testCID}, "UP")
+	if d := l.Today(); d.Markets != 0 || len(l.Closed) != 0 {
+		t.Errorf("untraded market recorded: day %s, %d closed", &d, len(l.Closed))
+	}
+}
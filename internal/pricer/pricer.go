@@ -4,6 +4,7 @@
 package pricer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,38 +12,56 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/gipsh/polymarket-bot-go/internal/book"
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
 	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/recorder"
 	"github.com/gipsh/polymarket-bot-go/internal/types"
 )
 
 const (
 	priceEndpoint    = "/price"
 	midpointEndpoint = "/midpoint"
+	bookEndpoint     = "/book"
 )
 
 // Pricer fetches prices from the Polymarket REST API.
 type Pricer struct {
-	host    string
-	httpCli *http.Client
+	host     string
+	httpCli  *http.Client
+	recorder *recorder.Recorder
 }
 
-// NewPricer creates a REST-based pricer.
+// NewPricer creates a REST-based pricer against config.CLOBHost.
 func NewPricer() *Pricer {
+	return NewPricerWithHost(config.CLOBHost)
+}
+
+// NewPricerWithHost creates a REST pricer targeting host (e.g. a local
+// fakeclob server).
+func NewPricerWithHost(host string) *Pricer {
 	return &Pricer{
-		host: config.CLOBHost,
+		host: strings.TrimRight(host, "/"),
 		httpCli: &http.Client{
 			Timeout: 6 * time.Second,
 		},
 	}
 }
 
+// SetRecorder enables persisting every fetched REST price to rec.
+func (p *Pricer) SetRecorder(rec *recorder.Recorder) {
+	p.recorder = rec
+}
+
 // GetPrices fetches UP and DOWN prices concurrently and returns classified Prices.
-func (p *Pricer) GetPrices(upTokenID, downTokenID string) (*types.Prices, error) {
+func (p *Pricer) GetPrices(ctx context.Context, upTokenID, downTokenID string) (*types.Prices, error) {
 	var (
 		upPrice, downPrice float64
+		upBid, downBid     float64 // 0 unless SPLIT is enabled
 		upErr, downErr     error
 		wg                 sync.WaitGroup
 	)
@@ -50,14 +69,34 @@ func (p *Pricer) GetPrices(upTokenID, downTokenID string) (*types.Prices, error)
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		upPrice, upErr = p.fetchPrice(upTokenID)
+		upPrice, upErr = p.fetchPrice(ctx, upTokenID)
 	}()
 	go func() {
 		defer wg.Done()
-		downPrice, downErr = p.fetchPrice(downTokenID)
+		downPrice, downErr = p.fetchPrice(ctx, downTokenID)
 	}()
+	if config.SplitThreshold > 0 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			upBid, _ = p.fetchSidePrice(ctx, upTokenID, "SELL")
+		}()
+		go func() {
+			defer wg.Done()
+			downBid, _ = p.fetchSidePrice(ctx, downTokenID, "SELL")
+		}()
+	}
 	wg.Wait()
 
+	if p.recorder != nil {
+		if upErr == nil {
+			p.recorder.RecordREST(upTokenID, upPrice)
+		}
+		if downErr == nil {
+			p.recorder.RecordREST(downTokenID, downPrice)
+		}
+	}
+
 	if upErr != nil {
 		log.Printf("[pricer] UP price error (%s...): %v", upTokenID[:12], upErr)
 		upPrice = 0.5
@@ -67,34 +106,69 @@ func (p *Pricer) GetPrices(upTokenID, downTokenID string) (*types.Prices, error)
 		downPrice = 0.5
 	}
 
-	state := types.ClassifyPrices(upPrice, downPrice, config.ARBThreshold, config.MomentumTrigger)
-	return &types.Prices{
-		Up:     upPrice,
-		Down:   downPrice,
-		Spread: upPrice + downPrice,
-		State:  state,
-	}, nil
+	state := types.ClassifyPrices(upPrice, downPrice, upBid, downBid, config.ARBThreshold, config.SplitThreshold, config.MomentumTrigger)
+	prices := &types.Prices{
+		Up:      upPrice,
+		Down:    downPrice,
+		Spread:  upPrice + downPrice,
+		State:   state,
+		UpBid:   upBid,
+		DownBid: downBid,
+	}
+
+	// Depth only matters for ARB and SPLIT sizing — fetch books only then.
+	if state == types.StateARB || state == types.StateSplit {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			prices.UpBook, _ = p.GetBook(ctx, upTokenID)
+		}()
+		go func() {
+			defer wg.Done()
+			prices.DownBook, _ = p.GetBook(ctx, downTokenID)
+		}()
+		wg.Wait()
+	}
+	return prices, nil
+}
+
+// GetBook fetches the full order book for a single token.
+// GET /book?token_id=...
+func (p *Pricer) GetBook(ctx context.Context, tokenID string) (*book.Book, error) {
+	resp, err := p.get(ctx, p.host+bookEndpoint+"?token_id="+tokenID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return book.ParseJSON(body, clock.Now())
 }
 
 // fetchPrice fetches the best ask price for a single token.
 // Primary: GET /price?token_id=...&side=BUY
 // Fallback: GET /midpoint?token_id=...
-func (p *Pricer) fetchPrice(tokenID string) (float64, error) {
+func (p *Pricer) fetchPrice(ctx context.Context, tokenID string) (float64, error) {
 	// Primary: best ask
-	if price, err := p.fetchBestAsk(tokenID); err == nil {
+	if price, err := p.fetchSidePrice(ctx, tokenID, "BUY"); err == nil {
 		return price, nil
 	}
 
 	// Fallback: midpoint
-	return p.fetchMidpoint(tokenID)
+	return p.fetchMidpoint(ctx, tokenID)
 }
 
-func (p *Pricer) fetchBestAsk(tokenID string) (float64, error) {
+// fetchSidePrice fetches the price a taker gets on side: BUY = best ask,
+// SELL = best bid.
+func (p *Pricer) fetchSidePrice(ctx context.Context, tokenID, side string) (float64, error) {
 	params := url.Values{}
 	params.Set("token_id", tokenID)
-	params.Set("side", "BUY")
+	params.Set("side", side)
 
-	resp, err := p.httpCli.Get(p.host + priceEndpoint + "?" + params.Encode())
+	resp, err := p.get(ctx, p.host+priceEndpoint+"?"+params.Encode())
 	if err != nil {
 		return 0, err
 	}
@@ -118,8 +192,8 @@ func (p *Pricer) fetchBestAsk(tokenID string) (float64, error) {
 	return 0, fmt.Errorf("unparseable price: %s", body)
 }
 
-func (p *Pricer) fetchMidpoint(tokenID string) (float64, error) {
-	resp, err := p.httpCli.Get(p.host + midpointEndpoint + "?token_id=" + tokenID)
+func (p *Pricer) fetchMidpoint(ctx context.Context, tokenID string) (float64, error) {
+	resp, err := p.get(ctx, p.host+midpointEndpoint+"?token_id="+tokenID)
 	if err != nil {
 		return 0, err
 	}
@@ -138,3 +212,12 @@ func (p *Pricer) fetchMidpoint(tokenID string) (float64, error) {
 	}
 	return strconv.ParseFloat(result.Mid, 64)
 }
+
+// get issues a GET bound to ctx.
+func (p *Pricer) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return p.httpCli.Do(req)
+}
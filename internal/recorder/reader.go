@@ -0,0 +1,84 @@
+package recorder
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ReadDir loads every recording file under dir (recursively) and returns
+// all records sorted by timestamp. Records with equal timestamps keep their
+// on-disk order, so replays are deterministic.
+func ReadDir(dir string) ([]Record, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && (strings.HasSuffix(path, ".ndjson.gz") || strings.HasSuffix(path, ".ndjson")) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no recording files under %s", dir)
+	}
+	sort.Strings(paths)
+
+	var out []Record
+	for _, p := range paths {
+		recs, err := ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		out = append(out, recs...)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].TS.Before(out[j].TS) })
+	return out, nil
+}
+
+// ReadFile reads one (optionally gzip-compressed) recording file.
+// A truncated gzip tail (e.g. after a crash) ends the file without error.
+func ReadFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var out []Record
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			break // partial last line
+		}
+		out = append(out, rec)
+	}
+	// sc.Err() here is a truncated gzip stream; keep what was read.
+	return out, nil
+}
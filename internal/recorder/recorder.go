@@ -0,0 +1,335 @@
+// Package recorder persists raw market-data events to disk as rotating,
+// gzip-compressed newline-delimited JSON files, one stream per market.
+//
+// Layout:
+//
+//	<dir>/<slug>_<conditionID>/<YYYYMMDD-HH>-<seq>.ndjson.gz
+//
+// Every file starts with a "market" record describing the market, so each
+// file can be read on its own (by backtests, replays or post-mortems).
+package recorder
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+const (
+	flushInterval = time.Second
+	unknownMarket = "unknown"
+)
+
+// Record sources.
+const (
+	SourceWS   = "ws"
+	SourceREST = "rest"
+	SourceMeta = "meta"
+)
+
+// Record is one line in a recording file.
+type Record struct {
+	TS          time.Time       `json:"ts"`
+	Source      string          `json:"source"` // ws | rest | meta
+	Event       string          `json:"event"`  // book, price_change, best_bid_ask, last_trade_price, price, market
+	Slug        string          `json:"slug,omitempty"`
+	ConditionID string          `json:"condition_id,omitempty"`
+	AssetID     string          `json:"asset_id,omitempty"`
+	Side        string          `json:"side,omitempty"`  // "UP" / "DOWN" (if asset known)
+	Price       float64         `json:"price,omitempty"` // REST price
+	Market      *MarketMeta     `json:"market,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"` // raw WS event
+}
+
+// MarketMeta is the serialisable form of types.Market.
+type MarketMeta struct {
+	Asset       string    `json:"asset"`
+	Slug        string    `json:"slug"`
+	ConditionID string    `json:"condition_id"`
+	UpTokenID   string    `json:"up_token_id"`
+	DownTokenID string    `json:"down_token_id"`
+	EndDate     time.Time `json:"end_date"`
+	Title       string    `json:"title"`
+}
+
+// ToMarket converts the metadata back to a types.Market.
+func (mm *MarketMeta) ToMarket() *types.Market {
+	return &types.Market{
+		Asset:       mm.Asset,
+		Slug:        mm.Slug,
+		ConditionID: mm.ConditionID,
+		UpTokenID:   mm.UpTokenID,
+		DownTokenID: mm.DownTokenID,
+		EndDate:     mm.EndDate,
+		Title:       mm.Title,
+	}
+}
+
+type tokenRef struct {
+	conditionID string
+	side        string
+}
+
+// Recorder writes market-data records to per-market rotating files.
+// Safe for concurrent use.
+type Recorder struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	markets  map[string]*MarketMeta // conditionID → market
+	tokens   map[string]tokenRef    // tokenID → market + side
+	streams  map[string]*stream     // conditionID (or "unknown") → open file
+	closed   bool
+}
+
+// New creates a recorder writing under dir. Files rotate hourly or once
+// maxMB of uncompressed data has been written, whichever comes first.
+func New(dir string, maxMB int) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", dir, err)
+	}
+	if maxMB <= 0 {
+		maxMB = 64
+	}
+	log.Printf("[recorder] recording market data to %s (rotate hourly / %dMB)", dir, maxMB)
+	return &Recorder{
+		dir:      dir,
+		maxBytes: int64(maxMB) << 20,
+		markets:  make(map[string]*MarketMeta),
+		tokens:   make(map[string]tokenRef),
+		streams:  make(map[string]*stream),
+	}, nil
+}
+
+// Register associates a market's token IDs with its slug / condition ID so
+// that subsequent events are written to the right stream.
+func (r *Recorder) Register(m *types.Market) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.markets[m.ConditionID]; ok {
+		return
+	}
+	meta := &MarketMeta{
+		Asset:       m.Asset,
+		Slug:        m.Slug,
+		ConditionID: m.ConditionID,
+		UpTokenID:   m.UpTokenID,
+		DownTokenID: m.DownTokenID,
+		EndDate:     m.EndDate,
+		Title:       m.Title,
+	}
+	r.markets[m.ConditionID] = meta
+	r.tokens[m.UpTokenID] = tokenRef{m.ConditionID, "UP"}
+	r.tokens[m.DownTokenID] = tokenRef{m.ConditionID, "DOWN"}
+}
+
+// RecordWS records one raw WebSocket market event (book, price_change,
+// best_bid_ask, last_trade_price).
+func (r *Recorder) RecordWS(eventType string, raw json.RawMessage) {
+	var ids struct {
+		AssetID      string `json:"asset_id"`
+		Market       string `json:"market"`
+		PriceChanges []struct {
+			AssetID string `json:"asset_id"`
+		} `json:"price_changes"`
+	}
+	_ = json.Unmarshal(raw, &ids)
+	assetID := ids.AssetID
+	if assetID == "" && len(ids.PriceChanges) > 0 {
+		assetID = ids.PriceChanges[0].AssetID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec := Record{
+		TS:      time.Now().UTC(),
+		Source:  SourceWS,
+		Event:   eventType,
+		AssetID: assetID,
+		Data:    raw,
+	}
+	r.resolve(&rec, assetID, ids.Market)
+	r.write(&rec)
+}
+
+// RecordREST records a REST price (pricer fallback) for one token.
+func (r *Recorder) RecordREST(tokenID string, price float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec := Record{
+		TS:      time.Now().UTC(),
+		Source:  SourceREST,
+		Event:   "price",
+		AssetID: tokenID,
+		Price:   price,
+	}
+	r.resolve(&rec, tokenID, "")
+	r.write(&rec)
+}
+
+// Close flushes and closes every open file. Further records are dropped.
+func (r *Recorder) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	for key, s := range r.streams {
+		s.close()
+		delete(r.streams, key)
+	}
+	log.Println("[recorder] closed")
+}
+
+// ── Internal ──────────────────────────────────────────────────────────────
+
+// resolve fills in slug / condition ID / side from the token or market ID.
+func (r *Recorder) resolve(rec *Record, assetID, conditionID string) {
+	if ref, ok := r.tokens[assetID]; ok {
+		rec.ConditionID = ref.conditionID
+		rec.Side = ref.side
+	} else if _, ok := r.markets[conditionID]; ok {
+		rec.ConditionID = conditionID
+	}
+	if mm, ok := r.markets[rec.ConditionID]; ok {
+		rec.Slug = mm.Slug
+	}
+}
+
+func (r *Recorder) write(rec *Record) {
+	if r.closed {
+		return
+	}
+	key := rec.ConditionID
+	if key == "" {
+		key = unknownMarket
+	}
+
+	s := r.streams[key]
+	if s != nil && s.needsRotate(rec.TS, r.maxBytes) {
+		s.close()
+		s = nil
+	}
+	if s == nil {
+		var err error
+		if s, err = r.open(key, rec.TS); err != nil {
+			log.Printf("[recorder] open stream for %s: %v", key, err)
+			return
+		}
+		r.streams[key] = s
+	}
+
+	if err := s.writeRecord(rec); err != nil {
+		log.Printf("[recorder] write %s: %v", s.path, err)
+	}
+}
+
+// open creates the next file for a market stream and writes its header.
+func (r *Recorder) open(key string, ts time.Time) (*stream, error) {
+	dirName := unknownMarket
+	meta := r.markets[key]
+	if meta != nil {
+		dirName = fmt.Sprintf("%s_%s", sanitize(meta.Slug), sanitize(meta.ConditionID))
+	}
+	dir := filepath.Join(r.dir, dirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	hour := ts.Format("20060102-15")
+	var path string
+	for seq := 0; ; seq++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s-%03d.ndjson.gz", hour, seq))
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+	}
+
+	s, err := newStream(path, hour)
+	if err != nil {
+		return nil, err
+	}
+	if meta != nil {
+		_ = s.writeRecord(&Record{
+			TS:          ts,
+			Source:      SourceMeta,
+			Event:       "market",
+			Slug:        meta.Slug,
+			ConditionID: meta.ConditionID,
+			Market:      meta,
+		})
+	}
+	return s, nil
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// ── Stream (one open gzip file) ───────────────────────────────────────────
+
+type stream struct {
+	path      string
+	hour      string
+	file      *os.File
+	gz        *gzip.Writer
+	written   int64
+	lastFlush time.Time
+}
+
+func newStream(path, hour string) (*stream, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &stream{
+		path:      path,
+		hour:      hour,
+		file:      f,
+		gz:        gzip.NewWriter(f),
+		lastFlush: time.Now(),
+	}, nil
+}
+
+func (s *stream) needsRotate(ts time.Time, maxBytes int64) bool {
+	return s.written >= maxBytes || ts.Format("20060102-15") != s.hour
+}
+
+func (s *stream) writeRecord(rec *Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	n, err := s.gz.Write(line)
+	s.written += int64(n)
+	if err != nil {
+		return err
+	}
+	if time.Since(s.lastFlush) >= flushInterval {
+		s.lastFlush = time.Now()
+		return s.gz.Flush()
+	}
+	return nil
+}
+
+func (s *stream) close() {
+	if err := s.gz.Close(); err != nil {
+		log.Printf("[recorder] close %s: %v", s.path, err)
+	}
+	_ = s.file.Close()
+}
@@ -0,0 +1,125 @@
+package recorder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+var testMarket = &types.Market{
+	Asset: "BTC", Slug: "btc-updown", ConditionID: "0xcond",
+	UpTokenID: "1001", DownTokenID: "1002", EndDate: time.Date(2026, 2, 22, 22, 0, 0, 0, time.UTC),
+}
+
+func TestRecordAndRead(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Register(testMarket)
+	r.RecordWS("book", json.RawMessage(`{"event_type":"book","asset_id":"1001","asks":[{"price":"0.45","size":"10"}]}`))
+	r.RecordWS("price_change", json.RawMessage(`{"event_type":"price_change","price_changes":[{"asset_id":"1002","price":"0.5","size":"3","side":"SELL"}]}`))
+	r.RecordREST("1002", 0.52)
+	r.RecordWS("book", json.RawMessage(`{"event_type":"book","asset_id":"9999"}`)) // not registered
+	r.Close()
+	r.RecordREST("1001", 0.5) // dropped after Close
+
+	if _, err := os.Stat(filepath.Join(dir, "btc-updown_0xcond")); err != nil {
+		t.Errorf("market stream dir: %v", err)
+	}
+	recs, err := ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type got struct{ source, event, side, slug string }
+	var market, unknown []got
+	for _, rec := range recs {
+		g := got{rec.Source, rec.Event, rec.Side, rec.Slug}
+		if rec.ConditionID == "0xcond" {
+			market = append(market, g)
+		} else {
+			unknown = append(unknown, g)
+		}
+	}
+	want := []got{
+		{SourceMeta, "market", "", "btc-updown"},
+		{SourceWS, "book", "UP", "btc-updown"},
+		{SourceWS, "price_change", "DOWN", "btc-updown"},
+		{SourceREST, "price", "DOWN", "btc-updown"},
+	}
+	if len(market) != len(want) {
+		t.Fatalf("market records = %v, want %v", market, want)
+	}
+	for i := range want {
+		if market[i] != want[i] {
+			t.Errorf("record %d = %v, want %v", i, market[i], want[i])
+		}
+	}
+	if len(unknown) != 1 || unknown[0].event != "book" {
+		t.Errorf("unregistered records = %v, want the one book in %s/", unknown, unknownMarket)
+	}
+	if recs[0].Market == nil || recs[0].Market.ToMarket().EndDate != testMarket.EndDate {
+		t.Errorf("header = %+v, want the market metadata", recs[0].Market)
+	}
+}
+
+func TestRotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.maxBytes = 1 // every record starts a new file
+	r.Register(testMarket)
+	for _, p := range []float64{0.50, 0.51, 0.52} {
+		r.RecordREST("1001", p)
+	}
+	r.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "btc-updown_0xcond", "*.ndjson.gz"))
+	if len(files) != 3 {
+		t.Fatalf("%d files, want 3", len(files))
+	}
+	// Each file can be read on its own: it starts with the market record
+	for _, f := range files {
+		recs, err := ReadFile(f)
+		if err != nil || len(recs) != 2 || recs[0].Event != "market" {
+			t.Errorf("%s: %d records (%v), want market header + 1 price", filepath.Base(f), len(recs), err)
+		}
+	}
+}
+
+func TestReadTruncatedFile(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Register(testMarket)
+	for i := 0; i < 200; i++ {
+		r.RecordREST("1001", 0.5)
+	}
+	r.Close()
+	files, _ := filepath.Glob(filepath.Join(dir, "btc-updown_0xcond", "*.ndjson.gz"))
+	if len(files) != 1 {
+		t.Fatalf("%d files, want 1", len(files))
+	}
+
+	// A crash leaves the gzip stream without its trailer
+	info, _ := os.Stat(files[0])
+	if err := os.Truncate(files[0], info.Size()-8); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("truncated file: %v", err)
+	}
+	if len(recs) != 201 {
+		t.Errorf("%d records read without the gzip trailer, want 201", len(recs))
+	}
+}
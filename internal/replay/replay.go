@@ -0,0 +1,151 @@
+// Package replay plays recorded market-data files (see internal/recorder)
+// back into the bot against a simulated clock, replacing the live WS feed,
+// the REST pricer and Gamma market discovery.
+//
+// The caller owns the clock: each main-loop iteration calls AdvanceTo with
+// the current simulated time, which injects every recorded event up to that
+// instant into the ws.Pricer. The same recording + config always produces
+// the same sequence of FSM decisions.
+package replay
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/recorder"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+	"github.com/gipsh/polymarket-bot-go/internal/ws"
+)
+
+// Player feeds recorded events to a ws.Pricer and serves recorded REST
+// prices and market metadata.
+type Player struct {
+	records []recorder.Record
+	pos     int
+	wsp     *ws.Pricer
+	markets map[string]*types.Market // conditionID → market (seen so far)
+	rest    map[string]float64       // tokenID → latest recorded REST price
+	now     time.Time
+}
+
+// restLookahead bounds how far ahead GetPrices searches for the REST price
+// recorded by the call being replayed (the live call was recorded when its
+// response arrived, i.e. slightly after the loop iteration started).
+const restLookahead = 6 * time.Second
+
+// Load reads every recording under dir.
+func Load(dir string, wsp *ws.Pricer) (*Player, error) {
+	recs, err := recorder.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, fmt.Errorf("no records in %s", dir)
+	}
+	log.Printf("[replay] loaded %d records from %s (%s → %s)",
+		len(recs), dir, recs[0].TS.Format(time.RFC3339), recs[len(recs)-1].TS.Format(time.RFC3339))
+	return &Player{
+		records: recs,
+		wsp:     wsp,
+		markets: make(map[string]*types.Market),
+		rest:    make(map[string]float64),
+	}, nil
+}
+
+// Start returns the timestamp of the first recorded event.
+func (p *Player) Start() time.Time {
+	return p.records[0].TS
+}
+
+// Done returns true once every record has been played.
+func (p *Player) Done() bool {
+	return p.pos >= len(p.records)
+}
+
+// AdvanceTo plays every record with a timestamp at or before t.
+// Returns the number of records played.
+func (p *Player) AdvanceTo(t time.Time) int {
+	p.now = t
+	n := 0
+	for p.pos < len(p.records) && !p.records[p.pos].TS.After(t) {
+		p.play(&p.records[p.pos])
+		p.pos++
+		n++
+	}
+	return n
+}
+
+func (p *Player) play(rec *recorder.Record) {
+	switch rec.Source {
+	case recorder.SourceMeta:
+		if rec.Market != nil {
+			if _, ok := p.markets[rec.Market.ConditionID]; !ok {
+				p.markets[rec.Market.ConditionID] = rec.Market.ToMarket()
+			}
+		}
+	case recorder.SourceWS:
+		p.wsp.Inject(rec.Data)
+	case recorder.SourceREST:
+		p.rest[rec.AssetID] = rec.Price
+	}
+}
+
+// ── market.Finder replacement ─────────────────────────────────────────────
+
+// GetActiveMarkets returns recorded markets that are open and closing within
+// MaxMarketAgeH at the current simulated time, soonest first.
+func (p *Player) GetActiveMarkets() ([]*types.Market, error) {
+	var out []*types.Market
+	for _, m := range p.markets {
+		if m.IsClosingSoon(config.MaxMarketAgeH) {
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].EndDate.Equal(out[j].EndDate) {
+			return out[i].EndDate.Before(out[j].EndDate)
+		}
+		return out[i].Slug < out[j].Slug
+	})
+	return out, nil
+}
+
+// ── pricer.Pricer replacement ─────────────────────────────────────────────
+
+// GetPrices returns the recorded REST prices for the pair: the response to
+// this very call if it was recorded within restLookahead, otherwise the most
+// recent earlier one. Like the live pricer, a missing side defaults to 0.5.
+func (p *Player) GetPrices(_ context.Context, upTokenID, downTokenID string) (*types.Prices, error) {
+	up, okUp := p.restPrice(upTokenID)
+	down, okDown := p.restPrice(downTokenID)
+	if !okUp && !okDown {
+		return nil, fmt.Errorf("no recorded REST price")
+	}
+	if !okUp {
+		up = 0.5
+	}
+	if !okDown {
+		down = 0.5
+	}
+	return &types.Prices{
+		Up:     up,
+		Down:   down,
+		Spread: up + down,
+		State:  types.ClassifyPrices(up, down, 0, 0, config.ARBThreshold, config.SplitThreshold, config.MomentumTrigger),
+	}, nil
+}
+
+func (p *Player) restPrice(tokenID string) (float64, bool) {
+	limit := p.now.Add(restLookahead)
+	for i := p.pos; i < len(p.records) && !p.records[i].TS.After(limit); i++ {
+		if r := &p.records[i]; r.Source == recorder.SourceREST && r.AssetID == tokenID {
+			return r.Price, true
+		}
+	}
+	price, ok := p.rest[tokenID]
+	return price, ok
+}
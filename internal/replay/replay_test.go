@@ -0,0 +1,95 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/fsm"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+	"github.com/gipsh/polymarket-bot-go/internal/ws"
+)
+
+// replayConfig sets the thresholds and caps the fixture is written for.
+func replayConfig(t *testing.T) {
+	t.Helper()
+	threshold, split, trigger, age := config.ARBThreshold, config.SplitThreshold, config.MomentumTrigger, config.MaxMarketAgeH
+	max, order, maker := config.ARBMaxUSDC, config.ARBOrderUSDC, config.ARBMakerEnabled
+	entry, momMax, main, hedge := config.MomentumMaxEntry, config.MomentumMaxUSDC, config.MomentumMainUSDC, config.MomentumHedgeUSDC
+	t.Cleanup(func() {
+		config.ARBThreshold, config.SplitThreshold, config.MomentumTrigger, config.MaxMarketAgeH = threshold, split, trigger, age
+		config.ARBMaxUSDC, config.ARBOrderUSDC, config.ARBMakerEnabled = max, order, maker
+		config.MomentumMaxEntry, config.MomentumMaxUSDC, config.MomentumMainUSDC, config.MomentumHedgeUSDC = entry, momMax, main, hedge
+		clock.Set(clock.Real{})
+	})
+	config.ARBThreshold, config.SplitThreshold, config.MomentumTrigger, config.MaxMarketAgeH = 0.98, 0, 0.85, 1
+	config.ARBMaxUSDC, config.ARBOrderUSDC, config.ARBMakerEnabled = 18, 9, false
+	config.MomentumMaxEntry, config.MomentumMaxUSDC, config.MomentumMainUSDC, config.MomentumHedgeUSDC = 0.92, 10, 5, 1
+}
+
+// run replays testdata the way the main loop does (WS prices while fresh,
+// else the recorded REST prices), filling every buy at the quoted prices,
+// and returns the FSM's decisions.
+func run(t *testing.T) []string {
+	t.Helper()
+	wsp := ws.NewWSPricer()
+	p, err := Load("testdata", wsp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := clock.NewSim(p.Start())
+	clock.Set(clk)
+	f := fsm.NewWithClock(clk)
+	inv := inventory.NewMemory()
+
+	var decisions []string
+	for !p.Done() {
+		p.AdvanceTo(clk.Now())
+		markets, _ := p.GetActiveMarkets()
+		for _, m := range markets {
+			var prices *types.Prices
+			if wsp.IsFresh(m.UpTokenID, 4*time.Second) && wsp.IsFresh(m.DownTokenID, 4*time.Second) {
+				prices = wsp.GetPrices(m.UpTokenID, m.DownTokenID)
+			} else if prices, err = p.GetPrices(context.Background(), m.UpTokenID, m.DownTokenID); err != nil {
+				continue
+			}
+			_, a := f.Step(m.ConditionID, prices, inv, m.MinutesToClose())
+			switch a.Kind {
+			case types.ActionWait, types.ActionSkip:
+				continue
+			case types.ActionBuyArbPair:
+				inv.RecordBuy(m.ConditionID, m.UpTokenID, m.DownTokenID, "UP", a.Pairs, a.UpUSDC)
+				inv.RecordBuy(m.ConditionID, m.UpTokenID, m.DownTokenID, "DOWN", a.Pairs, a.DownUSDC)
+			case types.ActionBuyMomentum:
+				inv.RecordBuy(m.ConditionID, m.UpTokenID, m.DownTokenID, a.MainSide, a.MainUSDC/prices.Up, a.MainUSDC)
+				inv.RecordBuy(m.ConditionID, m.UpTokenID, m.DownTokenID, a.HedgeSide, a.HedgeUSDC/prices.Down, a.HedgeUSDC)
+			case types.ActionMerge:
+				inv.RecordMerge(m.ConditionID, inv.GetMergeablePairs(m.ConditionID))
+			}
+			decisions = append(decisions, fmt.Sprintf("%s %s %s", clk.Now().Format("15:04:05"), prices.State, a.Kind))
+		}
+		clk.Advance(time.Second)
+	}
+	return decisions
+}
+
+func TestReplayDeterministic(t *testing.T) {
+	replayConfig(t)
+	want := []string{
+		"21:50:01 ARB buy_arb_pair",         // recorded books
+		"21:50:06 ARB buy_arb_pair",         // after the 5s ARB cooldown, up to ARB_MAX_USDC
+		"21:50:16 MOMENTUM_UP buy_momentum", // WS stale for 4s: recorded REST prices
+		"21:52:16 MOMENTUM_UP buy_momentum", // after the 2 min cooldown
+		"21:59:01 MOMENTUM_UP merge",        // under a minute to close
+	}
+	for i := 0; i < 2; i++ {
+		if got := run(t); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("replay %d decisions:\n%s\nwant:\n%s", i+1, strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+	}
+}
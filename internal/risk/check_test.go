@@ -0,0 +1,187 @@
+package risk
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+const testCID = "0xcondition0000000000000000000000000000000000000000000000000000000"
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	invested := func(usdc float64, tracked bool) func(*Manager) {
+		return func(r *Manager) {
+			r.inv.RecordBuy(testCID, "up", "down", "UP", usdc*2, usdc)
+			if tracked {
+				r.Track([]*types.Market{{ConditionID: testCID}})
+			}
+		}
+	}
+	tests := []struct {
+		name       string
+		limits     Limits
+		setup      func(*Manager)
+		action     types.Action
+		wantKind   types.ActionKind
+		wantCost   float64 // actionCost of the returned action
+		wantReason string  // substring of the returned reason
+		wantKilled bool
+		wantSpent  float64 // DaySpent after Check
+	}{
+		{
+			name:     "no limits: unchanged",
+			action:   types.BuyArbAction("UP", 40, 20, 0.5, "arb"),
+			wantKind: types.ActionBuyArb, wantCost: 20, wantSpent: 20,
+		},
+		{
+			name:     "non-spending actions pass the kill switch",
+			setup:    func(r *Manager) { r.st.Killed = true },
+			action:   types.MergeAction("close"),
+			wantKind: types.ActionMerge, wantKilled: true,
+		},
+		{
+			name:       "kill switch blocks buys",
+			setup:      func(r *Manager) { r.st.Killed, r.st.KillReason = true, "manual" },
+			action:     types.BuyArbAction("UP", 40, 20, 0.5, "arb"),
+			wantKind:   types.ActionSkip,
+			wantReason: "kill switch latched (manual)",
+			wantKilled: true,
+		},
+		{
+			name:       "shrunk to the exposure headroom",
+			limits:     Limits{MaxExposureUSDC: 50},
+			setup:      invested(40, true),
+			action:     types.BuyArbAction("UP", 40, 20, 0.5, "arb"),
+			wantKind:   types.ActionBuyArb,
+			wantCost:   10,
+			wantReason: "open exposure limit $50",
+			wantSpent:  10,
+		},
+		{
+			name:      "untracked markets are not exposure",
+			limits:    Limits{MaxExposureUSDC: 50},
+			setup:     invested(40, false),
+			action:    types.BuyArbAction("UP", 40, 20, 0.5, "arb"),
+			wantKind:  types.ActionBuyArb,
+			wantCost:  20,
+			wantSpent: 20,
+		},
+		{
+			name:       "pair shrunk to the daily spend headroom",
+			limits:     Limits{MaxDailySpendUSDC: 30},
+			setup:      func(r *Manager) { r.st.DaySpent = 25 },
+			action:     types.BuyArbPairAction(10, 4.8, 5, 0.48, 0.5, "pair"),
+			wantKind:   types.ActionBuyArbPair,
+			wantCost:   5,
+			wantReason: "daily spend limit $30",
+			wantSpent:  30,
+		},
+		{
+			name:       "headroom below the minimum order per leg",
+			limits:     Limits{MaxDailySpendUSDC: 30},
+			setup:      func(r *Manager) { r.st.DaySpent = 28.5 },
+			action:     types.BuyArbPairAction(10, 4.8, 5, 0.48, 0.5, "pair"),
+			wantKind:   types.ActionSkip,
+			wantReason: "daily spend limit $30 reached ($1.50 left)",
+			wantSpent:  28.5,
+		},
+		{
+			name:       "daily loss latches the kill switch",
+			limits:     Limits{MaxDailyLossUSDC: 20},
+			setup:      func(r *Manager) { r.st.DayRealized = -20 },
+			action:     types.BuyArbAction("UP", 10, 5, 0.5, "arb"),
+			wantKind:   types.ActionSkip,
+			wantReason: "daily loss $20.00",
+			wantKilled: true,
+		},
+		{
+			name:       "order rate latches the kill switch",
+			limits:     Limits{MaxOrdersPerMin: 3},
+			setup:      func(r *Manager) { r.orders = []time.Time{now.Add(-30 * time.Second), now} },
+			action:     types.BuyMomentumAction("UP", "DOWN", 5, 1, "momentum"),
+			wantKind:   types.ActionSkip,
+			wantReason: "order rate",
+			wantKilled: true,
+		},
+		{
+			name:      "orders older than a minute leave the window",
+			limits:    Limits{MaxOrdersPerMin: 3},
+			setup:     func(r *Manager) { r.orders = []time.Time{now.Add(-2 * time.Minute), now.Add(-time.Minute)} },
+			action:    types.BuyMomentumAction("UP", "DOWN", 5, 1, "momentum"),
+			wantKind:  types.ActionBuyMomentum,
+			wantCost:  6,
+			wantSpent: 6,
+		},
+		{
+			name:       "quotes are shrunk but not charged",
+			limits:     Limits{MaxDailySpendUSDC: 30},
+			setup:      func(r *Manager) { r.st.DaySpent = 25 },
+			action:     types.QuoteArbAction(0.5, 0.45, 10, 10, "quote"),
+			wantKind:   types.ActionQuoteArb,
+			wantCost:   5,
+			wantReason: "daily spend limit",
+			wantSpent:  25,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewMemory(inventory.NewMemory(), tt.limits)
+			r.clock = clock.NewSim(now)
+			r.rollDay(now)
+			if tt.setup != nil {
+				tt.setup(r)
+			}
+
+			got := r.Check(testCID, tt.action)
+			if got.Kind != tt.wantKind {
+				t.Fatalf("kind = %s (%s), want %s", got.Kind, got.Reason, tt.wantKind)
+			}
+			if cost, _ := actionCost(got); math.Abs(cost-tt.wantCost) > 1e-9 {
+				t.Errorf("cost = %.4f, want %.4f", cost, tt.wantCost)
+			}
+			if !strings.Contains(got.Reason, tt.wantReason) {
+				t.Errorf("reason = %q, want it to contain %q", got.Reason, tt.wantReason)
+			}
+			if r.st.Killed != tt.wantKilled {
+				t.Errorf("killed = %v, want %v", r.st.Killed, tt.wantKilled)
+			}
+			if math.Abs(r.st.DaySpent-tt.wantSpent) > 1e-9 {
+				t.Errorf("day spent = %.4f, want %.4f", r.st.DaySpent, tt.wantSpent)
+			}
+		})
+	}
+}
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		name   string
+		action types.Action
+	}{
+		{"buy arb", types.BuyArbAction("UP", 40, 20, 0.5, "")},
+		{"buy arb pair", types.BuyArbPairAction(10, 4.8, 5, 0.48, 0.5, "")},
+		{"momentum", types.BuyMomentumAction("UP", "DOWN", 8, 2, "")},
+		{"quote", types.QuoteArbAction(0.5, 0.45, 10, 6, "")},
+		{"split sell", types.SplitSellAction(12, 0.55, 0.5, "")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost, orders := actionCost(tt.action)
+			got := scale(tt.action, 0.25)
+			gotCost, gotOrders := actionCost(got)
+			if math.Abs(gotCost-cost*0.25) > 1e-9 || gotOrders != orders {
+				t.Errorf("scaled cost = %.4f (%d orders), want %.4f (%d)", gotCost, gotOrders, cost*0.25, orders)
+			}
+			// Prices and sides are not amounts
+			if got.UpPrice != tt.action.UpPrice || got.DownPrice != tt.action.DownPrice ||
+				got.Side != tt.action.Side || got.MainSide != tt.action.MainSide {
+				t.Errorf("scale changed prices or sides: %+v → %+v", tt.action, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,429 @@
+// Package risk enforces bot-wide limits on top of the FSM's per-market caps.
+// Every FSM action passes through Manager.Check before it reaches the
+// executor; Check lets it through, shrinks it, or replaces it with a skip.
+//
+// Limits (0 disables each one):
+//   - open exposure: net USDC at risk across all tracked markets
+//   - daily spend: USDC spent on buys since 00:00 UTC
+//   - daily loss: realized loss of markets settled since 00:00 UTC
+//   - order rate: market orders per rolling minute
+//
+// Exposure and daily spend shrink actions to the headroom left, then block
+// them. A breach of the daily loss or order-rate limit latches the kill
+// switch: every buy and quote is blocked until an operator calls Reset.
+// The day's totals and the kill switch are persisted, so a restart does
+// not clear them.
+package risk
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/fileutil"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/metrics"
+	"github.com/gipsh/polymarket-bot-go/internal/pnl"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+const (
+	minOrderUSDC   = 1.0            // CLOB minimum market order size
+	settledKeepFor = 48 * time.Hour // how long settled markets are remembered
+)
+
+// Limits are the bot-wide risk limits. Zero disables a limit.
+type Limits struct {
+	MaxExposureUSDC   float64 `json:"max_exposure_usdc"`
+	MaxDailySpendUSDC float64 `json:"max_daily_spend_usdc"`
+	MaxDailyLossUSDC  float64 `json:"max_daily_loss_usdc"`
+	MaxOrdersPerMin   int     `json:"max_orders_per_min"`
+}
+
+// LimitsFromConfig returns the limits configured in the environment.
+func LimitsFromConfig() Limits {
+	return Limits{
+		MaxExposureUSDC:   config.RiskMaxExposureUSDC,
+		MaxDailySpendUSDC: config.RiskMaxDailySpendUSDC,
+		MaxDailyLossUSDC:  config.RiskMaxDailyLossUSDC,
+		MaxOrdersPerMin:   config.RiskMaxOrdersPerMin,
+	}
+}
+
+// state is the persisted part of the manager.
+type state struct {
+	Day         string             `json:"day"` // UTC date the totals belong to
+	DaySpent    float64            `json:"day_spent_usdc"`
+	DayRealized float64            `json:"day_realized_usdc"`
+	Settled     map[string]settled `json:"settled"` // conditionID → settlement
+	Killed      bool               `json:"killed"`
+	KillReason  string             `json:"kill_reason,omitempty"`
+	KilledAt    time.Time          `json:"killed_at,omitempty"`
+}
+
+type settled struct {
+	At  time.Time `json:"at"`
+	PnL float64   `json:"pnl_usdc"`
+}
+
+// Manager applies Limits to FSM actions.
+type Manager struct {
+	mu        sync.Mutex
+	clock     clock.Clock
+	limits    Limits
+	inv       inventory.Inventory
+	filepath  string
+	st        state
+	tracked   map[string]bool // conditionIDs of markets being traded
+	orders    []time.Time     // market orders in the last minute
+	lastBlock string          // last block reason logged (avoids per-tick spam)
+}
+
+// New creates a manager backed by the configured state file.
+func New(inv inventory.Inventory, limits Limits) *Manager {
+	r := NewMemory(inv, limits)
+	r.filepath = config.RiskStateFile
+	r.load()
+	return r
+}
+
+// NewMemory creates a manager that is never persisted to disk.
+// Used by replay mode so simulated spend doesn't touch live state.
+func NewMemory(inv inventory.Inventory, limits Limits) *Manager {
+	return &Manager{
+		clock:   clock.Default(),
+		limits:  limits,
+		inv:     inv,
+		st:      state{Settled: make(map[string]settled)},
+		tracked: make(map[string]bool),
+	}
+}
+
+// ── Main loop hooks ───────────────────────────────────────────────────────
+
+// Track sets the markets whose positions count as open exposure.
+func (r *Manager) Track(markets []*types.Market) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tracked = make(map[string]bool, len(markets))
+	for _, m := range markets {
+		r.tracked[m.ConditionID] = true
+	}
+}
+
+// Check returns the action to execute instead of a: a unchanged, a shrunk
+// to fit the exposure and daily-spend headroom, or a skip explaining which
+// limit blocked it. Actions that don't spend (wait, skip, merge) pass.
+func (r *Manager) Check(conditionID string, a types.Action) types.Action {
+	cost, orders := actionCost(a)
+	if cost == 0 {
+		return a
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.clock.Now()
+	r.rollDay(now)
+
+	if r.st.Killed {
+		return r.block(fmt.Sprintf("kill switch latched (%s) — reset required", r.st.KillReason))
+	}
+	if lim := r.limits.MaxDailyLossUSDC; lim > 0 && -r.st.DayRealized >= lim {
+		r.kill(fmt.Sprintf("daily loss $%.2f ≥ $%.2f", -r.st.DayRealized, lim))
+		return r.block(r.st.KillReason)
+	}
+	r.pruneOrders(now)
+	if lim := r.limits.MaxOrdersPerMin; lim > 0 && len(r.orders)+orders > lim {
+		r.kill(fmt.Sprintf("order rate: %d orders in the last minute + %d > %d",
+			len(r.orders), orders, lim))
+		return r.block(r.st.KillReason)
+	}
+
+	// Headroom under the soft limits
+	headroom, limit := math.Inf(1), ""
+	if lim := r.limits.MaxExposureUSDC; lim > 0 {
+		if h := lim - r.exposure(); h < headroom {
+			headroom, limit = h, fmt.Sprintf("open exposure limit $%.0f", lim)
+		}
+	}
+	if lim := r.limits.MaxDailySpendUSDC; lim > 0 {
+		if h := lim - r.st.DaySpent; h < headroom {
+			headroom, limit = h, fmt.Sprintf("daily spend limit $%.0f", lim)
+		}
+	}
+	if cost > headroom {
+		legs := float64(max(orders, 1))
+		if headroom < minOrderUSDC*legs {
+			return r.block(fmt.Sprintf("%s reached ($%.2f left)", limit, math.Max(0, headroom)))
+		}
+		a = scale(a, headroom/cost)
+		a.Reason += fmt.Sprintf(" | risk: shrunk $%.2f → $%.2f (%s)", cost, headroom, limit)
+		log.Printf("[risk] [%s...] %s action shrunk $%.2f → $%.2f by %s",
+			conditionID[:8], a.Kind, cost, headroom, limit)
+		cost = headroom
+	}
+	r.lastBlock = ""
+
+	// Resting quotes are charged when they fill (RecordSpend), not here
+	if a.Kind != types.ActionQuoteArb {
+		r.st.DaySpent += cost
+		for i := 0; i < orders; i++ {
+			r.orders = append(r.orders, now)
+		}
+		r.save()
+	}
+	return a
+}
+
+// RecordSpend charges USDC spent outside Check (maker fills) to the day.
+func (r *Manager) RecordSpend(usdc float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rollDay(r.clock.Now())
+	r.st.DaySpent += usdc
+	r.save()
+}
+
+// Settle realizes a closed market's P&L once its winner is known (see
+// pnl.Settle). Repeated calls for the same market are ignored.
+func (r *Manager) Settle(conditionID, winner string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, done := r.st.Settled[conditionID]; done {
+		return
+	}
+	e, ok := r.inv.Get(conditionID)
+	if !ok {
+		return
+	}
+	result := pnl.Settle(conditionID, e, winner).Total
+
+	now := r.clock.Now()
+	r.rollDay(now)
+	r.st.Settled[conditionID] = settled{At: now, PnL: result}
+	r.st.DayRealized += result
+	log.Printf("[risk] [%s...] settled %s: P&L $%+.2f | day $%+.2f",
+		conditionID[:8], winner, result, r.st.DayRealized)
+	if lim := r.limits.MaxDailyLossUSDC; lim > 0 && -r.st.DayRealized >= lim {
+		r.kill(fmt.Sprintf("daily loss $%.2f ≥ $%.2f", -r.st.DayRealized, lim))
+	}
+	r.save()
+}
+
+// ── Kill switch ───────────────────────────────────────────────────────────
+
+// Kill latches the kill switch by hand.
+func (r *Manager) Kill(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.kill(reason)
+}
+
+// Reset clears the kill switch and the order-rate window. The day's spend
+// and realized P&L stay, so a daily loss limit that is still breached trips
+// again on the next buy: raise it, or wait for the next UTC day.
+func (r *Manager) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.st.Killed {
+		log.Printf("[risk] kill switch reset (was: %s)", r.st.KillReason)
+	}
+	r.st.Killed, r.st.KillReason, r.st.KilledAt = false, "", time.Time{}
+	r.orders = nil
+	r.lastBlock = ""
+	metrics.RiskKilled(false)
+	r.save()
+}
+
+// kill latches the kill switch. Caller holds r.mu.
+func (r *Manager) kill(reason string) {
+	if r.st.Killed {
+		return
+	}
+	r.st.Killed, r.st.KillReason, r.st.KilledAt = true, reason, r.clock.Now()
+	log.Printf("[risk] 🛑 KILL SWITCH: %s — all buys blocked until reset", reason)
+	metrics.RiskKilled(true)
+	r.save()
+}
+
+// block returns a skip for reason, logging it once per reason.
+// Caller holds r.mu.
+func (r *Manager) block(reason string) types.Action {
+	if reason != r.lastBlock {
+		log.Printf("[risk] blocking buys: %s", reason)
+		r.lastBlock = reason
+	}
+	return types.SkipAction("risk: " + reason)
+}
+
+// ── Status ────────────────────────────────────────────────────────────────
+
+// Status is a snapshot of the manager for the control API.
+type Status struct {
+	Limits      Limits    `json:"limits"`
+	Day         string    `json:"day"`
+	Exposure    float64   `json:"open_exposure_usdc"`
+	DaySpent    float64   `json:"day_spent_usdc"`
+	DayRealized float64   `json:"day_realized_usdc"`
+	OrdersLast  int       `json:"orders_last_minute"`
+	Killed      bool      `json:"killed"`
+	KillReason  string    `json:"kill_reason,omitempty"`
+	KilledAt    time.Time `json:"killed_at,omitempty"`
+}
+
+// Status returns the current limits, usage and kill switch.
+func (r *Manager) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.clock.Now()
+	r.rollDay(now)
+	r.pruneOrders(now)
+	return Status{
+		Limits:      r.limits,
+		Day:         r.st.Day,
+		Exposure:    r.exposure(),
+		DaySpent:    r.st.DaySpent,
+		DayRealized: r.st.DayRealized,
+		OrdersLast:  len(r.orders),
+		Killed:      r.st.Killed,
+		KillReason:  r.st.KillReason,
+		KilledAt:    r.st.KilledAt,
+	}
+}
+
+// ── Internals ─────────────────────────────────────────────────────────────
+
+// exposure is the net USDC still at risk in tracked, unsettled markets:
+// invested minus what merges and sells have returned. Caller holds r.mu.
+func (r *Manager) exposure() float64 {
+	var total float64
+	for cid, e := range r.inv.Snapshot() {
+		if _, done := r.st.Settled[cid]; done || !r.tracked[cid] {
+			continue
+		}
+		total += math.Max(0, e.TotalInvested-e.TotalMerged-e.TotalSold)
+	}
+	return total
+}
+
+// rollDay starts new daily totals at 00:00 UTC. Caller holds r.mu.
+func (r *Manager) rollDay(now time.Time) {
+	day := now.UTC().Format("2006-01-02")
+	if r.st.Day == day {
+		return
+	}
+	if r.st.Day != "" {
+		log.Printf("[risk] new day %s (was %s: spent $%.2f, realized $%+.2f)",
+			day, r.st.Day, r.st.DaySpent, r.st.DayRealized)
+	}
+	r.st.Day, r.st.DaySpent, r.st.DayRealized = day, 0, 0
+	for cid, s := range r.st.Settled {
+		if now.Sub(s.At) > settledKeepFor {
+			delete(r.st.Settled, cid)
+		}
+	}
+	r.save()
+}
+
+// pruneOrders drops orders older than a minute. Caller holds r.mu.
+func (r *Manager) pruneOrders(now time.Time) {
+	i := 0
+	for i < len(r.orders) && now.Sub(r.orders[i]) >= time.Minute {
+		i++
+	}
+	r.orders = r.orders[i:]
+}
+
+// actionCost returns the USDC an action commits and how many market orders
+// it places.
+func actionCost(a types.Action) (usdc float64, orders int) {
+	switch a.Kind {
+	case types.ActionBuyArb:
+		return a.ArbUSDC, 1
+	case types.ActionBuyArbPair:
+		return a.UpUSDC + a.DownUSDC, 2
+	case types.ActionBuyMomentum:
+		return a.MainUSDC + a.HedgeUSDC, 2
+	case types.ActionQuoteArb:
+		return a.UpPrice*a.UpSize + a.DownPrice*a.DownSize, 0
+	case types.ActionSplitSell:
+		return a.Pairs, 2 // USDC split, then two sells
+	}
+	return 0, 0
+}
+
+// scale shrinks every amount of a spending action by f (0 < f < 1).
+func scale(a types.Action, f float64) types.Action {
+	a.ArbUSDC *= f
+	a.UpUSDC *= f
+	a.DownUSDC *= f
+	a.Pairs *= f
+	a.MainUSDC *= f
+	a.HedgeUSDC *= f
+	a.UpSize *= f
+	a.DownSize *= f
+	return a
+}
+
+// ── Persistence ───────────────────────────────────────────────────────────
+
+func (r *Manager) load() {
+	data, err := os.ReadFile(r.filepath)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &r.st)
+	}
+	if err != nil {
+		r.unreadable(err)
+	}
+	if r.st.Settled == nil {
+		r.st.Settled = make(map[string]settled)
+	}
+	metrics.RiskKilled(r.st.Killed)
+	if r.st.Killed {
+		log.Printf("[risk] 🛑 kill switch still latched from %s: %s",
+			r.st.KilledAt.Format(time.RFC3339), r.st.KillReason)
+	}
+}
+
+// unreadable handles a state file that cannot be loaded. The day's totals
+// and a possibly latched kill switch are unknown, so the manager starts
+// with the switch latched rather than fresh; the file is set aside for
+// inspection and an operator resets the switch once satisfied.
+func (r *Manager) unreadable(err error) {
+	bad := fmt.Sprintf("%s.corrupt-%d", r.filepath, time.Now().Unix())
+	if rerr := os.Rename(r.filepath, bad); rerr != nil {
+		bad = r.filepath
+	}
+	r.st = state{
+		Day:        r.clock.Now().UTC().Format("2006-01-02"),
+		Settled:    make(map[string]settled),
+		Killed:     true,
+		KillReason: fmt.Sprintf("risk state unreadable (%v), kept at %s", err, bad),
+		KilledAt:   r.clock.Now(),
+	}
+	log.Printf("[risk] 🛑 %s — kill switch latched until reset", r.st.KillReason)
+	r.save()
+}
+
+// save persists the state. Caller holds r.mu.
+func (r *Manager) save() {
+	if r.filepath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(r.st, "", "  ")
+	if err == nil {
+		err = fileutil.WriteAtomic(r.filepath, data, 0600)
+	}
+	if err != nil {
+		log.Printf("[risk] save error: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package risk
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+func TestSettle(t *testing.T) {
+	tests := []struct {
+		name         string
+		winner       string
+		settles      int // calls for the same market
+		wantRealized float64
+		wantKilled   bool
+	}{
+		{name: "winning market", winner: "UP", settles: 1, wantRealized: 15},
+		{name: "losing market trips the daily loss", winner: "DOWN", settles: 1, wantRealized: -25, wantKilled: true},
+		{name: "settled once however often called", winner: "DOWN", settles: 3, wantRealized: -25, wantKilled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 40 UP bought for $25
+			inv := inventory.NewMemory()
+			inv.RecordBuy(testCID, "up", "down", "UP", 40, 25)
+			r := NewMemory(inv, Limits{MaxDailyLossUSDC: 20})
+			r.clock = clock.NewSim(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+
+			for i := 0; i < tt.settles; i++ {
+				r.Settle(testCID, tt.winner)
+			}
+			if math.Abs(r.st.DayRealized-tt.wantRealized) > 1e-9 {
+				t.Errorf("day realized = $%.2f, want $%.2f", r.st.DayRealized, tt.wantRealized)
+			}
+			if r.st.Killed != tt.wantKilled {
+				t.Errorf("killed = %v (%s), want %v", r.st.Killed, r.st.KillReason, tt.wantKilled)
+			}
+			if got := r.Check(testCID, types.BuyArbAction("UP", 10, 5, 0.5, "arb")); (got.Kind == types.ActionSkip) != tt.wantKilled {
+				t.Errorf("buy after settling: %s (%s)", got.Kind, got.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package risk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	config.RiskStateFile = filepath.Join(t.TempDir(), "risk_state.json")
+	r := New(inventory.NewMemory(), Limits{})
+	r.RecordSpend(12.5)
+	r.mu.Lock()
+	r.kill("test")
+	r.mu.Unlock()
+
+	r2 := New(inventory.NewMemory(), Limits{})
+	if !r2.st.Killed || r2.st.DaySpent != 12.5 {
+		t.Errorf("reloaded killed=%v spent=%.2f, want true 12.50", r2.st.Killed, r2.st.DaySpent)
+	}
+}
+
+func TestUnreadableStateLatchesKillSwitch(t *testing.T) {
+	dir := t.TempDir()
+	config.RiskStateFile = filepath.Join(dir, "risk_state.json")
+	if err := os.WriteFile(config.RiskStateFile, []byte(`{"day":"2026-10-16","day_spent_usdc":4`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(inventory.NewMemory(), Limits{})
+	if !r.st.Killed {
+		t.Fatal("kill switch not latched after an unreadable state file")
+	}
+	entries, _ := os.ReadDir(dir)
+	var aside bool
+	for _, e := range entries {
+		aside = aside || strings.Contains(e.Name(), ".corrupt-")
+	}
+	if !aside {
+		t.Error("unreadable state file not set aside")
+	}
+
+	// The latched state was saved: a restart stays latched
+	if r2 := New(inventory.NewMemory(), Limits{}); !r2.st.Killed {
+		t.Error("kill switch not latched after restart")
+	}
+}
@@ -0,0 +1,168 @@
+// Package settle follows markets after they close until their winner is
+// known, then settles each one once (risk manager's daily loss, P&L ledger).
+//
+// A closed market usually drops out of the active market list at the next
+// refresh, long before its prices show a winner, so settlement can't wait
+// for the main loop to see it resolved. The Tracker keeps every closed
+// market with a position pending and polls its prices until one side
+// reaches RESOLVED.
+package settle
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+const (
+	pollEvery = 30 * time.Second // between price checks of a pending market
+	giveUp    = 24 * time.Hour   // after close, stop waiting for a winner
+)
+
+// PriceSource serves current prices (REST pricer or replay player).
+type PriceSource interface {
+	GetPrices(ctx context.Context, upTokenID, downTokenID string) (*types.Prices, error)
+}
+
+// OnSettleFunc settles a resolved market against its winner ("UP"/"DOWN").
+type OnSettleFunc func(m *types.Market, winner string)
+
+type pending struct {
+	market   *types.Market
+	lastPoll time.Time
+}
+
+// Tracker holds closed markets waiting for a winner.
+type Tracker struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	inv      inventory.Inventory
+	prices   PriceSource
+	onSettle OnSettleFunc
+	pending  map[string]*pending  // conditionID → market
+	settled  map[string]time.Time // conditionID → when settled
+}
+
+// New creates a tracker that settles through onSettle, reading prices of
+// markets no longer polled by the main loop from prices.
+func New(inv inventory.Inventory, prices PriceSource, onSettle OnSettleFunc) *Tracker {
+	return &Tracker{
+		clock:    clock.Default(),
+		inv:      inv,
+		prices:   prices,
+		onSettle: onSettle,
+		pending:  make(map[string]*pending),
+		settled:  make(map[string]time.Time),
+	}
+}
+
+// Observe is called for every market the main loop priced. A closed market
+// is settled at once if resolved, else kept pending.
+func (t *Tracker) Observe(m *types.Market, prices *types.Prices) {
+	if m.IsOpen() {
+		return
+	}
+	if prices.State == types.StateResolved {
+		t.settle(m, prices.Winner())
+		return
+	}
+	t.Add(m)
+}
+
+// Refresh is called with the new active market list: markets that left it
+// are kept pending until they resolve.
+func (t *Tracker) Refresh(old, active []*types.Market) {
+	still := make(map[string]bool, len(active))
+	for _, m := range active {
+		still[m.ConditionID] = true
+	}
+	for _, m := range old {
+		if !still[m.ConditionID] {
+			t.Add(m)
+		}
+	}
+}
+
+// Add keeps a market pending until it resolves. Markets never traded and
+// markets already settled are ignored.
+func (t *Tracker) Add(m *types.Market) {
+	if _, ok := t.inv.Get(m.ConditionID); !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, done := t.settled[m.ConditionID]; done {
+		return
+	}
+	if _, ok := t.pending[m.ConditionID]; !ok {
+		log.Printf("[settle] [%s...] %s closed — waiting for its winner", m.ConditionID[:8], m.Slug)
+		t.pending[m.ConditionID] = &pending{market: m}
+	}
+}
+
+// Poll checks the prices of pending markets (each at most every pollEvery)
+// and settles the resolved ones. Markets still unresolved giveUp after
+// their close are dropped with a warning. Called from the main loop.
+func (t *Tracker) Poll(ctx context.Context) {
+	now := t.clock.Now()
+	t.mu.Lock()
+	var due []*types.Market
+	for cid, p := range t.pending {
+		switch {
+		case now.Sub(p.market.EndDate) > giveUp:
+			log.Printf("[settle] ⚠ [%s...] %s still unresolved %s after close — not settled",
+				cid[:8], p.market.Slug, giveUp)
+			delete(t.pending, cid)
+		case now.Sub(p.lastPoll) >= pollEvery:
+			p.lastPoll = now
+			due = append(due, p.market)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, m := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		prices, err := t.prices.GetPrices(ctx, m.UpTokenID, m.DownTokenID)
+		if err != nil {
+			log.Printf("[settle] [%s...] price check failed: %v", m.ConditionID[:8], err)
+			continue
+		}
+		if prices.State == types.StateResolved {
+			t.settle(m, prices.Winner())
+		}
+	}
+}
+
+// Pending returns how many markets are waiting for a winner.
+func (t *Tracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending)
+}
+
+// settle calls onSettle once per market.
+func (t *Tracker) settle(m *types.Market, winner string) {
+	now := t.clock.Now()
+	t.mu.Lock()
+	if _, done := t.settled[m.ConditionID]; done {
+		t.mu.Unlock()
+		return
+	}
+	t.settled[m.ConditionID] = now
+	delete(t.pending, m.ConditionID)
+	for cid, at := range t.settled {
+		if now.Sub(at) > giveUp {
+			delete(t.settled, cid)
+		}
+	}
+	t.mu.Unlock()
+
+	t.onSettle(m, winner)
+}
@@ -0,0 +1,142 @@
+package settle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
+	"github.com/gipsh/polymarket-bot-go/internal/inventory"
+	"github.com/gipsh/polymarket-bot-go/internal/risk"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+const testCID = "0xcondition0000000000000000000000000000000000000000000000000000000"
+
+// quotes is a PriceSource returning the next of a fixed list of prices.
+type quotes struct {
+	next  []*types.Prices
+	calls int
+}
+
+func (q *quotes) GetPrices(context.Context, string, string) (*types.Prices, error) {
+	q.calls++
+	if len(q.next) == 0 {
+		return nil, errors.New("no price")
+	}
+	p := q.next[0]
+	q.next = q.next[1:]
+	return p, nil
+}
+
+var (
+	open     = &types.Prices{Up: 0.60, Down: 0.41, State: types.StateGrey}
+	downWins = &types.Prices{Up: 0.001, Down: 0.999, State: types.StateResolved}
+)
+
+type settlement struct {
+	cid, winner string
+}
+
+// setup returns a tracker over a traded market that closed a minute ago.
+func setup(t *testing.T, src PriceSource) (*Tracker, *clock.Sim, *types.Market, *[]settlement) {
+	t.Helper()
+	inv := inventory.NewMemory()
+	inv.RecordBuy(testCID, "up", "down", "UP", 10, 5)
+	var got []settlement
+	tr := New(inv, src, func(m *types.Market, winner string) {
+		got = append(got, settlement{m.ConditionID, winner})
+	})
+	clk := clock.NewSim(time.Now())
+	tr.clock = clk
+	m := &types.Market{ConditionID: testCID, Slug: "btc", UpTokenID: "up", DownTokenID: "down", EndDate: time.Now().Add(-time.Minute)}
+	return tr, clk, m, &got
+}
+
+func TestSettledWhenDroppedFromActiveList(t *testing.T) {
+	src := &quotes{next: []*types.Prices{open, downWins}}
+	tr, clk, m, got := setup(t, src)
+
+	// The refresh drops the closed market before it resolved
+	tr.Refresh([]*types.Market{m}, nil)
+	tr.Poll(context.Background())
+	if len(*got) != 0 || tr.Pending() != 1 {
+		t.Fatalf("settled %v with %d pending, want nothing settled yet and 1 pending", *got, tr.Pending())
+	}
+
+	// Not polled again before pollEvery
+	tr.Poll(context.Background())
+	if src.calls != 1 {
+		t.Errorf("%d price checks within %s, want 1", src.calls, pollEvery)
+	}
+
+	clk.Advance(pollEvery)
+	tr.Poll(context.Background())
+	if len(*got) != 1 || (*got)[0] != (settlement{testCID, "DOWN"}) {
+		t.Fatalf("settlements = %v, want one DOWN", *got)
+	}
+	if tr.Pending() != 0 {
+		t.Errorf("%d still pending after settling", tr.Pending())
+	}
+}
+
+func TestSettledOnce(t *testing.T) {
+	tr, clk, m, got := setup(t, &quotes{})
+	for i := 0; i < 3; i++ {
+		tr.Observe(m, downWins) // the main loop keeps seeing it until the next refresh
+		tr.Refresh([]*types.Market{m}, nil)
+		clk.Advance(pollEvery)
+		tr.Poll(context.Background())
+	}
+	if len(*got) != 1 {
+		t.Errorf("settled %d times, want once", len(*got))
+	}
+}
+
+func TestOpenAndUntradedMarketsIgnored(t *testing.T) {
+	src := &quotes{}
+	tr, _, m, got := setup(t, src)
+
+	live := *m
+	live.EndDate = time.Now().Add(time.Hour)
+	tr.Observe(&live, downWins)
+
+	untraded := *m
+	untraded.ConditionID = "0xother0000000000000000000000000000000000000000000000000000000000"
+	tr.Refresh([]*types.Market{&untraded}, nil)
+	tr.Poll(context.Background())
+
+	if len(*got) != 0 || tr.Pending() != 0 || src.calls != 0 {
+		t.Errorf("settled %v, %d pending, %d price checks; want nothing", *got, tr.Pending(), src.calls)
+	}
+}
+
+func TestUnresolvedGivenUp(t *testing.T) {
+	tr, clk, m, got := setup(t, &quotes{})
+	tr.Observe(m, open)
+	clk.Advance(giveUp + time.Minute)
+	tr.Poll(context.Background())
+	if tr.Pending() != 0 || len(*got) != 0 {
+		t.Errorf("%d pending, settled %v; want it dropped unsettled", tr.Pending(), *got)
+	}
+}
+
+func TestResolvedLossTripsDailyLimit(t *testing.T) {
+	inv := inventory.NewMemory()
+	inv.RecordBuy(testCID, "up", "down", "UP", 40, 25) // UP loses: -$25
+	riskMgr := risk.NewMemory(inv, risk.Limits{MaxDailyLossUSDC: 20})
+	tr := New(inv, &quotes{next: []*types.Prices{downWins}}, func(m *types.Market, winner string) {
+		riskMgr.Settle(m.ConditionID, winner)
+	})
+	m := &types.Market{ConditionID: testCID, EndDate: time.Now().Add(-time.Minute)}
+
+	// Closed, then dropped by the refresh before its prices resolved
+	tr.Observe(m, open)
+	tr.Refresh([]*types.Market{m}, nil)
+	tr.Poll(context.Background())
+
+	if st := riskMgr.Status(); !st.Killed {
+		t.Errorf("kill switch not latched after a $25 resolution loss (day realized $%.2f)", st.DayRealized)
+	}
+}
@@ -0,0 +1,189 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// migration is one schema change. Migrations are applied in order, each in
+// its own transaction, and recorded in schema_migrations. Never edit a
+// migration that has shipped: append a new one.
+type migration struct {
+	version int
+	name    string
+	stmts   []string
+}
+
+var migrations = []migration{
+	{1, "initial schema", []string{
+		`CREATE TABLE markets (
+			condition_id  TEXT PRIMARY KEY,
+			asset         TEXT NOT NULL,
+			slug          TEXT NOT NULL,
+			title         TEXT NOT NULL,
+			up_token_id   TEXT NOT NULL,
+			down_token_id TEXT NOT NULL,
+			end_date      TEXT NOT NULL,
+			first_seen    TEXT NOT NULL,
+			last_seen     TEXT NOT NULL
+		)`,
+
+		`CREATE TABLE actions (
+			id           INTEGER PRIMARY KEY,
+			ts           TEXT NOT NULL,
+			condition_id TEXT NOT NULL,
+			state        TEXT NOT NULL,
+			kind         TEXT NOT NULL,
+			reason       TEXT NOT NULL,
+			up           REAL NOT NULL, -- UP ask when emitted
+			down         REAL NOT NULL, -- DOWN ask when emitted
+			params       TEXT NOT NULL  -- the full action, JSON
+		)`,
+		`CREATE INDEX actions_condition ON actions (condition_id, ts)`,
+
+		`CREATE TABLE orders (
+			id           INTEGER PRIMARY KEY,
+			ts           TEXT NOT NULL,
+			order_id     TEXT, -- NULL when rejected
+			condition_id TEXT NOT NULL,
+			token_id     TEXT NOT NULL,
+			outcome      TEXT NOT NULL, -- UP / DOWN
+			side         TEXT NOT NULL, -- BUY / SELL
+			order_type   TEXT NOT NULL, -- FOK / GTC / GTD
+			price        REAL NOT NULL,
+			size         REAL NOT NULL, -- tokens
+			usdc         REAL NOT NULL,
+			status       TEXT NOT NULL,
+			error        TEXT,
+			updated_at   TEXT NOT NULL
+		)`,
+		`CREATE INDEX orders_order_id ON orders (order_id)`,
+		`CREATE INDEX orders_condition ON orders (condition_id, ts)`,
+
+		`CREATE TABLE fills (
+			id           INTEGER PRIMARY KEY,
+			ts           TEXT NOT NULL,
+			trade_id     TEXT NOT NULL,
+			order_id     TEXT NOT NULL,
+			condition_id TEXT NOT NULL,
+			asset_id     TEXT NOT NULL,
+			side         TEXT NOT NULL,
+			outcome      TEXT NOT NULL,
+			price        REAL NOT NULL,
+			size         REAL NOT NULL,
+			status       TEXT NOT NULL, -- MATCHED / MINED / CONFIRMED / FAILED …
+			tx_hash      TEXT,
+			UNIQUE (trade_id, order_id, status)
+		)`,
+		`CREATE INDEX fills_order_id ON fills (order_id)`,
+
+		`CREATE TABLE chain_txs (
+			id           INTEGER PRIMARY KEY,
+			ts           TEXT NOT NULL,
+			kind         TEXT NOT NULL, -- merge / split / redeem
+			condition_id TEXT NOT NULL,
+			usdc         REAL NOT NULL,
+			tx_hash      TEXT,
+			status       TEXT NOT NULL
+		)`,
+		`CREATE INDEX chain_txs_condition ON chain_txs (condition_id, ts)`,
+
+		`CREATE TABLE prices (
+			id           INTEGER PRIMARY KEY,
+			ts           TEXT NOT NULL,
+			condition_id TEXT NOT NULL,
+			up           REAL NOT NULL,
+			down         REAL NOT NULL,
+			up_bid       REAL NOT NULL,
+			down_bid     REAL NOT NULL,
+			spread       REAL NOT NULL,
+			state        TEXT NOT NULL
+		)`,
+		`CREATE INDEX prices_condition ON prices (condition_id, ts)`,
+
+		// Inventory (see inventory/sqlite.go): current state per condition
+		// plus every change that produced it
+		`CREATE TABLE inventory (
+			condition_id        TEXT PRIMARY KEY,
+			up_token_id         TEXT NOT NULL,
+			down_token_id       TEXT NOT NULL,
+			up_balance          REAL NOT NULL,
+			down_balance        REAL NOT NULL,
+			up_cost_usdc        REAL NOT NULL,
+			down_cost_usdc      REAL NOT NULL,
+			total_invested_usdc REAL NOT NULL,
+			total_merged_usdc   REAL NOT NULL,
+			total_sold_usdc     REAL NOT NULL,
+			total_redeemed_usdc REAL NOT NULL,
+			fees_usdc           REAL NOT NULL,
+			redeemed            INTEGER NOT NULL,
+			updated_at          TEXT NOT NULL
+		)`,
+		`CREATE TABLE inventory_events (
+			seq          INTEGER PRIMARY KEY,
+			ts           TEXT NOT NULL,
+			kind         TEXT NOT NULL, -- buy / sell / merge / split / redeem / fee / reconcile / onchain
+			condition_id TEXT,
+			side         TEXT,
+			tokens       REAL,
+			usdc         REAL,
+			data         TEXT -- the full event, JSON
+		)`,
+		`CREATE INDEX inventory_events_condition ON inventory_events (condition_id, seq)`,
+	}},
+	{2, "inventory split totals", []string{
+		`ALTER TABLE inventory ADD COLUMN total_split_usdc REAL NOT NULL DEFAULT 0`,
+	}},
+}
+
+func latestVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// migrate brings the schema up to the latest migration.
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return err
+	}
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+	if current > latestVersion() {
+		return fmt.Errorf("database schema v%d is newer than this build (v%d)", current, latestVersion())
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := apply(db, m); err != nil {
+			return fmt.Errorf("v%d (%s): %w", m.version, m.name, err)
+		}
+		log.Printf("[store] migrated to v%d: %s", m.version, m.name)
+	}
+	return nil
+}
+
+func apply(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op after Commit
+	for _, stmt := range m.stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.version, m.name, Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
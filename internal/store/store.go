@@ -0,0 +1,289 @@
+// Package store is the optional SQLite database (STORAGE_BACKEND=sqlite).
+//
+// It keeps the bot's history queryable with SQL: markets seen, actions
+// emitted by the FSM, orders submitted, user-WS fills, on-chain
+// merges/splits/redeems with their tx hashes and price snapshots. The
+// SQLite inventory (inventory.NewSQLite) lives in the same database. The
+// schema is versioned by the migrations in migrate.go.
+//
+// The driver is modernc.org/sqlite: pure Go, so the bot still builds with
+// CGO_ENABLED=0.
+//
+// Record* methods are no-ops on a nil *DB, so callers can hold one whether
+// or not the backend is enabled. Write errors are logged, never returned:
+// history must not stop trading.
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+// driverName is the database/sql driver registered by modernc.org/sqlite.
+const driverName = "sqlite"
+
+// TimeFormat is how timestamps are stored: UTC, and understood by SQLite's
+// date and time functions.
+const TimeFormat = "2006-01-02 15:04:05.000"
+
+// DB is the SQLite database.
+type DB struct {
+	db *sql.DB
+
+	mu         sync.Mutex
+	lastAction map[string]types.Action // conditionID → last action recorded
+	lastPrices map[string]time.Time    // conditionID → last price snapshot
+}
+
+// Open opens (creating if needed) the database at path and migrates it to
+// the latest schema.
+func Open(path string) (*DB, error) {
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		return nil, err
+	}
+	// One connection: SQLite allows a single writer anyway, and the
+	// per-connection pragmas below then hold for every statement
+	db.SetMaxOpenConns(1)
+	for _, pragma := range []string{
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA synchronous = NORMAL",
+		"PRAGMA busy_timeout = 5000",
+		"PRAGMA foreign_keys = ON",
+	} {
+		if _, err := db.Exec(pragma); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("%s: %w", pragma, err)
+		}
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	log.Printf("[store] opened %s (schema v%d)", path, latestVersion())
+	return &DB{
+		db:         db,
+		lastAction: make(map[string]types.Action),
+		lastPrices: make(map[string]time.Time),
+	}, nil
+}
+
+// SQL returns the underlying handle, for packages that keep their own
+// tables in the database (inventory).
+func (d *DB) SQL() *sql.DB {
+	return d.db
+}
+
+// Close closes the database.
+func (d *DB) Close() error {
+	if d == nil {
+		return nil
+	}
+	return d.db.Close()
+}
+
+// ── Markets ───────────────────────────────────────────────────────────────
+
+// RecordMarket inserts a market the first time it is seen and bumps its
+// last_seen afterwards.
+func (d *DB) RecordMarket(m *types.Market) {
+	if d == nil {
+		return
+	}
+	now := Now()
+	d.exec("market", `
+		INSERT INTO markets (condition_id, asset, slug, title, up_token_id, down_token_id, end_date, first_seen, last_seen)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (condition_id) DO UPDATE SET last_seen = excluded.last_seen`,
+		m.ConditionID, m.Asset, m.Slug, m.Title, m.UpTokenID, m.DownTokenID,
+		m.EndDate.UTC().Format(TimeFormat), now, now)
+}
+
+// ── FSM actions ───────────────────────────────────────────────────────────
+
+// RecordAction records an action the FSM emitted for a market. Waits and
+// skips are not recorded, nor an action identical to the previous one of
+// the same market (a maker quote is re-emitted every tick).
+func (d *DB) RecordAction(conditionID string, state types.BotState, prices *types.Prices, action types.Action) {
+	if d == nil || action.Kind == types.ActionWait || action.Kind == types.ActionSkip {
+		return
+	}
+	d.mu.Lock()
+	if d.lastAction[conditionID] == action {
+		d.mu.Unlock()
+		return
+	}
+	d.lastAction[conditionID] = action
+	d.mu.Unlock()
+
+	params, _ := json.Marshal(action)
+	d.exec("action", `
+		INSERT INTO actions (ts, condition_id, state, kind, reason, up, down, params)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		Now(), conditionID, state.String(), string(action.Kind), action.Reason,
+		prices.Up, prices.Down, string(params))
+}
+
+// ── Orders ────────────────────────────────────────────────────────────────
+
+// Order statuses. Market (FOK) orders follow their lifecycle instead
+// (types.OrderStatus: submitted → matched → mined → confirmed / failed),
+// and are marked unconfirmed when they take too long to get there.
+const (
+	OrderPlaced      = "placed"
+	OrderRejected    = "rejected" // the POST failed
+	OrderDryRun      = "dry_run"
+	OrderCancelled   = "cancelled"
+	OrderExpired     = "expired"
+	OrderFilled      = "filled"
+	OrderUnconfirmed = "unconfirmed"
+)
+
+// Order is one order submitted to the CLOB (or simulated in dry run).
+type Order struct {
+	OrderID     string // empty when the CLOB rejected it
+	ConditionID string
+	TokenID     string
+	Outcome     string // "UP" or "DOWN"
+	Side        types.OrderSide
+	Type        string  // FOK, GTC or GTD
+	Price       float64 // limit price, or the price hint of a market order
+	Size        float64 // tokens
+	USDC        float64
+	Status      string
+	Error       string
+}
+
+// RecordOrder records a submitted order.
+func (d *DB) RecordOrder(o Order) {
+	if d == nil {
+		return
+	}
+	now := Now()
+	d.exec("order", `
+		INSERT INTO orders (ts, order_id, condition_id, token_id, outcome, side, order_type, price, size, usdc, status, error, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		now, nullable(o.OrderID), o.ConditionID, o.TokenID, o.Outcome, o.Side.String(), o.Type,
+		o.Price, o.Size, o.USDC, o.Status, nullable(o.Error), now)
+}
+
+// OrderStatus updates the status of a recorded order (the latest one with
+// that ID: dry-run IDs repeat across runs).
+func (d *DB) OrderStatus(orderID, status string) {
+	if d == nil || orderID == "" {
+		return
+	}
+	d.exec("order status", `
+		UPDATE orders SET status = ?, updated_at = ?
+		WHERE id = (SELECT MAX(id) FROM orders WHERE order_id = ?)`,
+		status, Now(), orderID)
+}
+
+// CorrectOrder updates the size and USDC of a recorded order to what it
+// actually filled.
+func (d *DB) CorrectOrder(orderID string, size, usdc float64) {
+	if d == nil || orderID == "" {
+		return
+	}
+	d.exec("order correction", `
+		UPDATE orders SET size = ?, usdc = ?, updated_at = ?
+		WHERE id = (SELECT MAX(id) FROM orders WHERE order_id = ?)`,
+		size, usdc, Now(), orderID)
+}
+
+// ── Fills ─────────────────────────────────────────────────────────────────
+
+// RecordFill records a user-WS fill. The CLOB re-sends a trade as its
+// status advances; each (trade, order, status) is stored once.
+func (d *DB) RecordFill(f types.FillEvent) {
+	if d == nil {
+		return
+	}
+	d.exec("fill", `
+		INSERT INTO fills (ts, trade_id, order_id, condition_id, asset_id, side, outcome, price, size, status, tx_hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (trade_id, order_id, status) DO NOTHING`,
+		Now(), f.TradeID, f.OrderID, f.ConditionID, f.AssetID, f.Side, f.Outcome,
+		f.Price, f.Size, f.Status, nullable(f.TxHash))
+}
+
+// ── On-chain transactions ─────────────────────────────────────────────────
+
+// On-chain operations.
+const (
+	TxMerge  = "merge"
+	TxSplit  = "split"
+	TxRedeem = "redeem"
+)
+
+// On-chain transaction statuses.
+const (
+	TxOK     = "ok"
+	TxFailed = "failed"
+	TxDryRun = "dry_run"
+)
+
+// RecordChainTx records a merge, split or redeem. usdc is the USDC
+// received (merge, redeem) or spent (split).
+func (d *DB) RecordChainTx(kind, conditionID string, usdc float64, txHash, status string) {
+	if d == nil {
+		return
+	}
+	d.exec("chain tx", `
+		INSERT INTO chain_txs (ts, kind, condition_id, usdc, tx_hash, status)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		Now(), kind, conditionID, usdc, nullable(txHash), status)
+}
+
+// ── Prices ────────────────────────────────────────────────────────────────
+
+// RecordPrices stores a price snapshot of a market, at most one every
+// SQLITE_PRICE_INTERVAL_SEC per market.
+func (d *DB) RecordPrices(conditionID string, p *types.Prices) {
+	if d == nil {
+		return
+	}
+	interval := time.Duration(config.SQLitePriceIntervalSec) * time.Second
+	d.mu.Lock()
+	if time.Since(d.lastPrices[conditionID]) < interval {
+		d.mu.Unlock()
+		return
+	}
+	d.lastPrices[conditionID] = time.Now()
+	d.mu.Unlock()
+
+	d.exec("prices", `
+		INSERT INTO prices (ts, condition_id, up, down, up_bid, down_bid, spread, state)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		Now(), conditionID, p.Up, p.Down, p.UpBid, p.DownBid, p.Spread, string(p.State))
+}
+
+// ── Helpers ───────────────────────────────────────────────────────────────
+
+// Now returns the current time in TimeFormat.
+func Now() string {
+	return time.Now().UTC().Format(TimeFormat)
+}
+
+func (d *DB) exec(what, query string, args ...interface{}) {
+	if _, err := d.db.Exec(query, args...); err != nil {
+		log.Printf("[store] %s write failed: %v", what, err)
+	}
+}
+
+// nullable stores empty strings as NULL.
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
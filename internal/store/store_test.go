@@ -0,0 +1,133 @@
+package store
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+const testCID = "0xcondition0000000000000000000000000000000000000000000000000000000"
+
+func count(t *testing.T, d *DB, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+	if err := d.SQL().QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+func TestMigrateFromV1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bot.db")
+
+	// A database left by a build that only knew v1, holding a position
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at TEXT NOT NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	if err := apply(db, migrations[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO inventory VALUES (?, 'up', 'down', 10, 8, 4, 4.4, 8.4, 0, 0, 0, 0, 0, ?)`,
+		testCID, Now()); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	for i := 0; i < 2; i++ { // the second open finds nothing to do
+		d, err := Open(path)
+		if err != nil {
+			t.Fatalf("open %d: %v", i, err)
+		}
+		if n := count(t, d, `SELECT COUNT(*) FROM schema_migrations`); n != latestVersion() {
+			t.Errorf("open %d: %d migrations recorded, want %d", i, n, latestVersion())
+		}
+		var up, split float64
+		if err := d.SQL().QueryRow(`SELECT up_balance, total_split_usdc FROM inventory WHERE condition_id = ?`,
+			testCID).Scan(&up, &split); err != nil {
+			t.Fatalf("open %d: read migrated row: %v", i, err)
+		}
+		if up != 10 || split != 0 {
+			t.Errorf("open %d: migrated row up %.0f, split $%.2f; want 10 and $0", i, up, split)
+		}
+		d.Close()
+	}
+}
+
+func TestNewerSchemaRefused(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bot.db")
+	d, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SQL().Exec(`INSERT INTO schema_migrations VALUES (?, 'future', ?)`, latestVersion()+1, Now())
+	d.Close()
+
+	if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "newer than this build") {
+		t.Errorf("err = %v, want the newer schema refused", err)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	d, err := Open(filepath.Join(t.TempDir(), "bot.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	m := &types.Market{ConditionID: testCID, Slug: "btc", UpTokenID: "up", DownTokenID: "down", EndDate: time.Now()}
+	d.RecordMarket(m)
+	d.RecordMarket(m)
+	if n := count(t, d, `SELECT COUNT(*) FROM markets`); n != 1 {
+		t.Errorf("%d market rows after seeing it twice, want 1", n)
+	}
+
+	// A repeated action and waits are not recorded
+	prices := &types.Prices{Up: 0.45, Down: 0.53}
+	buy := types.Action{Kind: types.ActionBuyArbPair, Pairs: 10}
+	d.RecordAction(testCID, types.BotARB, prices, buy)
+	d.RecordAction(testCID, types.BotARB, prices, buy)
+	d.RecordAction(testCID, types.BotARB, prices, types.Action{Kind: types.ActionWait})
+	if n := count(t, d, `SELECT COUNT(*) FROM actions`); n != 1 {
+		t.Errorf("%d action rows, want 1", n)
+	}
+
+	// Dry-run IDs repeat across runs: updates go to the latest order
+	for i := 0; i < 2; i++ {
+		d.RecordOrder(Order{OrderID: "dry-1", ConditionID: testCID, TokenID: "up", Outcome: "UP",
+			Side: types.SideBuy, Type: "FOK", Price: 0.45, Size: 10, USDC: 4.5, Status: OrderDryRun})
+	}
+	d.OrderStatus("dry-1", OrderFilled)
+	if n := count(t, d, `SELECT COUNT(*) FROM orders WHERE status = ?`, OrderFilled); n != 1 {
+		t.Errorf("%d orders marked filled, want only the latest", n)
+	}
+	if n := count(t, d, `SELECT COUNT(*) FROM orders WHERE order_id = 'dry-1' AND id = (SELECT MAX(id) FROM orders) AND status = ?`, OrderFilled); n != 1 {
+		t.Error("latest order not the one marked filled")
+	}
+
+	// Each (trade, order, status) is stored once
+	fill := types.FillEvent{TradeID: "t1", OrderID: "o1", ConditionID: testCID, Status: "MATCHED"}
+	d.RecordFill(fill)
+	d.RecordFill(fill)
+	fill.Status = "CONFIRMED"
+	d.RecordFill(fill)
+	if n := count(t, d, `SELECT COUNT(*) FROM fills`); n != 2 {
+		t.Errorf("%d fill rows, want 2 (MATCHED, CONFIRMED)", n)
+	}
+
+	// A nil DB records nothing, without panicking
+	var none *DB
+	none.RecordMarket(m)
+	none.RecordChainTx(TxMerge, testCID, 5, "", TxOK)
+	if err := none.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,200 @@
+// Package trades keeps a local copy of the account's CLOB trade history.
+//
+// The store is an append-only newline-delimited JSON log keyed by trade ID:
+// a trade is appended when first seen and again whenever its status
+// changes (MATCHED → CONFIRMED / FAILED); on load the last line of each ID
+// wins. Sync pulls only trades newer than the last one stored (minus a
+// short overlap for late status updates), so the full history is fetched
+// once and every later sync is a page or two.
+package trades
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clob"
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+)
+
+// syncOverlap is how far (in seconds) before the newest stored trade a sync
+// starts, so status changes of recent trades are picked up.
+const syncOverlap = 3600
+
+// Store is the local trade history.
+type Store struct {
+	mu       sync.Mutex
+	filepath string
+	trades   map[string]clob.Trade // key → latest version
+	newest   int64                 // unix time of the newest trade stored
+	legacy   bool                  // trades stored without trader_side: refetch everything once
+}
+
+// New creates a store backed by the configured file.
+func New() *Store {
+	s := &Store{
+		filepath: config.TradesFile,
+		trades:   make(map[string]clob.Trade),
+	}
+	s.load()
+	return s
+}
+
+// NewMemory creates a store that is never persisted to disk.
+func NewMemory() *Store {
+	return &Store{trades: make(map[string]clob.Trade)}
+}
+
+// Sync fetches the trades the store has not seen (or whose status changed)
+// and appends them. Returns how many were added or updated.
+func (s *Store) Sync(ctx context.Context, client *clob.Client) (int, error) {
+	s.mu.Lock()
+	after := int64(0)
+	if s.newest > syncOverlap && !s.legacy {
+		after = s.newest - syncOverlap
+	}
+	s.mu.Unlock()
+
+	fetched, err := client.GetTrades(ctx, after)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var changed []clob.Trade
+	for _, t := range fetched {
+		k := key(t)
+		if old, ok := s.trades[k]; ok && old.Status == t.Status && old.TraderSide == t.TraderSide {
+			continue
+		}
+		s.put(k, t)
+		changed = append(changed, t)
+	}
+	if err := s.append(changed); err != nil {
+		log.Printf("[trades] save error: %v", err)
+	}
+	s.legacy = false
+	if len(changed) > 0 {
+		log.Printf("[trades] synced: %d new/updated of %d fetched (after %d) | %d stored",
+			len(changed), len(fetched), after, len(s.trades))
+	}
+	return len(changed), nil
+}
+
+// All returns every stored trade (latest version of each), oldest first.
+func (s *Store) All() []clob.Trade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]clob.Trade, 0, len(s.trades))
+	for _, t := range s.trades {
+		out = append(out, t)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ti, tj := timestamp(out[i]), timestamp(out[j])
+		if ti != tj {
+			return ti < tj
+		}
+		return key(out[i]) < key(out[j])
+	})
+	return out
+}
+
+// Len returns the number of distinct trades stored.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.trades)
+}
+
+// put stores t under k. Caller holds s.mu.
+func (s *Store) put(k string, t clob.Trade) {
+	s.trades[k] = t
+	if ts := timestamp(t); ts > s.newest {
+		s.newest = ts
+	}
+}
+
+// ── Persistence ───────────────────────────────────────────────────────────
+
+func (s *Store) load() {
+	f, err := os.Open(s.filepath)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		log.Printf("[trades] load error: %v — starting fresh", err)
+		return
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	bad := 0
+	for sc.Scan() {
+		var t clob.Trade
+		if err := json.Unmarshal(sc.Bytes(), &t); err != nil {
+			bad++ // e.g. a line cut short by a crash
+			continue
+		}
+		s.put(key(t), t)
+		if t.TraderSide == "" {
+			s.legacy = true
+		}
+	}
+	if err := sc.Err(); err != nil {
+		log.Printf("[trades] read error: %v", err)
+	}
+	if bad > 0 {
+		log.Printf("[trades] skipped %d unreadable lines in %s", bad, s.filepath)
+	}
+	log.Printf("[trades] loaded %d trades from %s", len(s.trades), s.filepath)
+	if s.legacy {
+		log.Printf("[trades] trades stored without maker/taker role — the next sync refetches the full history")
+	}
+}
+
+// append writes trades to the end of the log. Caller holds s.mu.
+func (s *Store) append(trades []clob.Trade) error {
+	if s.filepath == "" || len(trades) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(s.filepath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, t := range trades {
+		if err := enc.Encode(t); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ── Helpers ───────────────────────────────────────────────────────────────
+
+// key identifies a trade: its ID, or its contents for trades without one.
+func key(t clob.Trade) string {
+	if t.ID != "" {
+		return t.ID
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%s/%s", t.Market, t.AssetID, t.Side, t.Size, t.Price, t.Timestamp)
+}
+
+func timestamp(t clob.Trade) int64 {
+	ts, _ := strconv.ParseInt(t.Timestamp, 10, 64)
+	return ts
+}
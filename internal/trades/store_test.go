@@ -0,0 +1,187 @@
+package trades
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gipsh/polymarket-bot-go/internal/clob"
+	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+const (
+	pageSize = 2
+	start    = 1760000000
+)
+
+// history serves /data/trades in pages of pageSize, with the CLOB's
+// cursors: the base64 offset of the next page, "LTE=" on the last one.
+type history struct {
+	mu       sync.Mutex
+	trades   []clob.Trade
+	requests []string // query of every request
+}
+
+func (h *history) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests = append(h.requests, r.URL.RawQuery)
+	if len(h.requests) > 20 {
+		http.Error(w, "paging did not stop", http.StatusTooManyRequests)
+		return
+	}
+
+	after, _ := strconv.ParseInt(r.URL.Query().Get("after"), 10, 64)
+	offset := 0
+	if c, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("next_cursor")); err == nil {
+		offset, _ = strconv.Atoi(string(c))
+	}
+	out := []clob.Trade{}
+	for _, t := range h.trades {
+		if timestamp(t) > after {
+			out = append(out, t)
+		}
+	}
+	offset = min(offset, len(out))
+	end := min(offset+pageSize, len(out))
+	next := "LTE="
+	if end < len(out) {
+		next = base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+	json.NewEncoder(w).Encode(map[string]any{"data": out[offset:end], "next_cursor": next})
+}
+
+func (h *history) setStatus(id, status string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := range h.trades {
+		if h.trades[i].ID == id {
+			h.trades[i].Status = status
+		}
+	}
+}
+
+func (h *history) queries() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	q := h.requests
+	h.requests = nil
+	return q
+}
+
+// serve returns a history of n matched trades half an hour apart and a
+// client reading it.
+func serve(t *testing.T, n int) (*history, *clob.Client) {
+	t.Helper()
+	h := &history{}
+	for i := 0; i < n; i++ {
+		h.trades = append(h.trades, clob.Trade{
+			ID:         fmt.Sprintf("trade-%d", i+1),
+			Side:       "BUY",
+			Size:       "10",
+			Price:      "0.45",
+			Status:     "MATCHED",
+			Timestamp:  strconv.Itoa(start + 1800*i),
+			TraderSide: "TAKER",
+		})
+	}
+	hs := httptest.NewServer(h)
+	t.Cleanup(hs.Close)
+	c, err := clob.NewClientWithHost(hs.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetAPICreds(&types.APICreds{APIKey: "key", APISecret: "c2VjcmV0", Passphrase: "pass"})
+	return h, c
+}
+
+func tradesFile(t *testing.T) string {
+	t.Helper()
+	prev := config.TradesFile
+	t.Cleanup(func() { config.TradesFile = prev })
+	config.TradesFile = filepath.Join(t.TempDir(), "trades.ndjson")
+	return config.TradesFile
+}
+
+func TestGetTradesPagesToEnd(t *testing.T) {
+	h, c := serve(t, 5)
+	got, err := c.GetTrades(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 5 || got[4].ID != "trade-5" {
+		t.Errorf("fetched %d trades, want all 5", len(got))
+	}
+	want := []string{"", "next_cursor=Mg%3D%3D", "next_cursor=NA%3D%3D"}
+	if q := h.queries(); fmt.Sprint(q) != fmt.Sprint(want) {
+		t.Errorf("requests %q, want %q (stop at LTE=)", q, want)
+	}
+}
+
+func TestSync(t *testing.T) {
+	path := tradesFile(t)
+	h, c := serve(t, 5)
+	ctx := context.Background()
+
+	s := New()
+	if n, err := s.Sync(ctx, c); err != nil || n != 5 {
+		t.Fatalf("first sync added %d (err %v), want 5", n, err)
+	}
+	h.queries()
+
+	// A later sync only asks for the last hour and stores status changes
+	h.setStatus("trade-5", "CONFIRMED")
+	if n, err := s.Sync(ctx, c); err != nil || n != 1 {
+		t.Fatalf("second sync added %d (err %v), want the 1 status change", n, err)
+	}
+	newest := start + 1800*4
+	if q := h.queries(); len(q) != 1 || q[0] != fmt.Sprintf("after=%d", newest-syncOverlap) {
+		t.Errorf("second sync requests %q, want one after newest - %ds", q, syncOverlap)
+	}
+
+	// A line cut short by a crash is skipped on reload; the last version wins
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"id":"trade-6","sta`)
+	f.Close()
+
+	s = New()
+	all := s.All()
+	if len(all) != 5 || all[4].ID != "trade-5" || all[4].Status != "CONFIRMED" {
+		t.Fatalf("reloaded %d trades (last %+v), want 5 with trade-5 CONFIRMED", len(all), all[len(all)-1])
+	}
+	if n, err := s.Sync(ctx, c); err != nil || n != 0 {
+		t.Errorf("sync after reload added %d (err %v), want 0", n, err)
+	}
+}
+
+func TestLegacyStoreRefetched(t *testing.T) {
+	path := tradesFile(t)
+	h, c := serve(t, 3)
+	// Stored before trader_side was kept
+	old := h.trades[2]
+	old.TraderSide = ""
+	line, _ := json.Marshal(old)
+	if err := os.WriteFile(path, append(line, '\n'), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New()
+	if n, err := s.Sync(context.Background(), c); err != nil || n != 3 {
+		t.Fatalf("sync added %d (err %v), want 3", n, err)
+	}
+	if q := h.queries(); len(q) != 2 || q[0] != "" {
+		t.Errorf("requests %q, want the full history from the start", q)
+	}
+}
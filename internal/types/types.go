@@ -5,6 +5,9 @@ package types
 import (
 	"fmt"
 	"time"
+
+	"github.com/gipsh/polymarket-bot-go/internal/book"
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
 )
 
 // ── Market ────────────────────────────────────────────────────────────────
@@ -20,9 +23,9 @@ type Market struct {
 	Title       string    // human-readable title
 }
 
-// MinutesToClose returns minutes until the market resolves.
+// MinutesToClose returns minutes until the market resolves (default clock).
 func (m *Market) MinutesToClose() float64 {
-	return time.Until(m.EndDate).Minutes()
+	return clock.Until(m.EndDate).Minutes()
 }
 
 // IsOpen returns true if the market has not yet resolved.
@@ -51,6 +54,7 @@ const (
 	StateGrey         MarketState = "GREY"
 	StateMomentumUp   MarketState = "MOMENTUM_UP"
 	StateMomentumDown MarketState = "MOMENTUM_DOWN"
+	StateSplit        MarketState = "SPLIT"
 	StateResolved     MarketState = "RESOLVED"
 )
 
@@ -60,6 +64,15 @@ type Prices struct {
 	Down   float64
 	Spread float64     // Up + Down
 	State  MarketState
+
+	// Best bids (what selling gets), 0 when unknown
+	UpBid   float64
+	DownBid float64
+
+	// Order books behind the prices, when known (WS snapshots or REST /book).
+	// nil means depth is unknown and sizing falls back to fixed amounts.
+	UpBook   *book.Book
+	DownBook *book.Book
 }
 
 // Winner returns "UP" or "DOWN" depending on which price is higher.
@@ -86,13 +99,19 @@ func (p *Prices) LoserPrice() float64 {
 	return p.Down
 }
 
-// ClassifyPrices determines the MarketState from raw up/down prices.
-func ClassifyPrices(up, down, arbThreshold, momentumTrigger float64) MarketState {
+// ClassifyPrices determines the MarketState from raw up/down prices (best
+// asks) and best bids. Bids summing above splitThreshold mean a minted
+// pair sells for more than the $1 it costs (SPLIT); splitThreshold 0 or
+// unknown (zero) bids disable that check.
+func ClassifyPrices(up, down, upBid, downBid, arbThreshold, splitThreshold, momentumTrigger float64) MarketState {
 	winner := max64(up, down)
 	spread := up + down
 	if winner >= 0.99 {
 		return StateResolved
 	}
+	if splitThreshold > 0 && upBid > 0 && downBid > 0 && upBid+downBid > splitThreshold {
+		return StateSplit
+	}
 	if winner > momentumTrigger {
 		if up > down {
 			return StateMomentumUp
@@ -124,6 +143,7 @@ const (
 	BotMomentumUp   BotState = iota
 	BotMomentumDown BotState = iota
 	BotResolution   BotState = iota
+	BotSplit        BotState = iota
 )
 
 func (s BotState) String() string {
@@ -140,6 +160,8 @@ func (s BotState) String() string {
 		return "MOMENTUM_DOWN"
 	case BotResolution:
 		return "RESOLUTION"
+	case BotSplit:
+		return "SPLIT"
 	default:
 		return "UNKNOWN"
 	}
@@ -152,8 +174,11 @@ const (
 	ActionWait         ActionKind = "wait"
 	ActionSkip         ActionKind = "skip"
 	ActionBuyArb       ActionKind = "buy_arb"
+	ActionBuyArbPair   ActionKind = "buy_arb_pair"
+	ActionQuoteArb     ActionKind = "quote_arb"
 	ActionBuyMomentum  ActionKind = "buy_momentum"
 	ActionMerge        ActionKind = "merge"
+	ActionSplitSell    ActionKind = "split_sell"
 )
 
 // Action is the decision the FSM returns for a given market.
@@ -165,6 +190,14 @@ type Action struct {
 	MainUSDC  float64
 	HedgeUSDC float64
 	ArbUSDC   float64
+	ArbPrice  float64 // for buy_arb: highest ask the buy reaches (its FOK limit)
+	UpUSDC    float64 // for buy_arb_pair: cost of the UP leg
+	DownUSDC  float64 // for buy_arb_pair: cost of the DOWN leg
+	Pairs     float64 // for buy_arb: tokens to buy; buy_arb_pair: tokens to buy on each leg; split_sell: pairs to mint
+	UpPrice   float64 // for quote_arb: UP bid price; split_sell: lowest UP sell price; buy_arb_pair: highest UP ask
+	DownPrice float64 // for quote_arb: DOWN bid price; split_sell: lowest DOWN sell price; buy_arb_pair: highest DOWN ask
+	UpSize    float64 // for quote_arb: UP tokens to bid for (0 = no quote)
+	DownSize  float64 // for quote_arb: DOWN tokens to bid for (0 = no quote)
 	Reason    string
 }
 
@@ -178,9 +211,38 @@ func SkipAction(reason string) Action {
 	return Action{Kind: ActionSkip, Reason: reason}
 }
 
-// BuyArbAction creates an arb buy action.
-func BuyArbAction(side string, usdc float64, reason string) Action {
-	return Action{Kind: ActionBuyArb, Side: side, ArbUSDC: usdc, Reason: reason}
+// BuyArbAction creates an arb buy action: tokens of side for usdc, walking
+// the asks up to price.
+func BuyArbAction(side string, tokens, usdc, price float64, reason string) Action {
+	return Action{Kind: ActionBuyArb, Side: side, Pairs: tokens, ArbUSDC: usdc, ArbPrice: price, Reason: reason}
+}
+
+// BuyArbPairAction creates a two-leg arb action buying the same number of
+// UP and DOWN tokens, so the cycle ends in mergeable pairs. Each leg walks
+// the asks up to its price.
+func BuyArbPairAction(pairs, upUSDC, downUSDC, upPrice, downPrice float64, reason string) Action {
+	return Action{
+		Kind:      ActionBuyArbPair,
+		UpUSDC:    upUSDC,
+		DownUSDC:  downUSDC,
+		Pairs:     pairs,
+		UpPrice:   upPrice,
+		DownPrice: downPrice,
+		Reason:    reason,
+	}
+}
+
+// QuoteArbAction creates a maker arb action: rest limit bids on both sides
+// at prices that sum to less than $1 and wait for fills.
+func QuoteArbAction(upPrice, downPrice, upSize, downSize float64, reason string) Action {
+	return Action{
+		Kind:      ActionQuoteArb,
+		UpPrice:   upPrice,
+		DownPrice: downPrice,
+		UpSize:    upSize,
+		DownSize:  downSize,
+		Reason:    reason,
+	}
 }
 
 // BuyMomentumAction creates a momentum buy action.
@@ -200,9 +262,22 @@ func MergeAction(reason string) Action {
 	return Action{Kind: ActionMerge, Reason: reason}
 }
 
+// SplitSellAction creates a reverse arb action: mint pairs UP+DOWN pairs
+// for $1 each with splitPosition, then sell both legs at no less than
+// upPrice/downPrice per token.
+func SplitSellAction(pairs, upPrice, downPrice float64, reason string) Action {
+	return Action{
+		Kind:      ActionSplitSell,
+		Pairs:     pairs,
+		UpPrice:   upPrice,
+		DownPrice: downPrice,
+		Reason:    reason,
+	}
+}
+
 // ── Order types ───────────────────────────────────────────────────────────
 
-// OrderSide is the side of an order (BUY).
+// OrderSide is the side of an order (BUY or SELL).
 type OrderSide int
 
 const (
@@ -210,6 +285,26 @@ const (
 	SideSell OrderSide = 1
 )
 
+func (s OrderSide) String() string {
+	if s == SideSell {
+		return "SELL"
+	}
+	return "BUY"
+}
+
+// OrderStatus is where a market order is in its lifecycle: accepted by the
+// CLOB, matched, its settlement tx mined, then confirmed on-chain — or
+// failed at any point after matching.
+type OrderStatus string
+
+const (
+	OrderSubmitted OrderStatus = "submitted"
+	OrderMatched   OrderStatus = "matched"
+	OrderMined     OrderStatus = "mined"
+	OrderConfirmed OrderStatus = "confirmed"
+	OrderFailed    OrderStatus = "failed"
+)
+
 // SignatureType mirrors Polymarket signature types.
 type SignatureType int
 
@@ -243,6 +338,8 @@ type OrderResult struct {
 	Side           string // "UP" or "DOWN"
 	USDCSpent      float64
 	TokensReceived float64
+	TokensSold     float64 // SELL only
+	USDCReceived   float64 // SELL only
 	OrderID        string
 	Error          string
 }
@@ -259,11 +356,31 @@ type APICreds struct {
 // ── Fill event ────────────────────────────────────────────────────────────
 
 // FillEvent is emitted by the user WebSocket when an order is matched.
+// The same trade is re-sent as its status advances (MATCHED → MINED →
+// CONFIRMED), so consumers dedupe on TradeID + OrderID.
 type FillEvent struct {
-	OrderID string
-	Side    string
-	Size    float64
-	Price   float64
-	Outcome string
-	TxHash  string
+	TradeID     string
+	OrderID     string
+	ConditionID string
+	AssetID     string
+	Side        string
+	Size        float64
+	Price       float64
+	Outcome     string
+	Status      string
+	TxHash      string
+}
+
+// OrderEvent is emitted by the user WebSocket when one of our orders is
+// placed, updated (partially matched) or cancelled.
+type OrderEvent struct {
+	OrderID      string
+	ConditionID  string
+	AssetID      string
+	Side         string
+	Outcome      string
+	Type         string // PLACEMENT, UPDATE or CANCELLATION
+	Price        float64
+	OriginalSize float64
+	SizeMatched  float64
 }
@@ -3,6 +3,7 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"strconv"
@@ -11,7 +12,11 @@ import (
 
 	"github.com/gorilla/websocket"
 
+	"github.com/gipsh/polymarket-bot-go/internal/book"
+	"github.com/gipsh/polymarket-bot-go/internal/clock"
 	"github.com/gipsh/polymarket-bot-go/internal/config"
+	"github.com/gipsh/polymarket-bot-go/internal/metrics"
+	"github.com/gipsh/polymarket-bot-go/internal/recorder"
 	"github.com/gipsh/polymarket-bot-go/internal/types"
 )
 
@@ -27,24 +32,30 @@ type priceEntry struct {
 	ts    time.Time
 }
 
-// Pricer maintains a live WebSocket connection to the Polymarket market feed
-// and caches best-ask prices per token ID.
+// Pricer maintains a live WebSocket connection to the Polymarket market feed,
+// keeps a full L2 order book per token ID and caches best-ask prices.
 type Pricer struct {
 	mu            sync.RWMutex
 	cache         map[string]priceEntry
+	books         map[string]*book.Book
 	subscribed    map[string]bool
 	pendingSubs   []string
 	conn          *websocket.Conn
 	running       bool
 	stopCh        chan struct{}
+	stopOnce      sync.Once
+	recorder      *recorder.Recorder
+	lastMsg       time.Time // last message received (or creation time)
 }
 
 // NewWSPricer creates a new WebSocket-based price feed.
 func NewWSPricer() *Pricer {
 	return &Pricer{
 		cache:      make(map[string]priceEntry),
+		books:      make(map[string]*book.Book),
 		subscribed: make(map[string]bool),
 		stopCh:     make(chan struct{}),
+		lastMsg:    clock.Now(),
 	}
 }
 
@@ -74,23 +85,38 @@ func (p *Pricer) Subscribe(tokenIDs []string) {
 	}
 }
 
-// Start launches the background connection loop.
-func (p *Pricer) Start() {
+// SetRecorder enables persisting every raw market event to rec.
+func (p *Pricer) SetRecorder(rec *recorder.Recorder) {
+	p.recorder = rec
+}
+
+// Start launches the background connection loop. It runs until ctx is done
+// or Stop is called.
+func (p *Pricer) Start(ctx context.Context) {
 	p.running = true
-	go p.connectForever()
+	go p.connectForever(ctx)
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.Stop()
+		case <-p.stopCh:
+		}
+	}()
 	log.Println("[ws/pricer] started")
 }
 
-// Stop gracefully shuts down the WebSocket.
+// Stop gracefully shuts down the WebSocket. Safe to call more than once.
 func (p *Pricer) Stop() {
-	p.running = false
-	close(p.stopCh)
-	p.mu.Lock()
-	if p.conn != nil {
-		_ = p.conn.Close()
-	}
-	p.mu.Unlock()
-	log.Println("[ws/pricer] stopped")
+	p.stopOnce.Do(func() {
+		p.running = false
+		close(p.stopCh)
+		p.mu.Lock()
+		if p.conn != nil {
+			_ = p.conn.Close()
+		}
+		p.mu.Unlock()
+		log.Println("[ws/pricer] stopped")
+	})
 }
 
 // GetPrices returns cached prices for the given token pair.
@@ -101,32 +127,134 @@ func (p *Pricer) GetPrices(upTokenID, downTokenID string) *types.Prices {
 	down := p.getPrice(downTokenID)
 	p.mu.RUnlock()
 
-	state := types.ClassifyPrices(up, down, config.ARBThreshold, config.MomentumTrigger)
+	upBook, downBook := p.Book(upTokenID), p.Book(downTokenID)
+	var upBid, downBid float64
+	if upBook != nil {
+		upBid, _ = upBook.BestBid()
+	}
+	if downBook != nil {
+		downBid, _ = downBook.BestBid()
+	}
+
+	state := types.ClassifyPrices(up, down, upBid, downBid, config.ARBThreshold, config.SplitThreshold, config.MomentumTrigger)
 	return &types.Prices{
-		Up:     up,
-		Down:   down,
-		Spread: up + down,
-		State:  state,
+		Up:       up,
+		Down:     down,
+		Spread:   up + down,
+		State:    state,
+		UpBid:    upBid,
+		DownBid:  downBid,
+		UpBook:   upBook,
+		DownBook: downBook,
 	}
 }
 
+// LastMessageAge returns the time since the feed last delivered a message.
+func (p *Pricer) LastMessageAge() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return clock.Since(p.lastMsg)
+}
+
 // IsFresh returns true if the token has a recent price (within maxAge).
 func (p *Pricer) IsFresh(tokenID string, maxAge time.Duration) bool {
 	p.mu.RLock()
 	e, ok := p.cache[tokenID]
 	p.mu.RUnlock()
-	return ok && time.Since(e.ts) < maxAge
+	return ok && clock.Since(e.ts) < maxAge
 }
 
 // UpdateCache allows external seeding (e.g. REST fallback data).
 func (p *Pricer) UpdateCache(tokenID string, price float64) {
 	if price > 0 && price < 1 {
 		p.mu.Lock()
-		p.cache[tokenID] = priceEntry{price: price, ts: time.Now()}
+		p.cache[tokenID] = priceEntry{price: price, ts: clock.Now()}
 		p.mu.Unlock()
 	}
 }
 
+// ── Order book ────────────────────────────────────────────────────────────
+
+// Book returns a copy of the token's order book, or nil if no book snapshot
+// has been received yet.
+func (p *Pricer) Book(tokenID string) *book.Book {
+	p.mu.RLock()
+	b := p.books[tokenID]
+	p.mu.RUnlock()
+	if b == nil {
+		return nil
+	}
+	return b.Clone()
+}
+
+// BestBidAsk returns the top of the token's book.
+func (p *Pricer) BestBidAsk(tokenID string) (bid, ask float64, ok bool) {
+	p.mu.RLock()
+	b := p.books[tokenID]
+	p.mu.RUnlock()
+	if b == nil {
+		return 0, 0, false
+	}
+	bid, okBid := b.BestBid()
+	ask, okAsk := b.BestAsk()
+	return bid, ask, okBid && okAsk
+}
+
+// DepthUpTo returns how many tokens can be bought at or below maxPrice and
+// what they cost.
+func (p *Pricer) DepthUpTo(tokenID string, maxPrice float64) (tokens, usdc float64) {
+	p.mu.RLock()
+	b := p.books[tokenID]
+	p.mu.RUnlock()
+	if b == nil {
+		return 0, 0
+	}
+	return b.AskDepth(maxPrice)
+}
+
+// VWAP returns the average price and tokens received for a market BUY of
+// usdc. full=false if the book can't absorb the whole amount.
+func (p *Pricer) VWAP(tokenID string, usdc float64) (vwap, tokens float64, full bool) {
+	p.mu.RLock()
+	b := p.books[tokenID]
+	p.mu.RUnlock()
+	if b == nil {
+		return 0, 0, false
+	}
+	return b.BuyVWAP(usdc)
+}
+
+// bookFor returns the token's book, creating it if needed.
+func (p *Pricer) bookFor(tokenID string) *book.Book {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	b := p.books[tokenID]
+	if b == nil {
+		b = book.New()
+		p.books[tokenID] = b
+	}
+	return b
+}
+
+// syncBestAsk copies the book's best ask into the price cache. A book with
+// no asks left drops the cached price: the token is not fresh until an ask
+// returns, so callers fall back to REST instead of a stale ask.
+func (p *Pricer) syncBestAsk(tokenID string, b *book.Book) {
+	if ask, ok := b.BestAsk(); ok {
+		p.UpdateCache(tokenID, ask)
+		return
+	}
+	p.mu.Lock()
+	delete(p.cache, tokenID)
+	p.mu.Unlock()
+}
+
+// Inject processes a raw market-channel message as if it had arrived on the
+// WebSocket. Used by replay mode to feed recorded events without a connection.
+func (p *Pricer) Inject(raw []byte) {
+	p.handleMessage(raw)
+}
+
 // ── Internal helpers ──────────────────────────────────────────────────────
 
 func (p *Pricer) getPrice(tokenID string) float64 {
@@ -136,17 +264,22 @@ func (p *Pricer) getPrice(tokenID string) float64 {
 	return 0.5
 }
 
-func (p *Pricer) connectForever() {
+func (p *Pricer) connectForever(ctx context.Context) {
 	for p.running {
-		if err := p.listen(); err != nil && p.running {
+		if err := p.listen(ctx); err != nil && p.running {
 			log.Printf("[ws/pricer] disconnected: %v — reconnecting in %s", err, reconnectDelay)
-			time.Sleep(reconnectDelay)
+			metrics.Reconnect(metrics.FeedMarket)
+			select {
+			case <-p.stopCh:
+				return
+			case <-time.After(reconnectDelay):
+			}
 		}
 	}
 }
 
-func (p *Pricer) listen() error {
-	conn, _, err := websocket.DefaultDialer.Dial(marketWSURL, nil)
+func (p *Pricer) listen(ctx context.Context) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, marketWSURL, nil)
 	if err != nil {
 		return err
 	}
@@ -219,6 +352,10 @@ func (p *Pricer) sendSubscribe(conn *websocket.Conn, tokenIDs []string) error {
 // ── Message handling ──────────────────────────────────────────────────────
 
 func (p *Pricer) handleMessage(raw []byte) {
+	p.mu.Lock()
+	p.lastMsg = clock.Now()
+	p.mu.Unlock()
+
 	var events []json.RawMessage
 
 	// Could be a list or a single object
@@ -238,6 +375,13 @@ func (p *Pricer) handleMessage(raw []byte) {
 			etype = base.Type
 		}
 
+		switch etype {
+		case "book", "price_change", "best_bid_ask", "last_trade_price":
+			if p.recorder != nil {
+				p.recorder.RecordWS(etype, ev)
+			}
+		}
+
 		switch etype {
 		case "book":
 			p.handleBook(ev)
@@ -251,82 +395,146 @@ func (p *Pricer) handleMessage(raw []byte) {
 	}
 }
 
+// bookLevel is a price level as sent on the market channel.
+type bookLevel struct {
+	Price flexFloat `json:"price"`
+	Size  flexFloat `json:"size"`
+}
+
+func toLevels(in []bookLevel) []book.Level {
+	out := make([]book.Level, 0, len(in))
+	for _, l := range in {
+		out = append(out, book.Level{Price: float64(l.Price), Size: float64(l.Size)})
+	}
+	return out
+}
+
+// handleBook replaces the token's book with a full snapshot.
 func (p *Pricer) handleBook(raw json.RawMessage) {
 	var ev struct {
-		AssetID string `json:"asset_id"`
-		Asks    []struct {
-			Price string `json:"price"`
-		} `json:"asks"`
+		AssetID string      `json:"asset_id"`
+		Bids    []bookLevel `json:"bids"`
+		Asks    []bookLevel `json:"asks"`
 	}
-	if json.Unmarshal(raw, &ev) != nil || ev.AssetID == "" || len(ev.Asks) == 0 {
+	if json.Unmarshal(raw, &ev) != nil || ev.AssetID == "" {
 		return
 	}
-	best := 1.0
-	for _, a := range ev.Asks {
-		if f, err := strconv.ParseFloat(a.Price, 64); err == nil && f > 0 {
-			if f < best {
-				best = f
-			}
-		}
-	}
-	p.UpdateCache(ev.AssetID, best)
+	b := p.bookFor(ev.AssetID)
+	b.Snapshot(toLevels(ev.Bids), toLevels(ev.Asks), clock.Now())
+	p.syncBestAsk(ev.AssetID, b)
 }
 
+// handlePriceChange applies incremental level updates. Two layouts exist:
+//
+//	{"market":"0x…","price_changes":[{"asset_id":"…","price":"0.5","size":"10","side":"BUY"}]}
+//	{"asset_id":"…","changes":[{"price":"0.5","size":"10","side":"SELL"}]}
+//
+// side BUY updates a bid level, SELL an ask level; size 0 removes the level.
 func (p *Pricer) handlePriceChange(raw json.RawMessage) {
+	type change struct {
+		AssetID string    `json:"asset_id"`
+		Price   flexFloat `json:"price"`
+		Size    flexFloat `json:"size"`
+		Side    string    `json:"side"`
+	}
 	var ev struct {
-		AssetID string  `json:"asset_id"`
-		Price   float64 `json:"price"`
-		Side    string  `json:"side"`
+		AssetID      string    `json:"asset_id"`
+		Price        flexFloat `json:"price"`
+		Side         string    `json:"side"`
+		Changes      []change  `json:"changes"`
+		PriceChanges []change  `json:"price_changes"`
 	}
-	if json.Unmarshal(raw, &ev) != nil || ev.AssetID == "" {
+	if json.Unmarshal(raw, &ev) != nil {
 		return
 	}
-	if ev.Side == "" || ev.Side == "ASK" || ev.Side == "SELL" {
-		current := 0.5
-		p.mu.RLock()
-		if e, ok := p.cache[ev.AssetID]; ok {
-			current = e.price
+
+	// Legacy flat layout without sizes: treat as a best-ask update.
+	if len(ev.Changes) == 0 && len(ev.PriceChanges) == 0 {
+		if ev.AssetID != "" && (ev.Side == "" || ev.Side == "ASK" || ev.Side == "SELL") {
+			p.UpdateCache(ev.AssetID, float64(ev.Price))
+		}
+		return
+	}
+
+	changes := ev.PriceChanges
+	for _, c := range ev.Changes {
+		c.AssetID = ev.AssetID
+		changes = append(changes, c)
+	}
+
+	now := clock.Now()
+	touched := map[string]*book.Book{}
+	for _, c := range changes {
+		if c.AssetID == "" {
+			continue
 		}
-		p.mu.RUnlock()
-		if ev.Price > 0 && abs64(ev.Price-current) < 0.15 {
-			p.UpdateCache(ev.AssetID, ev.Price)
+		side := book.Ask
+		if c.Side == "BUY" || c.Side == "BID" {
+			side = book.Bid
 		}
+		b := p.bookFor(c.AssetID)
+		b.Set(side, float64(c.Price), float64(c.Size), now)
+		touched[c.AssetID] = b
+	}
+	for id, b := range touched {
+		p.syncBestAsk(id, b)
 	}
 }
 
 func (p *Pricer) handleBestBidAsk(raw json.RawMessage) {
 	var ev struct {
-		AssetID string  `json:"asset_id"`
-		BestAsk float64 `json:"best_ask"`
-		Ask     float64 `json:"ask"`
+		AssetID string    `json:"asset_id"`
+		BestAsk flexFloat `json:"best_ask"`
+		Ask     flexFloat `json:"ask"`
 	}
 	if json.Unmarshal(raw, &ev) != nil || ev.AssetID == "" {
 		return
 	}
-	ask := ev.BestAsk
+	ask := float64(ev.BestAsk)
 	if ask == 0 {
-		ask = ev.Ask
+		ask = float64(ev.Ask)
 	}
 	p.UpdateCache(ev.AssetID, ask)
 }
 
 func (p *Pricer) handleLastTrade(raw json.RawMessage) {
 	var ev struct {
-		AssetID string  `json:"asset_id"`
-		Price   float64 `json:"price"`
+		AssetID string    `json:"asset_id"`
+		Price   flexFloat `json:"price"`
 	}
 	if json.Unmarshal(raw, &ev) != nil || ev.AssetID == "" {
 		return
 	}
 	// Only use last trade if we have no fresh data
 	if !p.IsFresh(ev.AssetID, 2*time.Second) {
-		p.UpdateCache(ev.AssetID, ev.Price)
+		p.UpdateCache(ev.AssetID, float64(ev.Price))
 	}
 }
 
-func abs64(x float64) float64 {
-	if x < 0 {
-		return -x
+// flexFloat accepts a JSON number or a numeric string ("0.52").
+type flexFloat float64
+
+func (f *flexFloat) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			*f = 0
+			return nil
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		*f = flexFloat(v)
+		return nil
+	}
+	var v float64
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
 	}
-	return x
+	*f = flexFloat(v)
+	return nil
 }
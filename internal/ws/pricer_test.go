@@ -0,0 +1,30 @@
+package ws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBookWithoutAsksNotFresh(t *testing.T) {
+	p := NewWSPricer()
+	p.Inject([]byte(`{"event_type":"book","asset_id":"up",
+		"bids":[{"price":"0.40","size":"10"}],"asks":[{"price":"0.45","size":"10"}]}`))
+	if !p.IsFresh("up", time.Second) {
+		t.Fatal("ask not cached from the book snapshot")
+	}
+
+	// The asks side is emptied by the next snapshot
+	p.Inject([]byte(`{"event_type":"book","asset_id":"up",
+		"bids":[{"price":"0.40","size":"10"}],"asks":[]}`))
+	if p.IsFresh("up", time.Second) {
+		t.Errorf("stale ask still fresh after an empty asks snapshot (price %.2f)",
+			p.GetPrices("up", "down").Up)
+	}
+
+	// Same when a price_change removes the last ask level
+	p.Inject([]byte(`{"event_type":"book","asset_id":"up","bids":[],"asks":[{"price":"0.45","size":"10"}]}`))
+	p.Inject([]byte(`{"event_type":"price_change","price_changes":[{"asset_id":"up","price":"0.45","size":"0","side":"SELL"}]}`))
+	if p.IsFresh("up", time.Second) {
+		t.Error("stale ask still fresh after its last level was removed")
+	}
+}
@@ -3,6 +3,7 @@
 package ws
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
@@ -10,10 +11,12 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 
+	"github.com/gipsh/polymarket-bot-go/internal/metrics"
 	"github.com/gipsh/polymarket-bot-go/internal/types"
 )
 
@@ -22,15 +25,20 @@ const userWSURL = "wss://ws-subscriptions-clob.polymarket.com/ws/user"
 // OnFillFunc is called when a fill event arrives.
 type OnFillFunc func(types.FillEvent)
 
+// OnOrderFunc is called when an order event arrives.
+type OnOrderFunc func(types.OrderEvent)
+
 // UserClient maintains an authenticated connection to the user channel.
 type UserClient struct {
 	apiKey     string
 	apiSecret  string
 	passphrase string
 	onFill     OnFillFunc
+	onOrder    OnOrderFunc
 	conn       *websocket.Conn
 	running    bool
 	stopCh     chan struct{}
+	stopOnce   sync.Once
 }
 
 // NewUserClient creates an authenticated user WebSocket client.
@@ -44,21 +52,37 @@ func NewUserClient(creds *types.APICreds, onFill OnFillFunc) *UserClient {
 	}
 }
 
-// Start launches the background connection loop.
-func (u *UserClient) Start() {
+// SetOnOrder registers a callback for order events (placement, partial
+// match, cancellation). Call before Start.
+func (u *UserClient) SetOnOrder(fn OnOrderFunc) {
+	u.onOrder = fn
+}
+
+// Start launches the background connection loop. It runs until ctx is done
+// or Stop is called.
+func (u *UserClient) Start(ctx context.Context) {
 	u.running = true
-	go u.connectForever()
+	go u.connectForever(ctx)
+	go func() {
+		select {
+		case <-ctx.Done():
+			u.Stop()
+		case <-u.stopCh:
+		}
+	}()
 	log.Println("[ws/user] started")
 }
 
-// Stop gracefully shuts down.
+// Stop gracefully shuts down. Safe to call more than once.
 func (u *UserClient) Stop() {
-	u.running = false
-	close(u.stopCh)
-	if u.conn != nil {
-		_ = u.conn.Close()
-	}
-	log.Println("[ws/user] stopped")
+	u.stopOnce.Do(func() {
+		u.running = false
+		close(u.stopCh)
+		if u.conn != nil {
+			_ = u.conn.Close()
+		}
+		log.Println("[ws/user] stopped")
+	})
 }
 
 // Subscribe subscribes to fill events for a given condition ID.
@@ -76,16 +100,21 @@ func (u *UserClient) Subscribe(conditionID string) {
 
 // ── Internal ──────────────────────────────────────────────────────────────
 
-func (u *UserClient) connectForever() {
+func (u *UserClient) connectForever(ctx context.Context) {
 	for u.running {
-		if err := u.listen(); err != nil && u.running {
+		if err := u.listen(ctx); err != nil && u.running {
 			log.Printf("[ws/user] disconnected: %v — reconnecting in %s", err, reconnectDelay)
-			time.Sleep(reconnectDelay)
+			metrics.Reconnect(metrics.FeedUser)
+			select {
+			case <-u.stopCh:
+				return
+			case <-time.After(reconnectDelay):
+			}
 		}
 	}
 }
 
-func (u *UserClient) listen() error {
+func (u *UserClient) listen(ctx context.Context) error {
 	// Build auth headers for WS connection
 	ts := strconv.FormatInt(time.Now().Unix(), 10)
 	sig := u.hmacSign(ts, "GET", "/ws/user", "")
@@ -97,7 +126,7 @@ func (u *UserClient) listen() error {
 		"POLY_PASSPHRASE": {u.passphrase},
 	}
 
-	conn, _, err := websocket.DefaultDialer.Dial(userWSURL, headers)
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, userWSURL, headers)
 	if err != nil {
 		return fmt.Errorf("dial: %w", err)
 	}
@@ -152,34 +181,114 @@ func (u *UserClient) handleMessage(raw []byte) {
 			etype = base.Type
 		}
 
-		if etype == "trade" || etype == "fill" || etype == "TRADE" {
+		switch etype {
+		case "trade", "fill", "TRADE":
 			u.handleFill(ev)
+		case "order", "ORDER":
+			u.handleOrder(ev)
 		}
 	}
 }
 
 func (u *UserClient) handleFill(raw json.RawMessage) {
 	var ev struct {
-		OrderID  string  `json:"order_id"`
-		Side     string  `json:"side"`
-		Size     float64 `json:"size"`
-		Price    float64 `json:"price"`
-		Outcome  string  `json:"outcome"`
-		TxHash   string  `json:"transaction_hash"`
-	}
-	if err := json.Unmarshal(raw, &ev); err != nil {
+		ID          string    `json:"id"`
+		Market      string    `json:"market"`
+		Owner       string    `json:"owner"`
+		OrderID     string    `json:"order_id"`
+		TakerOrder  string    `json:"taker_order_id"`
+		AssetID     string    `json:"asset_id"`
+		Side        string    `json:"side"`
+		Size        flexFloat `json:"size"`
+		Price       flexFloat `json:"price"`
+		Outcome     string    `json:"outcome"`
+		Status      string    `json:"status"`
+		TxHash      string    `json:"transaction_hash"`
+		MakerOrders []struct {
+			OrderID       string    `json:"order_id"`
+			Owner         string    `json:"owner"`
+			AssetID       string    `json:"asset_id"`
+			MatchedAmount flexFloat `json:"matched_amount"`
+			Price         flexFloat `json:"price"`
+			Outcome       string    `json:"outcome"`
+		} `json:"maker_orders"`
+	}
+	if err := json.Unmarshal(raw, &ev); err != nil || u.onFill == nil {
 		return
 	}
-	if u.onFill != nil {
+
+	// Taker side of the trade (our market orders). A trade we were only a
+	// maker in carries the taker's owner, or none: only ours is a fill.
+	takerID := ev.TakerOrder
+	if takerID == "" {
+		takerID = ev.OrderID
+	}
+	if takerID != "" && ev.Owner == u.apiKey {
+		metrics.Fill("taker")
 		u.onFill(types.FillEvent{
-			OrderID: ev.OrderID,
-			Side:    ev.Side,
-			Size:    ev.Size,
-			Price:   ev.Price,
-			Outcome: ev.Outcome,
-			TxHash:  ev.TxHash,
+			TradeID:     ev.ID,
+			OrderID:     takerID,
+			ConditionID: ev.Market,
+			AssetID:     ev.AssetID,
+			Side:        ev.Side,
+			Size:        float64(ev.Size),
+			Price:       float64(ev.Price),
+			Outcome:     ev.Outcome,
+			Status:      ev.Status,
+			TxHash:      ev.TxHash,
 		})
 	}
+
+	// Maker side (our resting limit orders that were hit)
+	for _, mo := range ev.MakerOrders {
+		if mo.Owner != u.apiKey {
+			continue
+		}
+		metrics.Fill("maker")
+		u.onFill(types.FillEvent{
+			TradeID:     ev.ID,
+			OrderID:     mo.OrderID,
+			ConditionID: ev.Market,
+			AssetID:     mo.AssetID,
+			Size:        float64(mo.MatchedAmount),
+			Price:       float64(mo.Price),
+			Outcome:     mo.Outcome,
+			Status:      ev.Status,
+			TxHash:      ev.TxHash,
+		})
+	}
+}
+
+func (u *UserClient) handleOrder(raw json.RawMessage) {
+	var ev struct {
+		ID           string    `json:"id"`
+		Owner        string    `json:"owner"`
+		Market       string    `json:"market"`
+		AssetID      string    `json:"asset_id"`
+		Side         string    `json:"side"`
+		Outcome      string    `json:"outcome"`
+		Type         string    `json:"type"`
+		Price        flexFloat `json:"price"`
+		OriginalSize flexFloat `json:"original_size"`
+		SizeMatched  flexFloat `json:"size_matched"`
+	}
+	if err := json.Unmarshal(raw, &ev); err != nil || u.onOrder == nil || ev.ID == "" {
+		return
+	}
+	if ev.Owner != "" && ev.Owner != u.apiKey {
+		return
+	}
+	u.onOrder(types.OrderEvent{
+		OrderID:      ev.ID,
+		ConditionID:  ev.Market,
+		AssetID:      ev.AssetID,
+		Side:         ev.Side,
+		Outcome:      ev.Outcome,
+		Type:         ev.Type,
+		Price:        float64(ev.Price),
+		OriginalSize: float64(ev.OriginalSize),
+		SizeMatched:  float64(ev.SizeMatched),
+	})
 }
 
 func (u *UserClient) hmacSign(ts, method, path, body string) string {
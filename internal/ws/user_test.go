@@ -0,0 +1,51 @@
+package ws
+
+import (
+	"testing"
+
+	"github.com/gipsh/polymarket-bot-go/internal/types"
+)
+
+func TestFillsOnlyOurs(t *testing.T) {
+	tests := []struct {
+		name  string
+		trade string
+		want  []string // order IDs of the fills emitted
+	}{
+		{
+			name:  "our taker order",
+			trade: `{"event_type":"trade","id":"t1","owner":"our-key","taker_order_id":"0xtaker","size":"10","price":"0.45"}`,
+			want:  []string{"0xtaker"},
+		},
+		{
+			name:  "taker without an owner is not ours",
+			trade: `{"event_type":"trade","id":"t2","owner":"","taker_order_id":"0xtaker","size":"10","price":"0.45"}`,
+		},
+		{
+			name: "only our maker order in someone else's trade",
+			trade: `{"event_type":"trade","id":"t3","owner":"their-key","taker_order_id":"0xtaker","size":"10","price":"0.45",
+				"maker_orders":[
+					{"order_id":"0xtheirs","owner":"their-key","matched_amount":"6","price":"0.45"},
+					{"order_id":"0xnobody","matched_amount":"1","price":"0.45"},
+					{"order_id":"0xours","owner":"our-key","matched_amount":"3","price":"0.44"}]}`,
+			want: []string{"0xours"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			u := NewUserClient(&types.APICreds{APIKey: "our-key"}, func(f types.FillEvent) {
+				got = append(got, f.OrderID)
+			})
+			u.handleMessage([]byte(tt.trade))
+			if len(got) != len(tt.want) {
+				t.Fatalf("fills = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("fills = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}